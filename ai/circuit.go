@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The circuit opens after circuitThreshold consecutive failures that point at
+// the provider (network, server, rate limit, timeout). While open, requests
+// fail immediately; after circuitCooldown one request is let through to test
+// the provider again.
+const (
+	circuitThreshold = 5
+	circuitCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned while the provider is considered unavailable.
+var ErrCircuitOpen = errors.New("AI provider unavailable, circuit open")
+
+type circuit struct {
+	mu       sync.Mutex
+	failures int       // consecutive provider failures
+	openedAt time.Time // zero while closed
+	probing  bool      // a trial request is in flight
+}
+
+// allow reports whether a request may be sent now.
+func (c *circuit) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.openedAt.IsZero() {
+		return true
+	}
+	if c.probing || time.Since(c.openedAt) < circuitCooldown {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// record updates the circuit with the outcome of a request made with ctx. A
+// request the caller cancelled or timed out says nothing about the provider:
+// it only frees the trial slot, and a half-open circuit stays half-open.
+func (c *circuit) record(ctx context.Context, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.probing = false
+	if err != nil && ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return
+	}
+	if err == nil || !isProviderFailure(err) {
+		if !c.openedAt.IsZero() {
+			logrus.Info("✅ AI provider available again, circuit closed")
+		}
+		c.failures = 0
+		c.openedAt = time.Time{}
+		return
+	}
+
+	c.failures++
+	if c.failures >= circuitThreshold {
+		if c.openedAt.IsZero() {
+			logrus.WithField("failures", c.failures).Warn("⚠️ AI provider failing, circuit opened")
+		}
+		c.openedAt = time.Now()
+	}
+}
+
+// state returns "closed" or "open".
+func (c *circuit) state() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.openedAt.IsZero() {
+		return "closed"
+	}
+	return "open"
+}
+
+func isProviderFailure(err error) bool {
+	switch classifyError(err) {
+	case errClassNetwork, errClassServer, errClassRateLimited, errClassTimeout:
+		return true
+	}
+	return false
+}
+
+// CircuitState reports whether requests reach the provider: "closed" while
+// they do, "open" after repeated failures.
+func (p *Provider) CircuitState() string {
+	return p.circuit.state()
+}
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"factory_bot/metrics"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var (
+	requestDuration = metrics.NewHistogram("factory_bot_ai_request_duration_seconds",
+		"Duration of AI chat completion requests.", metrics.LongDurationBuckets, "model")
+	tokensUsed = metrics.NewCounter("factory_bot_ai_tokens_total",
+		"Tokens used by AI requests, by type (prompt or completion).", "model", "type")
+	requestErrors = metrics.NewCounter("factory_bot_ai_errors_total",
+		"Failed AI requests by error class.", "model", "class")
+)
+
+// Error classes reported in factory_bot_ai_errors_total.
+const (
+	errClassCanceled    = "canceled"
+	errClassTimeout     = "timeout"
+	errClassRateLimited = "rate_limited"
+	errClassAuth        = "auth"
+	errClassClient      = "client"
+	errClassServer      = "server"
+	errClassNetwork     = "network"
+	errClassEmpty       = "empty"
+	errClassCircuitOpen = "circuit_open" // not sent, the provider is failing
+)
+
+// classifyError maps a failed chat completion to an error class.
+func classifyError(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return errClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return errClassTimeout
+	}
+
+	status := 0
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	default:
+		return errClassNetwork
+	}
+
+	switch {
+	case status == http.StatusTooManyRequests:
+		return errClassRateLimited
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusPaymentRequired:
+		return errClassAuth
+	case status >= 500:
+		return errClassServer
+	}
+	return errClassClient
+}
+
+// observe records the outcome of a chat completion started at start.
+func observe(model string, start time.Time, resp openai.ChatCompletionResponse, err error) {
+	requestDuration.Observe(time.Since(start).Seconds(), model)
+	switch {
+	case err != nil:
+		requestErrors.Inc(model, classifyError(err))
+	case len(resp.Choices) == 0:
+		requestErrors.Inc(model, errClassEmpty)
+	default:
+		tokensUsed.Add(float64(resp.Usage.PromptTokens), model, "prompt")
+		tokensUsed.Add(float64(resp.Usage.CompletionTokens), model, "completion")
+	}
+}
@@ -3,13 +3,17 @@ package ai
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"factory_bot/logging"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sirupsen/logrus"
 )
 
 type Provider struct {
-	client *openai.Client
+	client  *openai.Client
+	circuit circuit
 }
 
 func NewProvider(openRouterKey string) *Provider {
@@ -23,34 +27,50 @@ func NewProvider(openRouterKey string) *Provider {
 	}
 }
 
-func (p *Provider) Generate(ctx context.Context, messages []openai.ChatCompletionMessage, model string, maxTokens int) (string, error) {
-	logrus.WithFields(logrus.Fields{
-		"model":      model,
-		"max_tokens": maxTokens,
-		"msg_count":  len(messages),
+// Generate returns the model's answer. A temperature of 0 leaves the model default.
+func (p *Provider) Generate(ctx context.Context, messages []openai.ChatCompletionMessage, model string, maxTokens int, temperature float32) (string, error) {
+	logging.From(ctx).WithFields(logrus.Fields{
+		"model":       model,
+		"max_tokens":  maxTokens,
+		"temperature": temperature,
+		"msg_count":   len(messages),
 	}).Info("Sending request to AI model")
 
+	ctx, span := startSpan(ctx, model, maxTokens, len(messages))
+	defer span.End()
+
+	if !p.circuit.allow() {
+		requestErrors.Inc(model, errClassCircuitOpen)
+		span.RecordError(ErrCircuitOpen)
+		return "", ErrCircuitOpen
+	}
+
+	start := time.Now()
 	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:     model,
-		Messages:  messages,
-		MaxTokens: maxTokens,
-		Stream:    false,
+		Model:       model,
+		Messages:    messages,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+		Stream:      false,
 	})
+	observe(model, start, resp, err)
+	traceResult(span, resp, err)
+	p.circuit.record(ctx, err)
 
 	if err != nil {
-		logrus.WithError(err).WithField("model", model).Error("❌ OpenRouter API request failed")
+		logging.From(ctx).WithError(err).WithField("model", model).Error("❌ OpenRouter API request failed")
 		return "", fmt.Errorf("openrouter API error: %w", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		logrus.WithField("model", model).Error("❌ No response choices returned")
+		logging.From(ctx).WithField("model", model).Error("❌ No response choices returned")
 		return "", fmt.Errorf("no response choices returned")
 	}
 
 	content := resp.Choices[0].Message.Content
 
 	// Success logging
-	logrus.WithFields(logrus.Fields{
+	logging.From(ctx).WithFields(logrus.Fields{
 		"model":            model,
 		"content_length":   len(content),
 		"prompt_tokens":    resp.Usage.PromptTokens,
@@ -62,33 +82,48 @@ func (p *Provider) Generate(ctx context.Context, messages []openai.ChatCompletio
 	return content, nil
 }
 
-func (p *Provider) GenerateWithVision(ctx context.Context, messages []openai.ChatCompletionMessage, model string, maxTokens int) (string, error) {
-	logrus.WithFields(logrus.Fields{
-		"model":      model,
-		"max_tokens": maxTokens,
-		"msg_count":  len(messages),
+func (p *Provider) GenerateWithVision(ctx context.Context, messages []openai.ChatCompletionMessage, model string, maxTokens int, temperature float32) (string, error) {
+	logging.From(ctx).WithFields(logrus.Fields{
+		"model":       model,
+		"max_tokens":  maxTokens,
+		"temperature": temperature,
+		"msg_count":   len(messages),
 	}).Info("Sending vision request to AI model")
 
+	ctx, span := startSpan(ctx, model, maxTokens, len(messages))
+	defer span.End()
+
+	if !p.circuit.allow() {
+		requestErrors.Inc(model, errClassCircuitOpen)
+		span.RecordError(ErrCircuitOpen)
+		return "", ErrCircuitOpen
+	}
+
+	start := time.Now()
 	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:     model,
-		Messages:  messages,
-		MaxTokens: maxTokens,
-		Stream:    false,
+		Model:       model,
+		Messages:    messages,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+		Stream:      false,
 	})
+	observe(model, start, resp, err)
+	traceResult(span, resp, err)
+	p.circuit.record(ctx, err)
 
 	if err != nil {
-		logrus.WithError(err).WithField("model", model).Error("❌ OpenRouter vision API request failed")
+		logging.From(ctx).WithError(err).WithField("model", model).Error("❌ OpenRouter vision API request failed")
 		return "", fmt.Errorf("openrouter vision API error: %w", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		logrus.WithField("model", model).Error("❌ No response choices returned from vision model")
+		logging.From(ctx).WithField("model", model).Error("❌ No response choices returned from vision model")
 		return "", fmt.Errorf("no response choices returned from vision model")
 	}
 
 	content := resp.Choices[0].Message.Content
 
-	logrus.WithFields(logrus.Fields{
+	logging.From(ctx).WithFields(logrus.Fields{
 		"model":            model,
 		"content_length":   len(content),
 		"prompt_tokens":    resp.Usage.PromptTokens,
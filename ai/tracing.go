@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"context"
+	"errors"
+
+	"factory_bot/tracing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// startSpan opens a client span for a chat completion, named and attributed
+// after the OpenTelemetry GenAI conventions.
+func startSpan(ctx context.Context, model string, maxTokens, messages int) (context.Context, *tracing.Span) {
+	return tracing.StartKind(ctx, tracing.KindClient, "chat "+model,
+		tracing.String("gen_ai.system", "openrouter"),
+		tracing.String("gen_ai.operation.name", "chat"),
+		tracing.String("gen_ai.request.model", model),
+		tracing.Int("gen_ai.request.max_tokens", maxTokens),
+		tracing.Int("gen_ai.request.messages", messages),
+	)
+}
+
+// traceResult records the outcome of a chat completion on its span.
+func traceResult(span *tracing.Span, resp openai.ChatCompletionResponse, err error) {
+	switch {
+	case err != nil:
+		span.SetAttributes(tracing.String("error.class", classifyError(err)))
+		span.RecordError(err)
+	case len(resp.Choices) == 0:
+		span.RecordError(errors.New("no response choices returned"))
+	default:
+		span.SetAttributes(
+			tracing.String("gen_ai.response.model", resp.Model),
+			tracing.Int("gen_ai.usage.input_tokens", resp.Usage.PromptTokens),
+			tracing.Int("gen_ai.usage.output_tokens", resp.Usage.CompletionTokens),
+			tracing.String("gen_ai.response.finish_reason", string(resp.Choices[0].FinishReason)),
+		)
+	}
+}
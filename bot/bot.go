@@ -2,25 +2,73 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"factory_bot/ai"
 	"factory_bot/config"
 	"factory_bot/database"
-	"factory_bot/instructions"
+	"factory_bot/logging"
+	"factory_bot/markdown"
+	"factory_bot/scheduler"
+	"factory_bot/tracing"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sashabaranov/go-openai"
 	"github.com/sirupsen/logrus"
 )
 
+// cancelNoticeTimeout is how long cancelled handlers get to notify users.
+const cancelNoticeTimeout = 5 * time.Second
+
+// maxMessageLength is Telegram's limit on message text, in UTF-16 code units.
+const maxMessageLength = 4096
+
+// partReserve leaves room for "(1/3)" markers and reopened formatting in split messages.
+const partReserve = 16
+
 type Bot struct {
 	api        *tgbotapi.BotAPI
-	config     *config.Config
 	db         *database.Database
 	aiProvider *ai.Provider
+	scheduler  *scheduler.Scheduler
+	sender     *sender // rate-limited outgoing requests
+
+	// cfg is replaced as a whole by Reload; read it through config()
+	cfg      atomic.Pointer[config.Config]
+	reloadMu sync.Mutex
+
+	// lastPoll is the time of the last successful getUpdates in UnixNano
+	lastPoll atomic.Int64
+
+	// started bounds the history sent to the model, so each run starts with a
+	// fresh context; older messages are kept for shift reports
+	started time.Time
+
+	// workCtx is passed to handlers; Shutdown cancels it when the grace period expires
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+	dispatcher *dispatcher
+	workers    sync.WaitGroup
+
+	commands    map[string]*Command
+	commandList []*Command
+
+	// prompts caches the active prompt versions by name; read it through prompt()
+	promptsMu sync.RWMutex
+	prompts   map[string]database.Prompt
+
+	dialogsMu sync.Mutex
+	dialogs   map[chatUser]*dialogSession
+
+	ticketsMu    sync.Mutex
+	ticketDrafts map[chatUser]*database.Ticket // asker -> ticket suggested by the AI
+
+	feedbackMu      sync.Mutex
+	pendingComments map[chatUser]pendingComment // rating awaiting a comment
 }
 
 func New(cfg *config.Config) (*Bot, error) {
@@ -31,299 +79,440 @@ func New(cfg *config.Config) (*Bot, error) {
 	}
 
 	// Initialize database
-	db, err := database.New("./data/bot.db")
+	db, err := database.New(cfg.DatabasePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
-	// Clear chat history on startup
-	err = db.ClearAllChatHistory()
-	if err != nil {
-		logrus.WithError(err).Warn("Failed to clear chat history on startup")
-	} else {
-		logrus.Info("Chat history cleared on startup")
-	}
-
 	// Initialize AI provider
 	aiProvider := ai.NewProvider(cfg.OpenRouterKey)
 
-	return &Bot{
-		api:        bot,
-		config:     cfg,
-		db:         db,
-		aiProvider: aiProvider,
-	}, nil
+	b := &Bot{
+		api:             bot,
+		started:         time.Now(),
+		sender:          newSender(bot),
+		db:              db,
+		aiProvider:      aiProvider,
+		dialogs:         make(map[chatUser]*dialogSession),
+		ticketDrafts:    make(map[chatUser]*database.Ticket),
+		pendingComments: make(map[chatUser]pendingComment),
+	}
+
+	b.cfg.Store(cfg)
+	if err := b.syncPrompts(context.Background(), cfg); err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	b.workCtx, b.cancelWork = context.WithCancel(context.Background())
+	b.dispatcher = newDispatcher(cfg.Workers, cfg.ChatQueueLimit, cfg.QueueLimit)
+	b.registerDispatcherMetrics()
+	b.scheduler = b.newScheduler()
+
+	// Build the command router
+	b.commandList = b.commandRegistry()
+	b.commands = make(map[string]*Command, len(b.commandList))
+	for _, cmd := range b.commandList {
+		b.commands[cmd.Name] = cmd
+	}
+
+	return b, nil
 }
 
-func (b *Bot) Start() error {
+// Start receives updates until ctx is cancelled. In-flight handlers keep
+// running; call Shutdown to wait for them.
+func (b *Bot) Start(ctx context.Context) error {
 	b.api.Debug = false
 	logrus.Infof("Bot authorized: %s", b.api.Self.UserName)
 
+	// Publish the command menu generated from the router
+	b.syncCommands()
+
+	// Background workers stop producing new work once receiving stops
+	b.workers.Add(3)
+	go func() {
+		defer b.workers.Done()
+		// Expose Prometheus metrics and health checks
+		b.serveMonitoring(ctx)
+	}()
+	go func() {
+		defer b.workers.Done()
+		// Post end-of-shift reports to the management chat
+		b.runShiftReports(ctx)
+	}()
+	go func() {
+		defer b.workers.Done()
+		// Deliver reminders and recurring notifications
+		b.scheduler.Run(ctx)
+	}()
+
+	if b.config().Mode == config.ModeWebhook {
+		return b.serveWebhook(ctx)
+	}
+	return b.poll(ctx)
+}
+
+// poll receives updates with long polling until ctx is cancelled. Updates of
+// an interrupted poll are not acknowledged, so Telegram redelivers them after
+// a restart.
+func (b *Bot) poll(ctx context.Context) error {
+	// A webhook left over from webhook mode would make getUpdates fail
+	if err := b.deleteWebhook(); err != nil {
+		logrus.WithError(err).Warn("⚠️ Failed to remove webhook before polling")
+	}
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
+	b.markPolled() // counts as a poll until the first one returns
 
-	updates := b.api.GetUpdatesChan(u)
+	type pollResult struct {
+		updates []tgbotapi.Update
+		err     error
+	}
 
-	for update := range updates {
-		if update.Message == nil {
+	for {
+		results := make(chan pollResult, 1)
+		go func(config tgbotapi.UpdateConfig) {
+			updates, err := b.api.GetUpdates(config)
+			results <- pollResult{updates, err}
+		}(u)
+
+		var result pollResult
+		select {
+		case <-ctx.Done():
+			logrus.Info("🛑 Stopped receiving updates")
+			return nil
+		case result = <-results:
+		}
+
+		if result.err != nil {
+			logrus.WithError(result.err).Warn("⚠️ Failed to get updates, retrying in 3 seconds")
+			select {
+			case <-ctx.Done():
+				logrus.Info("🛑 Stopped receiving updates")
+				return nil
+			case <-time.After(3 * time.Second):
+			}
 			continue
 		}
 
-		go b.handleMessage(update.Message)
+		b.markPolled()
+		for _, update := range result.updates {
+			if update.UpdateID >= u.Offset {
+				u.Offset = update.UpdateID + 1
+				b.dispatchUpdate(update)
+			}
+		}
+	}
+}
+
+// dispatchUpdate queues an update for its handler, regardless of delivery
+// mode. Updates of one chat are handled in order; when the queue is full the
+// user is asked to wait instead.
+func (b *Bot) dispatchUpdate(update tgbotapi.Update) {
+	updatesReceived.Inc(updateType(update))
+
+	chatID := updateChatID(update)
+	ctx := logging.WithRequest(b.workCtx, logging.Request{
+		ID:       logging.NewRequestID(),
+		UpdateID: update.UpdateID,
+		ChatID:   chatID,
+		UserID:   updateUserID(update),
+	})
+
+	if update.Message == nil && update.CallbackQuery == nil {
+		return
 	}
 
-	return nil
+	err := b.dispatcher.Submit(chatID, func() { b.handleUpdate(ctx, update) })
+	if err == nil {
+		return
+	}
+
+	logging.From(ctx).WithError(err).Warn("⚠️ Update rejected by dispatcher")
+
+	// The notice must not hold up receiving updates for other chats, so it is
+	// dropped when the chat is already at its rate limit
+	if errors.Is(err, errQueueFull) {
+		if update.CallbackQuery != nil {
+			b.sender.TrySend(ctx, 0, tgbotapi.NewCallback(update.CallbackQuery.ID, b.t(ctx, update.CallbackQuery.From.ID, "busy")))
+			return
+		}
+		b.sender.TrySend(ctx, chatID, tgbotapi.NewMessage(chatID, b.t(ctx, chatID, "busy")))
+	}
 }
 
-func (b *Bot) handleMessage(message *tgbotapi.Message) {
+// handleUpdate runs the handler for a dispatched update under a server span,
+// the root of the update's trace.
+func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	ctx, span := tracing.StartKind(ctx, tracing.KindServer, "update "+updateType(update),
+		tracing.String("request_id", logging.RequestID(ctx)),
+		tracing.Int("telegram.update_id", update.UpdateID),
+		tracing.Int64("telegram.chat_id", updateChatID(update)),
+	)
+	defer span.End()
+
+	switch {
+	case update.Message != nil:
+		b.handleMessage(ctx, update.Message)
+	case update.CallbackQuery != nil:
+		b.handleCallback(ctx, update.CallbackQuery)
+	}
+}
+
+// Shutdown waits for queued and in-flight handlers and background workers. Handlers
+// still running after grace have their AI calls cancelled and tell the user
+// to retry; they get a few more seconds to send that notice.
+func (b *Bot) Shutdown(grace time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		b.dispatcher.Close()
+		b.workers.Wait()
+		close(done)
+	}()
+
+	stats := b.dispatcher.Stats()
+	logrus.WithFields(logrus.Fields{
+		"grace_period": grace.String(),
+		"queued":       stats.Queued,
+		"active":       stats.Active,
+	}).Info("⏳ Waiting for in-flight requests")
+
+	select {
+	case <-done:
+		logrus.Info("✅ All in-flight requests finished")
+		return
+	case <-time.After(grace):
+	}
+
+	logrus.Warn("⚠️ Grace period expired, cancelling in-flight requests")
+	b.cancelWork()
+
+	select {
+	case <-done:
+		logrus.Info("✅ In-flight requests cancelled")
+	case <-time.After(cancelNoticeTimeout):
+		logrus.Warn("⚠️ Some requests did not stop in time")
+	}
+}
+
+// isShutdownCancel reports whether err comes from Shutdown cancelling work.
+func (b *Bot) isShutdownCancel(err error) bool {
+	return b.workCtx.Err() != nil && errors.Is(err, context.Canceled)
+}
+
+// replyError tells the user a request failed, that it was interrupted by a
+// restart, or that the AI provider is unavailable. key is the catalog key of
+// the failure message. The request ID is appended so users can quote it to
+// support.
+func (b *Bot) replyError(ctx context.Context, chatID int64, key string, err error) {
+	switch {
+	case b.isShutdownCancel(err):
+		key = "error.restarting"
+		// The notice still has to be sent after the work context is cancelled
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), cancelNoticeTimeout)
+		defer cancel()
+	case errors.Is(err, ai.ErrCircuitOpen):
+		key = "error.ai_unavailable"
+	}
+
+	text := b.t(ctx, chatID, key)
+	if id := logging.RequestID(ctx); id != "" {
+		text += "\n\n" + b.t(ctx, chatID, "error.request_id", id)
+	}
+	b.sendMessage(ctx, chatID, text)
+}
+
+func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
 	userID := message.From.ID
 	username := message.From.UserName
 	text := message.Text
 
 	// Log incoming message
-	logrus.WithFields(logrus.Fields{
-		"user_id":    userID,
+	logging.From(ctx).WithFields(logrus.Fields{
 		"username":   username,
 		"first_name": message.From.FirstName,
-		"chat_id":    message.Chat.ID,
 		"message_id": message.MessageID,
 		"text_len":   len(text),
 	}).Info("📨 Incoming message")
 
-	// Store user and message in database
-	err := b.db.AddUser(userID, username, message.From.FirstName, message.From.LastName)
+	// Store user in database; commands are stored here, other messages by the
+	// handlers once the history for the prompt has been loaded
+	err := b.db.AddUser(ctx, userID, username, message.From.FirstName, message.From.LastName, message.From.LanguageCode)
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"user_id":  userID,
-			"username": username,
-		}).Error("❌ Failed to store user")
+		logging.From(ctx).WithError(err).WithField("username", username).Error("❌ Failed to store user")
 	} else {
-		logrus.WithField("user_id", userID).Debug("✅ User stored successfully")
+		logging.From(ctx).Debug("✅ User stored successfully")
 	}
 
-	err = b.db.SaveMessage(userID, username, text, "user")
-	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"user_id":  userID,
-			"username": username,
-		}).Error("❌ Failed to store message")
-	} else {
-		logrus.WithField("user_id", userID).Debug("✅ Message stored successfully")
+	// A pending "what was wrong?" follow-up takes the next text message
+	if b.consumeFeedbackComment(ctx, message) {
+		return
+	}
+
+	// Multi-step dialogs (e.g. incident reports) take over the chat until finished
+	if b.routeDialogMessage(ctx, message) {
+		return
 	}
 
 	// Handle commands
-	if strings.HasPrefix(text, "/") {
-		logrus.WithFields(logrus.Fields{
-			"user_id": userID,
-			"command": strings.Split(text, " ")[0],
-		}).Info("Processing command")
-		b.handleCommand(message)
+	if message.IsCommand() {
+		logging.From(ctx).WithField("command", message.Command()).Info("Processing command")
+		if err := b.db.SaveMessage(ctx, message.Chat.ID, username, text, "user"); err != nil {
+			logging.From(ctx).WithError(err).Error("❌ Failed to store message")
+		}
+		b.handleCommand(ctx, message)
 		return
 	}
 
 	// Handle photo messages
 	if len(message.Photo) > 0 {
-		logrus.WithFields(logrus.Fields{
-			"user_id":     userID,
+		logging.From(ctx).WithFields(logrus.Fields{
 			"photo_count": len(message.Photo),
 			"has_caption": message.Caption != "",
 			"caption_len": len(message.Caption),
 		}).Info("Processing image message")
-		b.handlePhoto(message)
+		b.handlePhoto(ctx, message)
 		return
 	}
 
 	// Process regular text message
-	logrus.WithFields(logrus.Fields{
-		"user_id":  userID,
-		"text_len": len(text),
-	}).Info("💬 Processing text message")
-	b.processUserMessage(message)
-}
-
-func (b *Bot) handleCommand(message *tgbotapi.Message) {
-	cmd := strings.Split(message.Text, " ")[0]
-	userID := message.Chat.ID
-
-	logrus.WithFields(logrus.Fields{
-		"user_id": userID,
-		"command": cmd,
-	}).Info("⚡ Executing command")
-
-	switch cmd {
-	case "/start":
-		b.sendMessage(userID, instructions.InitMessageEN)
-		logrus.WithField("user_id", userID).Info("🚀 Start command executed")
-	default:
-		b.sendMessage(userID, "Неизвестная команда. / Unknown command.")
-		logrus.WithFields(logrus.Fields{
-			"user_id": userID,
-			"command": cmd,
-		}).Warn("❓ Unknown command received")
-	}
+	logging.From(ctx).WithField("text_len", len(text)).Info("💬 Processing text message")
+	b.processUserMessage(ctx, message)
 }
 
-func (b *Bot) handlePhoto(message *tgbotapi.Message) {
-	ctx := context.Background()
+func (b *Bot) handlePhoto(ctx context.Context, message *tgbotapi.Message) {
 	userID := message.Chat.ID
 	startTime := time.Now()
 
-	logrus.WithField("user_id", userID).Info("🖼️ Starting image processing")
+	logging.From(ctx).Info("🖼️ Starting image processing")
 
-	// Send typing indicator
-	typing := tgbotapi.NewChatAction(userID, tgbotapi.ChatTyping)
-	b.api.Send(typing)
-
-	// Save image message to database
-	imageText := func() string {
-		if message.Caption != "" {
-			return "[Изображение] " + message.Caption
-		}
-		return "[Изображение без описания]"
-	}()
-	
-	err := b.db.SaveMessage(userID, message.From.UserName, imageText, "user")
-	if err != nil {
-		logrus.WithError(err).WithField("user_id", userID).Error("❌ Failed to store image message")
+	cfg := b.config()
+	mode := b.chatMode(ctx, cfg, userID)
+	if !mode.Allows(config.ToolImages) {
+		b.sendMessage(ctx, userID, b.t(ctx, userID, "mode.no_images", modeName(b.locale(ctx, userID), mode)))
+		return
 	}
+	mode = b.withUserModel(ctx, cfg, userID, mode, true)
+	_, visionModel := modeModels(cfg, mode)
+
+	// Send typing indicator
+	b.sendTyping(ctx, userID)
 
 	// Get the largest photo
 	photo := message.Photo[len(message.Photo)-1]
-	
-	logrus.WithFields(logrus.Fields{
-		"user_id":   userID,
+
+	logging.From(ctx).WithFields(logrus.Fields{
 		"file_id":   photo.FileID,
 		"file_size": photo.FileSize,
 		"width":     photo.Width,
 		"height":    photo.Height,
 	}).Info("📋 Processing photo details")
-	
-	// Get file URL
-	file, err := b.api.GetFile(tgbotapi.FileConfig{FileID: photo.FileID})
+
+	// Download the photo; the model gets it inline rather than as a Telegram link
+	imageURL, err := b.imageDataURL(ctx, photo.FileID)
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"user_id": userID,
-			"file_id": photo.FileID,
-		}).Error("❌ Failed to get photo file")
-		b.sendMessage(userID, "❌ Ошибка обработки изображения / Error processing image")
+		logging.From(ctx).WithError(err).WithField("file_id", photo.FileID).Error("❌ Failed to download photo")
+		b.replyError(ctx, userID, "error.image", err)
 		return
 	}
 
-	fileURL := file.Link(b.api.Token)
-	logrus.WithFields(logrus.Fields{
-		"user_id":  userID,
-		"file_url": fileURL,
-	}).Info("File URL obtained")
+	// Load history before storing the current image so it is not sent twice
+	history, err := b.db.GetChatHistory(ctx, userID, b.started, cfg.HistoryLimit)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Failed to get chat history")
+	}
+
+	// Save image message to database as a multimodal history entry
+	err = b.db.SaveImageMessage(ctx, userID, message.From.UserName, message.Caption, photo.FileID)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Failed to store image message")
+	}
 
-	// Prepare messages for AI with image
+	// Prepare messages for AI: history (with the newest images re-sent) and the current image
+	historyMessages, _ := b.historyMessages(ctx, history, cfg.MaxPromptImages-1)
+	systemPrompt, promptVersion := b.systemPrompt(ctx, cfg, userID, mode, true)
 	messages := []openai.ChatCompletionMessage{
 		{
 			Role:    openai.ChatMessageRoleSystem,
-			Content: instructions.MainInstructions + "\n\n" + instructions.ImageInstruction,
-		},
-		{
-			Role: openai.ChatMessageRoleUser,
-			MultiContent: []openai.ChatMessagePart{
-				{
-					Type: openai.ChatMessagePartTypeText,
-					Text: func() string {
-						if message.Caption != "" {
-							return message.Caption
-						}
-						return "Проанализируй это изображение для производства / Analyze this image for factory operations"
-					}(),
-				},
-				{
-					Type: openai.ChatMessagePartTypeImageURL,
-					ImageURL: &openai.ChatMessageImageURL{
-						URL:    fileURL,
-						Detail: openai.ImageURLDetailLow,
-					},
-				},
-			},
+			Content: systemPrompt,
 		},
 	}
+	messages = append(messages, historyMessages...)
+	messages = append(messages, imageMessage(message.Caption, imageURL, cfg.ImageDetail))
 
-	logrus.WithFields(logrus.Fields{
-		"user_id": userID,
-		"model":   b.config.VisionModel,
+	logging.From(ctx).WithFields(logrus.Fields{
+		"model":         visionModel,
+		"mode":          mode.Key,
+		"history_count": len(history),
 	}).Info("Sending image to AI model")
 
-	response, err := b.aiProvider.GenerateWithVision(ctx, messages, b.config.VisionModel, 1500)
+	response, err := b.aiProvider.GenerateWithVision(ctx, messages, visionModel, cfg.VisionMaxTokens, mode.Temperature)
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"user_id": userID,
-			"model":   b.config.VisionModel,
-		}).Error("❌ Failed to process image with AI")
-		b.sendMessage(userID, "❌ Ошибка анализа изображения / Error analyzing image")
+		logging.From(ctx).WithError(err).WithField("model", visionModel).Error("❌ Failed to process image with AI")
+		b.replyError(ctx, userID, "error.image_analysis", err)
 		return
 	}
 
 	processingTime := time.Since(startTime)
-	logrus.WithFields(logrus.Fields{
-		"user_id":         userID,
-		"model":           b.config.VisionModel,
+	logging.From(ctx).WithFields(logrus.Fields{
+		"model":           visionModel,
 		"response_length": len(response),
 		"processing_time": processingTime.String(),
 	}).Info("✅ Image processed successfully")
 
-	// Save bot response to database
-	err = b.db.SaveMessage(userID, b.api.Self.UserName, response, "assistant")
-	if err != nil {
-		logrus.WithError(err).WithField("user_id", userID).Error("❌ Failed to save bot response")
-	}
-
-	b.sendMessage(userID, response)
+	b.deliverAnswer(ctx, userID, response, answerInfo{UserID: message.From.ID, Model: visionModel, Mode: mode, PromptVersion: promptVersion})
 }
 
-func (b *Bot) processUserMessage(message *tgbotapi.Message) {
-	ctx := context.Background()
+func (b *Bot) processUserMessage(ctx context.Context, message *tgbotapi.Message) {
 	userID := message.Chat.ID
 	text := message.Text
 	startTime := time.Now()
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":  userID,
-		"text_len": len(text),
-	}).Info("Starting text processing")
+	logging.From(ctx).WithField("text_len", len(text)).Info("Starting text processing")
 
 	// Send typing indicator
-	typing := tgbotapi.NewChatAction(userID, tgbotapi.ChatTyping)
-	b.api.Send(typing)
+	b.sendTyping(ctx, userID)
+
+	cfg := b.config()
 
-	// Get chat history (20 messages max)
-	history, err := b.db.GetChatHistory(userID, 20)
+	// Get chat history before storing the current message so it is not sent twice
+	history, err := b.db.GetChatHistory(ctx, userID, b.started, cfg.HistoryLimit)
 	if err != nil {
-		logrus.WithError(err).WithField("user_id", userID).Error("❌ Failed to get chat history")
+		logging.From(ctx).WithError(err).Error("❌ Failed to get chat history")
 	} else {
-		logrus.WithFields(logrus.Fields{
-			"user_id":       userID,
-			"history_count": len(history),
-		}).Info("📚 Chat history retrieved")
+		logging.From(ctx).WithField("history_count", len(history)).Info("📚 Chat history retrieved")
+	}
+
+	err = b.db.SaveMessage(ctx, userID, message.From.UserName, text, "user")
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Failed to store message")
 	}
 
+	// Add chat history; recent images are re-sent so follow-up questions can refer to them
+	mode := b.chatMode(ctx, cfg, userID)
+	maxImages := 0
+	if mode.Allows(config.ToolImages) {
+		maxImages = cfg.MaxPromptImages
+	}
+	historyMessages, hasImages := b.historyMessages(ctx, history, maxImages)
+
+	mode = b.withUserModel(ctx, cfg, userID, mode, hasImages)
+	model, visionModel := modeModels(cfg, mode)
+	maxTokens := cfg.TextMaxTokens
+	if hasImages {
+		model = visionModel
+		maxTokens = cfg.VisionMaxTokens
+	}
+	systemPrompt, promptVersion := b.systemPrompt(ctx, cfg, userID, mode, hasImages)
+
 	// Prepare messages for AI with history
 	messages := []openai.ChatCompletionMessage{
 		{
 			Role:    openai.ChatMessageRoleSystem,
-			Content: instructions.MainInstructions,
+			Content: systemPrompt,
 		},
 	}
-
-	// Add chat history
-	for _, msg := range history {
-		if msg.Role == "user" {
-			messages = append(messages, openai.ChatCompletionMessage{
-				Role:    openai.ChatMessageRoleUser,
-				Content: msg.Text,
-			})
-		} else if msg.Role == "assistant" {
-			messages = append(messages, openai.ChatCompletionMessage{
-				Role:    openai.ChatMessageRoleAssistant,
-				Content: msg.Text,
-			})
-		}
-	}
+	messages = append(messages, historyMessages...)
 
 	// Add current message
 	messages = append(messages, openai.ChatCompletionMessage{
@@ -331,112 +520,150 @@ func (b *Bot) processUserMessage(message *tgbotapi.Message) {
 		Content: text,
 	})
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":       userID,
-		"model":         b.config.TextModel,
+	logging.From(ctx).WithFields(logrus.Fields{
+		"model":          model,
 		"total_messages": len(messages),
-		"history_count": len(history),
+		"history_count":  len(history),
+		"has_images":     hasImages,
+		"mode":           mode.Key,
 	}).Info("Sending text to AI model")
 
-	response, err := b.aiProvider.Generate(ctx, messages, b.config.TextModel, 1024)
+	var response string
+	if hasImages {
+		response, err = b.aiProvider.GenerateWithVision(ctx, messages, model, maxTokens, mode.Temperature)
+	} else {
+		response, err = b.aiProvider.Generate(ctx, messages, model, maxTokens, mode.Temperature)
+	}
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"user_id": userID,
-			"model":   b.config.TextModel,
-		}).Error("❌ Failed to process message with AI")
-		b.sendMessage(userID, "❌ Ошибка обработки запроса / Error processing request")
+		logging.From(ctx).WithError(err).WithField("model", model).Error("❌ Failed to process message with AI")
+		b.replyError(ctx, userID, "error.request", err)
 		return
 	}
 
 	processingTime := time.Since(startTime)
-	logrus.WithFields(logrus.Fields{
-		"user_id":         userID,
-		"model":           b.config.TextModel,
+	logging.From(ctx).WithFields(logrus.Fields{
+		"model":           model,
 		"response_length": len(response),
 		"processing_time": processingTime.String(),
 	}).Info("✅ Text processed successfully")
 
+	b.deliverAnswer(ctx, userID, response, answerInfo{UserID: message.From.ID, Model: model, Mode: mode, PromptVersion: promptVersion})
+}
+
+// answerInfo describes how an answer was produced.
+type answerInfo struct {
+	UserID        int64 // user who asked
+	Model         string
+	Mode          config.Mode
+	PromptVersion string // IDs of the prompt versions used, e.g. "12+7"
+}
+
+// deliverAnswer stores a model answer in the history and sends it, turning a
+// ticket suggestion from the model into a "create ticket" button.
+func (b *Bot) deliverAnswer(ctx context.Context, chatID int64, response string, info answerInfo) {
+	response, draft := extractTicketSuggestion(response)
+
 	// Save bot response to database
-	err = b.db.SaveMessage(userID, b.api.Self.UserName, response, "assistant")
+	err := b.db.SaveMessage(ctx, chatID, b.api.Self.UserName, response, "assistant")
 	if err != nil {
-		logrus.WithError(err).WithField("user_id", userID).Error("❌ Failed to save bot response")
+		logging.From(ctx).WithError(err).Error("❌ Failed to save bot response")
 	}
 
-	b.sendMessage(userID, response)
+	if draft != nil {
+		b.sendAnswer(ctx, chatID, response, info, b.offerTicketDraft(ctx, chatUser{chatID, info.UserID}, draft))
+		return
+	}
+	b.sendAnswer(ctx, chatID, response, info)
 }
 
-func (b *Bot) sendMessage(chatID int64, text string) *tgbotapi.Message {
-	const maxMessageLength = 4096
+// sendTyping shows the "typing…" indicator while an answer is prepared.
+func (b *Bot) sendTyping(ctx context.Context, chatID int64) {
+	if err := b.sender.Request(ctx, 0, tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping)); err != nil {
+		logging.From(ctx).WithError(err).WithField("chat_id", chatID).Debug("Failed to send typing indicator")
+	}
+}
 
-	logrus.WithFields(logrus.Fields{
-		"chat_id":     chatID,
-		"text_length": len(text),
-		"needs_split": len(text) > maxMessageLength,
-	}).Info("Sending message")
+func (b *Bot) sendMessage(ctx context.Context, chatID int64, text string) *tgbotapi.Message {
+	return b.sendText(ctx, chatID, text, true, nil)
+}
 
-	if len(text) <= maxMessageLength {
-		msg := tgbotapi.NewMessage(chatID, text)
-		// Try markdown first, fallback to plain text
-		msg.ParseMode = tgbotapi.ModeMarkdown
+// sendMessageWithMarkup sends text like sendMessage and attaches markup
+// (e.g. an inline keyboard) to the last part.
+func (b *Bot) sendMessageWithMarkup(ctx context.Context, chatID int64, text string, markup interface{}) *tgbotapi.Message {
+	return b.sendText(ctx, chatID, text, true, markup)
+}
 
-		sent, err := b.api.Send(msg)
-		if err != nil {
-			// Retry without markdown if parsing fails
-			logrus.WithError(err).WithField("chat_id", chatID).Warn("⚠️ Markdown parsing failed, retrying as plain text")
-			msg.ParseMode = ""
-			sent, err = b.api.Send(msg)
-			if err != nil {
-				logrus.WithError(err).WithField("chat_id", chatID).Error("❌ Failed to send message")
-				return nil
-			}
-		}
+// sendPlainMessage sends text without any parse mode.
+func (b *Bot) sendPlainMessage(ctx context.Context, chatID int64, text string) *tgbotapi.Message {
+	return b.sendText(ctx, chatID, text, false, nil)
+}
 
-		logrus.WithFields(logrus.Fields{
-			"chat_id":    chatID,
-			"message_id": sent.MessageID,
-		}).Info("✅ Message sent successfully")
-		return &sent
+func (b *Bot) sendPlainMessageWithMarkup(ctx context.Context, chatID int64, text string, markup interface{}) *tgbotapi.Message {
+	return b.sendText(ctx, chatID, text, false, markup)
+}
+
+// sendText sends text, split into parts if needed. Markdown text is rendered
+// as Telegram HTML part by part, so each part is valid on its own.
+func (b *Bot) sendText(ctx context.Context, chatID int64, text string, isMarkdown bool, markup interface{}) *tgbotapi.Message {
+	var parts []string
+	if isMarkdown {
+		parts = markdown.Split(text, maxMessageLength-partReserve)
+	} else {
+		parts = markdown.SplitPlain(text, maxMessageLength-partReserve)
 	}
 
-	// Split long message
-	parts := b.splitMessage(text, maxMessageLength)
-	logrus.WithFields(logrus.Fields{
-		"chat_id":    chatID,
+	logging.From(ctx).WithFields(logrus.Fields{
+		"chat_id":     chatID,
+		"text_length": markdown.UTF16Len(text),
 		"parts_count": len(parts),
-		"total_length": len(text),
-	}).Info("Splitting long message")
+	}).Info("Sending message")
+
+	messageParts.Observe(float64(len(parts)))
 
 	var lastMsg *tgbotapi.Message
 
 	for i, part := range parts {
 		msg := tgbotapi.NewMessage(chatID, part)
-		// Try markdown first, fallback to plain text
-		msg.ParseMode = tgbotapi.ModeMarkdown
+		if isMarkdown {
+			msg.Text = markdown.ToTelegramHTML(part)
+			msg.ParseMode = tgbotapi.ModeHTML
+		}
+		marker := ""
+		if b.config().PartMarkers && len(parts) > 1 {
+			marker = fmt.Sprintf("\n\n(%d/%d)", i+1, len(parts))
+			msg.Text += marker
+		}
+		if i == len(parts)-1 && markup != nil {
+			msg.ReplyMarkup = markup
+		}
 
-		sent, err := b.api.Send(msg)
-		if err != nil {
-			// Retry without markdown if parsing fails
-			logrus.WithError(err).WithFields(logrus.Fields{
+		sent, err := b.sender.Send(ctx, chatID, msg)
+		if err != nil && msg.ParseMode != "" && isParseError(err) {
+			// Should not happen with rendered HTML; send the text without markup
+			logging.From(ctx).WithError(err).WithFields(logrus.Fields{
 				"chat_id": chatID,
 				"part":    i + 1,
-			}).Warn("⚠️ Markdown parsing failed for part, retrying as plain text")
+			}).Warn("⚠️ HTML parsing failed, retrying as plain text")
+			msg.Text = markdown.ToPlainText(part) + marker
 			msg.ParseMode = ""
-			sent, err = b.api.Send(msg)
-			if err != nil {
-				logrus.WithError(err).WithFields(logrus.Fields{
-					"chat_id": chatID,
-					"part":    i + 1,
-				}).Error("❌ Failed to send message part")
-				continue
-			}
+			sent, err = b.sender.Send(ctx, chatID, msg)
+		}
+		if err != nil {
+			kind, _ := classifySendError(err)
+			logging.From(ctx).WithError(err).WithFields(logrus.Fields{
+				"chat_id": chatID,
+				"part":    i + 1,
+				"class":   kind.String(),
+			}).Error("❌ Failed to send message")
+			continue
 		}
 
-		logrus.WithFields(logrus.Fields{
-			"chat_id":    chatID,
-			"part":       i + 1,
+		logging.From(ctx).WithFields(logrus.Fields{
+			"chat_id":     chatID,
+			"part":        i + 1,
 			"total_parts": len(parts),
-			"message_id": sent.MessageID,
-		}).Info("Message part sent")
+			"message_id":  sent.MessageID,
+		}).Info("✅ Message sent successfully")
 
 		lastMsg = &sent
 	}
@@ -444,93 +671,6 @@ func (b *Bot) sendMessage(chatID int64, text string) *tgbotapi.Message {
 	return lastMsg
 }
 
-func (b *Bot) splitMessage(text string, maxLength int) []string {
-	if len(text) <= maxLength {
-		return []string{text}
-	}
-
-	var parts []string
-	paragraphs := strings.Split(text, "\n\n")
-	var currentPart strings.Builder
-
-	for i, paragraph := range paragraphs {
-		nextLength := currentPart.Len()
-		if i > 0 && currentPart.Len() > 0 {
-			nextLength += 2
-		}
-		nextLength += len(paragraph)
-
-		if nextLength > maxLength && currentPart.Len() > 0 {
-			parts = append(parts, strings.TrimSpace(currentPart.String()))
-			currentPart.Reset()
-		}
-
-		if len(paragraph) > maxLength {
-			if currentPart.Len() > 0 {
-				parts = append(parts, strings.TrimSpace(currentPart.String()))
-				currentPart.Reset()
-			}
-
-			sentences := b.splitBySentences(paragraph, maxLength)
-			for j, sentence := range sentences {
-				if j == len(sentences)-1 {
-					currentPart.WriteString(sentence)
-				} else {
-					parts = append(parts, strings.TrimSpace(sentence))
-				}
-			}
-		} else {
-			if currentPart.Len() > 0 {
-				currentPart.WriteString("\n\n")
-			}
-			currentPart.WriteString(paragraph)
-		}
-	}
-
-	if currentPart.Len() > 0 {
-		parts = append(parts, strings.TrimSpace(currentPart.String()))
-	}
-
-	return parts
-}
-
-func (b *Bot) splitBySentences(text string, maxLength int) []string {
-	if len(text) <= maxLength {
-		return []string{text}
-	}
-
-	var parts []string
-	sentences := strings.FieldsFunc(text, func(r rune) bool {
-		return r == '.' || r == '!' || r == '?'
-	})
-
-	var currentPart strings.Builder
-
-	for i, sentence := range sentences {
-		sentence = strings.TrimSpace(sentence)
-		if sentence == "" {
-			continue
-		}
-
-		if i < len(sentences)-1 {
-			sentence += ". "
-		}
-
-		if currentPart.Len()+len(sentence) > maxLength && currentPart.Len() > 0 {
-			parts = append(parts, strings.TrimSpace(currentPart.String()))
-			currentPart.Reset()
-		}
-
-		currentPart.WriteString(sentence)
-	}
-
-	if currentPart.Len() > 0 {
-		parts = append(parts, strings.TrimSpace(currentPart.String()))
-	}
-
-	return parts
-}
-
 func (b *Bot) Close() error {
 	return b.db.Close()
 }
@@ -0,0 +1,370 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"factory_bot/i18n"
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Role is the access level required to run a command.
+type Role int
+
+const (
+	RoleUser Role = iota
+	RoleAdmin
+)
+
+// Command is a bot command registered in the router. /help and the Telegram
+// command menu are generated from the registry.
+type Command struct {
+	Name        string // without the leading slash
+	Description string // catalog key of the menu and /help description
+	Usage       string // catalog key of the argument synopsis, e.g. "[дни]"
+	Role        Role
+	Group       bool // also offered in group chats
+	MinArgs     int
+	MaxArgs     int // -1 for unlimited
+	Handler     func(ctx context.Context, message *tgbotapi.Message, args []string)
+}
+
+// commandRegistry returns all commands in the order they appear in /help and the menu.
+func (b *Bot) commandRegistry() []*Command {
+	return []*Command{
+		{
+			Name:        "start",
+			Description: "cmd.start",
+			Group:       true,
+			MaxArgs:     -1,
+			Handler:     b.cmdStart,
+		},
+		{
+			Name:        "help",
+			Description: "cmd.help",
+			Group:       true,
+			MaxArgs:     0,
+			Handler:     b.cmdHelp,
+		},
+		{
+			Name:        "lang",
+			Description: "cmd.lang",
+			Usage:       "usage.lang",
+			Group:       true,
+			MaxArgs:     1,
+			Handler:     b.cmdLang,
+		},
+		{
+			Name:        "mode",
+			Description: "cmd.mode",
+			Usage:       "usage.mode",
+			MaxArgs:     1,
+			Handler:     b.cmdMode,
+		},
+		{
+			Name:        "model",
+			Description: "cmd.model",
+			Usage:       "usage.model",
+			MaxArgs:     1,
+			Handler:     b.cmdModel,
+		},
+		{
+			Name:        "cancel",
+			Description: "cmd.cancel",
+			Group:       true,
+			MaxArgs:     0,
+			Handler:     b.cmdCancel,
+		},
+		{
+			Name:        "incident",
+			Description: "cmd.incident",
+			Usage:       "usage.incident",
+			MaxArgs:     1,
+			Handler:     b.cmdIncident,
+		},
+		{
+			Name:        "incidentstatus",
+			Description: "cmd.incidentstatus",
+			Usage:       "usage.incidentstatus",
+			Role:        RoleAdmin,
+			MinArgs:     2,
+			MaxArgs:     -1,
+			Handler:     b.cmdIncidentStatus,
+		},
+		{
+			Name:        "ticket",
+			Description: "cmd.ticket",
+			Usage:       "usage.ticket",
+			Group:       true,
+			MaxArgs:     -1,
+			Handler:     b.cmdTicket,
+		},
+		{
+			Name:        "remind",
+			Description: "cmd.remind",
+			Usage:       "usage.remind",
+			Group:       true,
+			MaxArgs:     -1,
+			Handler:     b.cmdRemind,
+		},
+		{
+			Name:        "schedule",
+			Description: "cmd.schedule",
+			Usage:       "usage.schedule",
+			Role:        RoleAdmin,
+			MaxArgs:     -1,
+			Handler:     b.cmdSchedule,
+		},
+		{
+			Name:        "shiftreport",
+			Description: "cmd.shiftreport",
+			Usage:       "usage.shiftreport",
+			Role:        RoleAdmin,
+			MaxArgs:     2,
+			Handler:     b.cmdShiftReport,
+		},
+		{
+			Name:        "feedback",
+			Description: "cmd.feedback",
+			Usage:       "usage.feedback",
+			Role:        RoleAdmin,
+			MaxArgs:     1,
+			Handler:     b.cmdFeedback,
+		},
+		{
+			Name:        "department",
+			Description: "cmd.department",
+			Usage:       "usage.department",
+			Role:        RoleAdmin,
+			MinArgs:     1,
+			MaxArgs:     -1,
+			Handler:     b.cmdDepartment,
+		},
+		{
+			Name:        "status",
+			Description: "cmd.status",
+			Role:        RoleAdmin,
+			MaxArgs:     0,
+			Handler:     b.cmdStatus,
+		},
+		{
+			Name:        "prompt",
+			Description: "cmd.prompt",
+			Usage:       "usage.prompt",
+			Role:        RoleAdmin,
+			MaxArgs:     3,
+			Handler:     b.cmdPrompt,
+		},
+		{
+			Name:        "reload",
+			Description: "cmd.reload",
+			Role:        RoleAdmin,
+			MaxArgs:     0,
+			Handler:     b.cmdReload,
+		},
+	}
+}
+
+func (b *Bot) handleCommand(ctx context.Context, message *tgbotapi.Message) {
+	name := message.Command()
+	chatID := message.Chat.ID
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"user_id": chatID,
+		"command": name,
+	}).Info("⚡ Executing command")
+
+	cmd, ok := b.commands[name]
+	if !ok {
+		commandsHandled.Inc("unknown")
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "command.unknown"))
+		logging.From(ctx).WithFields(logrus.Fields{
+			"user_id": chatID,
+			"command": name,
+		}).Warn("❓ Unknown command received")
+		return
+	}
+
+	commandsHandled.Inc(cmd.Name)
+
+	if b.userRole(message.From.ID) < cmd.Role {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "command.admin_only"))
+		logging.From(ctx).WithFields(logrus.Fields{
+			"user_id": message.From.ID,
+			"command": name,
+		}).Warn("⛔ Command denied")
+		return
+	}
+
+	args := parseArgs(message.CommandArguments())
+	if len(args) < cmd.MinArgs || (cmd.MaxArgs >= 0 && len(args) > cmd.MaxArgs) {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "usage", cmd.synopsis(b.locale(ctx, chatID))))
+		return
+	}
+
+	cmd.Handler(ctx, message, args)
+}
+
+func (b *Bot) userRole(userID int64) Role {
+	if b.config().IsAdmin(userID) {
+		return RoleAdmin
+	}
+	return RoleUser
+}
+
+// synopsis renders the command with its arguments, e.g. "/feedback [дни]".
+func (c *Command) synopsis(l i18n.Locale) string {
+	if c.Usage == "" {
+		return "/" + c.Name
+	}
+	return "/" + c.Name + " " + i18n.T(l, c.Usage)
+}
+
+// parseArgs splits command arguments on whitespace, keeping double-quoted
+// strings together.
+func parseArgs(text string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+		started bool
+	)
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// syncCommands publishes the command menu to Telegram for private chats,
+// group chats and each admin's private chat, in every supported language.
+// The default language is also published without a language code.
+func (b *Bot) syncCommands() {
+	type scopedMenu struct {
+		name  string
+		scope tgbotapi.BotCommandScope
+		list  func(c *Command) bool
+	}
+
+	menus := []scopedMenu{
+		{"private", tgbotapi.NewBotCommandScopeAllPrivateChats(), func(c *Command) bool { return c.Role == RoleUser }},
+		{"group", tgbotapi.NewBotCommandScopeAllGroupChats(), func(c *Command) bool { return c.Role == RoleUser && c.Group }},
+	}
+	for _, adminID := range b.config().AdminIDs {
+		menus = append(menus, scopedMenu{
+			"admin:" + strconv.FormatInt(adminID, 10),
+			tgbotapi.NewBotCommandScopeChat(adminID),
+			func(c *Command) bool { return true },
+		})
+	}
+
+	for _, menu := range menus {
+		commands := func(l i18n.Locale) []tgbotapi.BotCommand {
+			var list []tgbotapi.BotCommand
+			for _, cmd := range b.commandList {
+				if menu.list(cmd) {
+					list = append(list, tgbotapi.BotCommand{Command: cmd.Name, Description: i18n.T(l, cmd.Description)})
+				}
+			}
+			return list
+		}
+
+		requests := []tgbotapi.Chattable{
+			tgbotapi.NewSetMyCommandsWithScope(menu.scope, commands(b.config().DefaultLanguage)...),
+		}
+		for _, l := range i18n.Locales {
+			requests = append(requests, tgbotapi.NewSetMyCommandsWithScopeAndLanguage(menu.scope, string(l), commands(l)...))
+		}
+		for _, req := range requests {
+			if _, err := b.api.Request(req); err != nil {
+				logrus.WithError(err).WithField("scope", menu.name).Warn("⚠️ Failed to set bot commands")
+			}
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"scope":     menu.name,
+			"commands":  len(commands(b.config().DefaultLanguage)),
+			"languages": len(i18n.Locales),
+		}).Info("📋 Bot commands synced")
+	}
+}
+
+// deleteAdminCommands removes the admin menu published for a former admin's
+// private chat in every language, so the chat shows the private chat menu.
+func (b *Bot) deleteAdminCommands(adminID int64) {
+	scope := tgbotapi.NewBotCommandScopeChat(adminID)
+	requests := []tgbotapi.Chattable{tgbotapi.NewDeleteMyCommandsWithScope(scope)}
+	for _, l := range i18n.Locales {
+		requests = append(requests, tgbotapi.NewDeleteMyCommandsWithScopeAndLanguage(scope, string(l)))
+	}
+	for _, req := range requests {
+		if _, err := b.api.Request(req); err != nil {
+			logrus.WithError(err).WithField("admin_id", adminID).Warn("⚠️ Failed to delete admin commands")
+			return
+		}
+	}
+	logrus.WithField("admin_id", adminID).Info("📋 Admin commands removed")
+}
+
+func (b *Bot) cmdStart(ctx context.Context, message *tgbotapi.Message, args []string) {
+	b.sendMessage(ctx, message.Chat.ID, b.t(ctx, message.Chat.ID, "start", b.config().PlantName))
+	logging.From(ctx).WithField("user_id", message.Chat.ID).Info("🚀 Start command executed")
+}
+
+func (b *Bot) cmdHelp(ctx context.Context, message *tgbotapi.Message, args []string) {
+	role := b.userRole(message.From.ID)
+	l := b.locale(ctx, message.Chat.ID)
+
+	var help strings.Builder
+	help.WriteString(i18n.T(l, "help.title") + "\n\n")
+	for _, cmd := range b.commandList {
+		if role < cmd.Role {
+			continue
+		}
+		fmt.Fprintf(&help, "%s — %s\n", cmd.synopsis(l), i18n.T(l, cmd.Description))
+	}
+
+	b.sendPlainMessage(ctx, message.Chat.ID, help.String())
+}
+
+func (b *Bot) cmdFeedback(ctx context.Context, message *tgbotapi.Message, args []string) {
+	days := feedbackReportDays
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			b.sendMessage(ctx, message.Chat.ID, b.t(ctx, message.Chat.ID, "usage", b.commands["feedback"].synopsis(b.locale(ctx, message.Chat.ID))))
+			return
+		}
+		days = n
+	}
+	b.sendFeedbackReport(ctx, message.Chat.ID, days)
+}
+
+func (b *Bot) cmdStatus(ctx context.Context, message *tgbotapi.Message, args []string) {
+	stats := b.dispatcher.Stats()
+	b.sendPlainMessage(ctx, message.Chat.ID, b.t(ctx, message.Chat.ID, "status.report",
+		stats.Workers, stats.Active, stats.Queued, stats.Peak, b.config().QueueLimit,
+		stats.Chats, stats.Processed, stats.Rejected))
+}
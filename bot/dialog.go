@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackDialog prefixes callback data addressed to the chat's active dialog.
+const callbackDialog = "dlg:"
+
+// dialog is a multi-step conversation that takes over a chat's messages until
+// it finishes or the user sends /cancel.
+type dialog interface {
+	// start sends the first question.
+	start(ctx context.Context)
+	// handleMessage processes the next message and reports whether the dialog is finished.
+	handleMessage(ctx context.Context, message *tgbotapi.Message) bool
+	// handleCallback processes a dialog button (data without the "dlg:" prefix)
+	// and reports whether the dialog is finished.
+	handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery, data string) bool
+}
+
+// chatUser identifies a member of a chat. Dialogs, ticket suggestions and
+// rating comments are keyed by it so other members of a group cannot take
+// them over.
+type chatUser struct {
+	chatID int64
+	userID int64
+}
+
+// dialogSession is the active dialog of a user in a chat. The dispatcher
+// handles a chat's updates one at a time, so a dialog needs no locking of its
+// own; endDialog compares sessions so a finished dialog cannot remove a newer one.
+type dialogSession struct {
+	d dialog
+}
+
+func (b *Bot) startDialog(ctx context.Context, key chatUser, d dialog) {
+	b.dialogsMu.Lock()
+	b.dialogs[key] = &dialogSession{d: d}
+	b.dialogsMu.Unlock()
+
+	d.start(ctx)
+}
+
+func (b *Bot) endDialog(key chatUser, session *dialogSession) {
+	b.dialogsMu.Lock()
+	if b.dialogs[key] == session {
+		delete(b.dialogs, key)
+	}
+	b.dialogsMu.Unlock()
+}
+
+// cancelDialog ends the user's dialog and reports whether one was active.
+func (b *Bot) cancelDialog(key chatUser) bool {
+	b.dialogsMu.Lock()
+	defer b.dialogsMu.Unlock()
+
+	if _, ok := b.dialogs[key]; !ok {
+		return false
+	}
+	delete(b.dialogs, key)
+	return true
+}
+
+func (b *Bot) activeDialog(key chatUser) *dialogSession {
+	b.dialogsMu.Lock()
+	defer b.dialogsMu.Unlock()
+	return b.dialogs[key]
+}
+
+// chatHasDialog reports whether any user has a dialog open in the chat.
+func (b *Bot) chatHasDialog(chatID int64) bool {
+	b.dialogsMu.Lock()
+	defer b.dialogsMu.Unlock()
+	for key := range b.dialogs {
+		if key.chatID == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+// routeDialogMessage passes a non-command message to the sender's active
+// dialog and reports whether it was consumed.
+func (b *Bot) routeDialogMessage(ctx context.Context, message *tgbotapi.Message) bool {
+	if message.IsCommand() || message.From == nil {
+		return false
+	}
+
+	key := chatUser{message.Chat.ID, message.From.ID}
+	session := b.activeDialog(key)
+	if session == nil {
+		return false
+	}
+
+	done := session.d.handleMessage(ctx, message)
+
+	if done {
+		b.endDialog(key, session)
+	}
+	return true
+}
+
+// handleDialogCallback passes a dialog button to the dialog of the user who
+// pressed it; buttons of other members' dialogs are rejected.
+func (b *Bot) handleDialogCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	chatID := query.Message.Chat.ID
+	key := chatUser{chatID, query.From.ID}
+
+	session := b.activeDialog(key)
+	if session == nil {
+		if b.chatHasDialog(chatID) {
+			b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "dialog.not_yours"))
+			return
+		}
+		b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "dialog.ended"))
+		return
+	}
+
+	done := session.d.handleCallback(ctx, query, strings.TrimPrefix(query.Data, callbackDialog))
+
+	if done {
+		b.endDialog(key, session)
+		logging.From(ctx).WithField("chat_id", chatID).Debug("Dialog finished")
+	}
+}
+
+// removeKeyboard strips the inline keyboard from a message after its button was used.
+func (b *Bot) removeKeyboard(ctx context.Context, chatID int64, messageID int) {
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	})
+	if err := b.sender.Request(ctx, chatID, edit); err != nil {
+		logging.From(ctx).WithError(err).WithField("chat_id", chatID).Debug("Failed to remove inline keyboard")
+	}
+}
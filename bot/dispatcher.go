@@ -0,0 +1,206 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// errQueueFull is returned by Submit when the chat or the whole bot is at its queue limit.
+var errQueueFull = errors.New("queue full")
+
+// errDispatcherClosed is returned by Submit after Close.
+var errDispatcherClosed = errors.New("dispatcher closed")
+
+// dispatcher runs update handlers on a fixed pool of workers. Tasks of one
+// chat run one at a time in arrival order, so a follow-up message sees the
+// history saved by the previous one; different chats run in parallel.
+type dispatcher struct {
+	size       int
+	maxPerChat int
+	maxTotal   int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queues  map[int64]*chatQueue
+	ready   []*chatQueue // chats with queued tasks and no worker
+	queued  int
+	active  int
+	closed  bool
+	workers sync.WaitGroup
+
+	processed uint64
+	rejected  uint64
+	peak      int
+}
+
+type chatQueue struct {
+	chatID  int64
+	tasks   []func()
+	running bool
+}
+
+// dispatcherStats is a snapshot of the dispatcher queues.
+type dispatcherStats struct {
+	Workers   int
+	Queued    int // accepted, not started
+	Active    int // running
+	Chats     int // chats with queued or running tasks
+	Peak      int // highest Queued seen
+	Processed uint64
+	Rejected  uint64
+}
+
+func newDispatcher(workers, maxPerChat, maxTotal int) *dispatcher {
+	d := &dispatcher{
+		size:       workers,
+		maxPerChat: maxPerChat,
+		maxTotal:   maxTotal,
+		queues:     make(map[int64]*chatQueue),
+	}
+	d.cond = sync.NewCond(&d.mu)
+
+	d.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer d.workers.Done()
+			d.work()
+		}()
+	}
+	return d
+}
+
+// Submit queues task behind the chat's earlier tasks.
+func (d *dispatcher) Submit(chatID int64, task func()) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return errDispatcherClosed
+	}
+
+	q := d.queues[chatID]
+	if d.queued >= d.maxTotal || (q != nil && len(q.tasks) >= d.maxPerChat) {
+		d.rejected++
+		return errQueueFull
+	}
+
+	if q == nil {
+		q = &chatQueue{chatID: chatID}
+		d.queues[chatID] = q
+	}
+	q.tasks = append(q.tasks, task)
+	d.queued++
+	if d.queued > d.peak {
+		d.peak = d.queued
+	}
+
+	if !q.running && len(q.tasks) == 1 {
+		d.ready = append(d.ready, q)
+		d.cond.Signal()
+	}
+	return nil
+}
+
+// work runs tasks until the dispatcher is closed and drained. A chat goes to
+// the back of the ready list after each task so busy chats do not starve others.
+func (d *dispatcher) work() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for {
+		for len(d.ready) == 0 && !d.closed {
+			d.cond.Wait()
+		}
+		if len(d.ready) == 0 {
+			return
+		}
+
+		q := d.ready[0]
+		d.ready = d.ready[1:]
+		task := q.tasks[0]
+		q.tasks = q.tasks[1:]
+		q.running = true
+		d.queued--
+		d.active++
+
+		d.mu.Unlock()
+		d.run(q.chatID, task)
+		d.mu.Lock()
+
+		d.active--
+		d.processed++
+		q.running = false
+		if len(q.tasks) > 0 {
+			d.ready = append(d.ready, q)
+			d.cond.Signal()
+		} else {
+			delete(d.queues, q.chatID)
+		}
+	}
+}
+
+// run executes task, keeping a panicking handler from taking down the worker.
+func (d *dispatcher) run(chatID int64, task func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.WithFields(logrus.Fields{
+				"chat_id": chatID,
+				"panic":   fmt.Sprint(r),
+			}).Error("💥 Update handler panicked")
+		}
+	}()
+	task()
+}
+
+// Close stops accepting tasks and waits until queued and running tasks finish.
+func (d *dispatcher) Close() {
+	d.mu.Lock()
+	d.closed = true
+	d.cond.Broadcast()
+	d.mu.Unlock()
+
+	d.workers.Wait()
+}
+
+func (d *dispatcher) Stats() dispatcherStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return dispatcherStats{
+		Workers:   d.size,
+		Queued:    d.queued,
+		Active:    d.active,
+		Chats:     len(d.queues),
+		Peak:      d.peak,
+		Processed: d.processed,
+		Rejected:  d.rejected,
+	}
+}
+
+// updateChatID returns the chat an update belongs to, used to order its handling.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+// updateUserID returns the user who sent an update, 0 if unknown.
+func updateUserID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
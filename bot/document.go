@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"factory_bot/document"
+	"factory_bot/i18n"
+	"factory_bot/logging"
+	"factory_bot/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// callbackDocument asks for an answer as a DOCX file.
+const callbackDocument = "doc:"
+
+// summarySections caps the section headings listed in a document summary.
+const summarySections = 8
+
+// needsSplit reports whether an answer takes more than one message.
+func needsSplit(text string) bool {
+	return markdown.UTF16Len(markdown.ToPlainText(text)) > maxMessageLength-partReserve
+}
+
+// sendsAsDocument reports whether an answer is long enough to be sent as a file.
+func (b *Bot) sendsAsDocument(text string) bool {
+	limit := b.config().AnswerDocumentLength
+	return limit > 0 && markdown.UTF16Len(markdown.ToPlainText(text)) > limit
+}
+
+func documentButtonRow(l i18n.Locale, answerID int64) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "document.button"), callbackDocument+strconv.FormatInt(answerID, 10)),
+	)
+}
+
+// sendAnswerDocument sends a long answer as a DOCX file followed by a short
+// summary carrying markup, both messages under header. If the file cannot be
+// built or sent the answer goes out as messages instead.
+func (b *Bot) sendAnswerDocument(ctx context.Context, chatID, answerID int64, header, text string, markup tgbotapi.InlineKeyboardMarkup) *tgbotapi.Message {
+	if err := b.sendDocument(ctx, chatID, answerID, text); err != nil {
+		return b.sendMessageWithMarkup(ctx, chatID, header+text, markup)
+	}
+	return b.sendMessageWithMarkup(ctx, chatID, header+answerSummary(b.locale(ctx, chatID), text), markup)
+}
+
+// sendDocument renders an answer as DOCX with the plant header and sends it.
+func (b *Bot) sendDocument(ctx context.Context, chatID, answerID int64, text string) error {
+	now := time.Now().In(b.config().Location)
+	l := b.locale(ctx, chatID)
+	title := answerTitle(l, text)
+
+	data, err := document.DOCX(document.Header{
+		Organization: b.config().PlantName,
+		Title:        title,
+		Subtitle:     i18n.T(l, "document.subtitle"),
+		Date:         now,
+		PageLabel:    i18n.T(l, "document.page"),
+	}, text)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("answer_id", answerID).Error("❌ Failed to render answer document")
+		return err
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("answer-%d-%s.docx", answerID, now.Format("20060102-1504")),
+		Bytes: data,
+	})
+	doc.Caption = "📄 " + title
+
+	if _, err := b.sender.Send(ctx, chatID, doc); err != nil {
+		logging.From(ctx).WithError(err).WithFields(logrus.Fields{
+			"chat_id":   chatID,
+			"answer_id": answerID,
+		}).Error("❌ Failed to send answer document")
+		return err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"chat_id":   chatID,
+		"answer_id": answerID,
+		"size":      len(data),
+	}).Info("📄 Answer sent as document")
+	return nil
+}
+
+func (b *Bot) handleDocumentCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	answerID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, callbackDocument), 10, 64)
+	if err != nil || query.Message == nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	chatID := query.Message.Chat.ID
+
+	answer, err := b.db.GetAnswer(ctx, answerID)
+	if err != nil || answer == nil || answer.ChatID != chatID {
+		b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "document.not_found"))
+		return
+	}
+
+	b.answerCallback(ctx, query.ID, "📄")
+	if err := b.sendDocument(ctx, chatID, answerID, answer.Text); err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "document.failed"))
+	}
+}
+
+// answerTitle names a document after the first heading of the answer, or its first line.
+func answerTitle(l i18n.Locale, text string) string {
+	var firstLine string
+	for _, blk := range markdown.Parse(text) {
+		switch blk.Kind {
+		case markdown.BlockHeading:
+			return truncateText(markdown.PlainInline(blk.Text), 80)
+		case markdown.BlockParagraph, markdown.BlockListItem:
+			if firstLine == "" {
+				firstLine = markdown.PlainInline(blk.Text)
+			}
+		}
+	}
+	if firstLine != "" {
+		return truncateText(firstLine, 60)
+	}
+	return i18n.T(l, "document.title")
+}
+
+// answerSummary is sent with the document: the opening paragraph and the
+// list of sections, so the reader knows what the file contains.
+func answerSummary(l i18n.Locale, text string) string {
+	var intro string
+	var sections []string
+	for _, blk := range markdown.Parse(text) {
+		switch blk.Kind {
+		case markdown.BlockParagraph:
+			if intro == "" {
+				intro = blk.Text
+			}
+		case markdown.BlockHeading:
+			if len(sections) < summarySections {
+				sections = append(sections, markdown.PlainInline(blk.Text))
+			}
+		}
+	}
+
+	var summary strings.Builder
+	summary.WriteString(i18n.T(l, "document.summary"))
+	if intro != "" {
+		summary.WriteString("\n\n" + truncateText(intro, 400))
+	}
+	if len(sections) > 0 {
+		summary.WriteString("\n\n" + i18n.T(l, "document.sections"))
+		for _, section := range sections {
+			summary.WriteString("\n• " + section)
+		}
+	}
+	return summary.String()
+}
@@ -0,0 +1,290 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"factory_bot/database"
+	"factory_bot/i18n"
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Callback data prefixes for answer feedback buttons
+const (
+	callbackRateUp   = "fb:up:"
+	callbackRateDown = "fb:down:"
+	callbackComment  = "fb:why:"
+)
+
+// feedbackReportDays is the default period covered by /feedback.
+const feedbackReportDays = 7
+
+// commentTimeout is how long the bot waits for a rating comment; a later
+// message is an ordinary question.
+const commentTimeout = 10 * time.Minute
+
+// sendAnswer records an AI answer for quality reporting and sends it under a
+// line naming the mode, with rating buttons below any extra button rows. Long
+// answers are sent as a document, or get a button to request one.
+func (b *Bot) sendAnswer(ctx context.Context, chatID int64, text string, info answerInfo, extraRows ...[]tgbotapi.InlineKeyboardButton) *tgbotapi.Message {
+	header := modeHeader(b.locale(ctx, chatID), info.Mode)
+
+	answerID, err := b.db.SaveAnswer(ctx, chatID, info.Model, info.PromptVersion, text)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("chat_id", chatID).Error("❌ Failed to record answer, sending without rating buttons")
+		if len(extraRows) > 0 {
+			return b.sendMessageWithMarkup(ctx, chatID, header+text, tgbotapi.NewInlineKeyboardMarkup(extraRows...))
+		}
+		return b.sendMessage(ctx, chatID, header+text)
+	}
+
+	markup := feedbackKeyboard(answerID)
+	markup.InlineKeyboard = append(extraRows, markup.InlineKeyboard...)
+
+	var sent *tgbotapi.Message
+	switch {
+	case b.sendsAsDocument(text):
+		sent = b.sendAnswerDocument(ctx, chatID, answerID, header, text, markup)
+	case needsSplit(text):
+		// Several messages are hard to read on a phone; offer a printable file
+		markup.InlineKeyboard = append(markup.InlineKeyboard, documentButtonRow(b.locale(ctx, chatID), answerID))
+		sent = b.sendMessageWithMarkup(ctx, chatID, header+text, markup)
+	default:
+		sent = b.sendMessageWithMarkup(ctx, chatID, header+text, markup)
+	}
+	if sent != nil {
+		b.db.SetAnswerTelegramMessageID(ctx, answerID, sent.MessageID)
+	}
+	return sent
+}
+
+func feedbackKeyboard(answerID int64) tgbotapi.InlineKeyboardMarkup {
+	id := strconv.FormatInt(answerID, 10)
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👍", callbackRateUp+id),
+			tgbotapi.NewInlineKeyboardButtonData("👎", callbackRateDown+id),
+		),
+	)
+}
+
+func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	logging.From(ctx).WithFields(logrus.Fields{
+		"user_id": query.From.ID,
+		"data":    query.Data,
+	}).Info("🔘 Incoming callback query")
+
+	switch {
+	case strings.HasPrefix(query.Data, "fb:"):
+		b.handleFeedbackCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackMode):
+		b.handleModeCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackModel):
+		b.handleModelCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackDialog):
+		b.handleDialogCallback(ctx, query)
+	case strings.HasPrefix(query.Data, "inc:"):
+		b.handleIncidentCallback(ctx, query)
+	case strings.HasPrefix(query.Data, "tk:"):
+		b.handleTicketCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackDocument):
+		b.handleDocumentCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackLanguage):
+		b.handleLanguageCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackPrompt):
+		b.handlePromptCallback(ctx, query)
+	default:
+		b.answerCallback(ctx, query.ID, "")
+		logging.From(ctx).WithField("data", query.Data).Warn("❓ Unknown callback data")
+	}
+}
+
+func (b *Bot) handleFeedbackCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	chatID := query.Message.Chat.ID
+	userID := query.From.ID
+
+	var (
+		prefix string
+		rating int
+	)
+	switch {
+	case strings.HasPrefix(query.Data, callbackRateUp):
+		prefix, rating = callbackRateUp, database.RatingUp
+	case strings.HasPrefix(query.Data, callbackRateDown):
+		prefix, rating = callbackRateDown, database.RatingDown
+	case strings.HasPrefix(query.Data, callbackComment):
+		b.requestFeedbackComment(ctx, query)
+		return
+	default:
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	answerID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, prefix), 10, 64)
+	if err != nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	ratingID, err := b.db.RateAnswer(ctx, answerID, userID, rating)
+	if err != nil {
+		b.answerCallback(ctx, query.ID, b.t(ctx, userID, "rating.save_failed"))
+		return
+	}
+
+	// Replace the rating buttons with the chosen rating and, for 👎, the optional follow-up
+	var markup tgbotapi.InlineKeyboardMarkup
+	if rating == database.RatingUp {
+		markup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, chatID, "rating.thanks_button"), callbackRateUp+strconv.FormatInt(answerID, 10)),
+		))
+		b.answerCallback(ctx, query.ID, b.t(ctx, userID, "rating.thanks"))
+	} else {
+		markup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, chatID, "rating.why_button"), callbackComment+strconv.FormatInt(ratingID, 10)),
+		))
+		b.answerCallback(ctx, query.ID, b.t(ctx, userID, "rating.noted"))
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, markup)
+	if err := b.sender.Request(ctx, chatID, edit); err != nil {
+		logging.From(ctx).WithError(err).WithField("chat_id", chatID).Warn("⚠️ Failed to update rating buttons")
+	}
+}
+
+// pendingComment is a rating awaiting its comment.
+type pendingComment struct {
+	ratingID int64
+	asked    time.Time
+}
+
+// requestFeedbackComment asks the user who rated the answer to describe the
+// problem; their next text message in the chat is stored as the rating comment.
+func (b *Bot) requestFeedbackComment(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	ratingID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, callbackComment), 10, 64)
+	if err != nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	chatID := query.Message.Chat.ID
+	userID := query.From.ID
+
+	raterID, err := b.db.GetRatingUserID(ctx, ratingID)
+	if err != nil {
+		b.answerCallback(ctx, query.ID, b.t(ctx, userID, "rating.save_failed"))
+		return
+	}
+	if raterID != userID {
+		b.answerCallback(ctx, query.ID, b.t(ctx, userID, "rating.not_yours"))
+		return
+	}
+
+	now := time.Now()
+	b.feedbackMu.Lock()
+	for key, pending := range b.pendingComments {
+		// Drop requests of users who never answered
+		if now.Sub(pending.asked) > commentTimeout {
+			delete(b.pendingComments, key)
+		}
+	}
+	b.pendingComments[chatUser{chatID, userID}] = pendingComment{ratingID: ratingID, asked: now}
+	b.feedbackMu.Unlock()
+
+	b.answerCallback(ctx, query.ID, "")
+	b.sendMessage(ctx, chatID, b.t(ctx, chatID, "rating.ask_comment"))
+
+	b.removeKeyboard(ctx, chatID, query.Message.MessageID)
+}
+
+// consumeFeedbackComment stores message as the sender's pending rating
+// comment if it comes within commentTimeout. It reports whether the message
+// was consumed. Any other message from the same user, such as a command,
+// cancels the pending comment; messages from other users leave it in place.
+func (b *Bot) consumeFeedbackComment(ctx context.Context, message *tgbotapi.Message) bool {
+	chatID := message.Chat.ID
+	key := chatUser{chatID, message.From.ID}
+
+	b.feedbackMu.Lock()
+	pending, ok := b.pendingComments[key]
+	delete(b.pendingComments, key)
+	b.feedbackMu.Unlock()
+
+	if !ok || time.Since(pending.asked) > commentTimeout || message.Text == "" || strings.HasPrefix(message.Text, "/") {
+		return false
+	}
+
+	if err := b.db.SetRatingComment(ctx, pending.ratingID, message.Text); err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "rating.comment_failed"))
+		return true
+	}
+
+	b.sendMessage(ctx, chatID, b.t(ctx, chatID, "rating.comment_saved"))
+	return true
+}
+
+// sendFeedbackReport sends admins a summary of ratings and the latest low-rated answers.
+func (b *Bot) sendFeedbackReport(ctx context.Context, chatID int64, days int) {
+	since := time.Now().AddDate(0, 0, -days)
+	loc := b.config().Location
+	l := b.locale(ctx, chatID)
+
+	summary, err := b.db.GetRatingSummary(ctx, since)
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(l, "report.failed"))
+		return
+	}
+	answers, err := b.db.GetLowRatedAnswers(ctx, since, 10)
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(l, "report.failed"))
+		return
+	}
+
+	var report strings.Builder
+	report.WriteString(i18n.T(l, "report.title", days) + "\n\n")
+
+	if len(summary) == 0 {
+		report.WriteString(i18n.T(l, "report.empty") + "\n")
+	}
+	for _, s := range summary {
+		report.WriteString(i18n.T(l, "report.model", s.Model, s.PromptVersion, s.Up, s.Down) + "\n")
+	}
+
+	if len(answers) > 0 {
+		report.WriteString("\n" + i18n.T(l, "report.low") + "\n")
+	}
+	for _, a := range answers {
+		fmt.Fprintf(&report, "\n#%d · %s · %s · v%s\n", a.ID, a.RatedAt.In(loc).Format("02.01 15:04"), a.Model, a.PromptVersion)
+		report.WriteString(i18n.T(l, "report.answer", truncateText(a.Text, 300)) + "\n")
+		if a.Comment != "" {
+			report.WriteString(i18n.T(l, "comment", a.Comment) + "\n")
+		}
+	}
+
+	// Answer excerpts contain arbitrary Markdown, so the report is sent as plain text
+	b.sendPlainMessage(ctx, chatID, report.String())
+}
+
+// truncateText shortens text to at most limit runes, adding an ellipsis.
+func truncateText(text string, limit int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+	return string(runes[:limit]) + "…"
+}
+
+func (b *Bot) answerCallback(ctx context.Context, callbackID, text string) {
+	if err := b.sender.Request(ctx, 0, tgbotapi.NewCallback(callbackID, text)); err != nil {
+		logging.From(ctx).WithError(err).Warn("⚠️ Failed to answer callback query")
+	}
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"factory_bot/tracing"
+)
+
+// downloadTimeout bounds a single file download from Telegram.
+const downloadTimeout = 30 * time.Second
+
+// maxImageSize limits photos downloaded for the model.
+const maxImageSize = 10 << 20
+
+var errFileTooLarge = errors.New("file too large")
+
+// downloadFile fetches a file sent to the bot, reading at most limit bytes.
+// Errors never contain the download URL, which carries the bot token.
+func (b *Bot) downloadFile(ctx context.Context, fileID string, limit int64) (data []byte, err error) {
+	ctx, span := tracing.StartKind(ctx, tracing.KindClient, "telegram download")
+	defer func() {
+		span.SetAttributes(tracing.Int("file.size", len(data)))
+		span.RecordError(err)
+		span.End()
+	}()
+
+	fileURL, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build download request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: %s", resp.Status)
+	}
+
+	data, err = io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, errFileTooLarge
+	}
+	return data, nil
+}
+
+// imageDataURL downloads a photo and returns it as a base64 data URL, so the
+// model provider never sees a Telegram file link with the bot token in it.
+func (b *Bot) imageDataURL(ctx context.Context, fileID string) (string, error) {
+	data, err := b.downloadFile(ctx, fileID, maxImageSize)
+	if err != nil {
+		return "", err
+	}
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("unsupported image type %s", mimeType)
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
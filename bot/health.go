@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"factory_bot/config"
+)
+
+// healthCheckTimeout bounds each readiness check.
+const healthCheckTimeout = 2 * time.Second
+
+// Check statuses in /readyz responses.
+const (
+	checkOK      = "ok"
+	checkFail    = "fail"
+	checkSkipped = "skipped"
+)
+
+type checkResult struct {
+	Status    string `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	LatencyMS int64  `json:"latency_ms,omitempty"`
+}
+
+type healthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// markPolled records a successful getUpdates for the readiness check.
+func (b *Bot) markPolled() {
+	b.lastPoll.Store(time.Now().UnixNano())
+}
+
+// readiness runs the readiness checks: the database answers, updates are
+// being received, and the AI circuit is closed.
+func (b *Bot) readiness(ctx context.Context) healthReport {
+	report := healthReport{Status: checkOK, Checks: make(map[string]checkResult, 3)}
+	add := func(name string, result checkResult) {
+		report.Checks[name] = result
+		if result.Status == checkFail {
+			report.Status = checkFail
+		}
+	}
+
+	// Database
+	start := time.Now()
+	dbCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	err := b.db.Ping(dbCtx)
+	cancel()
+	result := checkResult{Status: checkOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status, result.Detail = checkFail, err.Error()
+	}
+	add("database", result)
+
+	// Telegram updates; webhook mode has no heartbeat, updates only arrive when users write
+	cfg := b.config()
+	if cfg.Mode == config.ModeWebhook {
+		add("updates", checkResult{Status: checkSkipped, Detail: "webhook mode"})
+	} else {
+		result := checkResult{Status: checkOK}
+		if last := b.lastPoll.Load(); last == 0 {
+			result.Status, result.Detail = checkFail, "not polling"
+		} else {
+			age := time.Since(time.Unix(0, last)).Truncate(time.Second)
+			result.Detail = fmt.Sprintf("last getUpdates %s ago", age)
+			if age > cfg.UpdatesThreshold {
+				result.Status = checkFail
+			}
+		}
+		add("updates", result)
+	}
+
+	// AI provider
+	if state := b.aiProvider.CircuitState(); state == "closed" {
+		add("ai", checkResult{Status: checkOK, Detail: "circuit closed"})
+	} else {
+		add("ai", checkResult{Status: checkFail, Detail: "circuit " + state})
+	}
+
+	return report
+}
+
+// healthzHandler reports that the process is alive and serving HTTP.
+func healthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, healthReport{Status: checkOK})
+	})
+}
+
+// readyzHandler reports whether the bot can serve users: 200 when all checks
+// pass, 503 otherwise.
+func (b *Bot) readyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, b.readiness(r.Context()))
+	})
+}
+
+func writeHealth(w http.ResponseWriter, report healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != checkOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
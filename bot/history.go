@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+
+	"factory_bot/database"
+	"factory_bot/logging"
+	"factory_bot/tracing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultImagePrompt is used when a photo arrives without a caption.
+const defaultImagePrompt = "Проанализируй это изображение для производства / Analyze this image for factory operations"
+
+// historyMessages converts stored chat history into model messages. Image turns
+// become multimodal user messages; only the newest maxImages of them carry the
+// actual picture, older ones fall back to a text placeholder so the prompt
+// stays within the provider's image limits. The second return value reports
+// whether any image part was included.
+func (b *Bot) historyMessages(ctx context.Context, history []database.Message, maxImages int) ([]openai.ChatCompletionMessage, bool) {
+	ctx, span := tracing.Start(ctx, "history.build", tracing.Int("history.messages", len(history)))
+	defer span.End()
+
+	// Walk from newest to oldest to decide which images are re-sent
+	withImage := make(map[int]bool)
+	remaining := maxImages
+	for i := len(history) - 1; i >= 0 && remaining > 0; i-- {
+		if history[i].ImageFileID != "" {
+			withImage[i] = true
+			remaining--
+		}
+	}
+
+	var messages []openai.ChatCompletionMessage
+	hasImages := false
+
+	for i, msg := range history {
+		switch msg.Role {
+		case "user":
+			if msg.ImageFileID == "" {
+				messages = append(messages, openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleUser,
+					Content: msg.Text,
+				})
+				continue
+			}
+
+			if withImage[i] {
+				imageURL, err := b.imageDataURL(ctx, msg.ImageFileID)
+				if err == nil {
+					messages = append(messages, imageMessage(msg.Text, imageURL, b.config().ImageDetail))
+					hasImages = true
+					continue
+				}
+				logging.From(ctx).WithError(err).WithFields(logrus.Fields{
+					"user_id":    msg.UserID,
+					"message_id": msg.ID,
+				}).Warn("⚠️ Failed to download history image, sending placeholder")
+			}
+
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: imagePlaceholder(msg.Text),
+			})
+		case "assistant":
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: msg.Text,
+			})
+		}
+	}
+
+	span.SetAttributes(tracing.Bool("history.images", hasImages))
+	return messages, hasImages
+}
+
+// imageMessage builds a user message carrying a caption and an image part
+// sent at the given detail level ("low", "high" or "auto").
+func imageMessage(caption, imageURL, detail string) openai.ChatCompletionMessage {
+	if caption == "" {
+		caption = defaultImagePrompt
+	}
+
+	return openai.ChatCompletionMessage{
+		Role: openai.ChatMessageRoleUser,
+		MultiContent: []openai.ChatMessagePart{
+			{
+				Type: openai.ChatMessagePartTypeText,
+				Text: caption,
+			},
+			{
+				Type: openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{
+					URL:    imageURL,
+					Detail: openai.ImageURLDetail(detail),
+				},
+			},
+		},
+	}
+}
+
+// imagePlaceholder describes an image turn that is no longer re-sent.
+func imagePlaceholder(caption string) string {
+	if caption != "" {
+		return "[Изображение] " + caption
+	}
+	return "[Изображение без описания]"
+}
@@ -0,0 +1,428 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"factory_bot/database"
+	"factory_bot/i18n"
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// callbackIncidentStatus prefixes status buttons in the safety officers' chat: "inc:st:<id>:<status>".
+const callbackIncidentStatus = "inc:st:"
+
+// maxIncidentPhotos limits the photos attached to one report (one media group).
+const maxIncidentPhotos = 10
+
+type choice struct {
+	Key   string
+	Label string // catalog key
+}
+
+var incidentCategories = []choice{
+	{"injury", "incident.category.injury"},
+	{"near_miss", "incident.category.near_miss"},
+	{"equipment", "incident.category.equipment"},
+	{"fire", "incident.category.fire"},
+	{"chemical", "incident.category.chemical"},
+	{"electrical", "incident.category.electrical"},
+	{"other", "incident.category.other"},
+}
+
+var incidentSeverities = []choice{
+	{"low", "incident.severity.low"},
+	{"medium", "incident.severity.medium"},
+	{"high", "incident.severity.high"},
+	{"critical", "incident.severity.critical"},
+}
+
+var incidentStatuses = []choice{
+	{database.IncidentNew, "incident.status.new"},
+	{database.IncidentInProgress, "incident.status.in_progress"},
+	{database.IncidentResolved, "incident.status.resolved"},
+	{database.IncidentRejected, "incident.status.rejected"},
+}
+
+func choiceLabel(l i18n.Locale, choices []choice, key string) string {
+	for _, c := range choices {
+		if c.Key == key {
+			return i18n.T(l, c.Label)
+		}
+	}
+	return key
+}
+
+func isChoice(choices []choice, key string) bool {
+	for _, c := range choices {
+		if c.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// choiceKeyboard lays out one button per row with callback data prefix+key.
+func choiceKeyboard(l i18n.Locale, choices []choice, prefix string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, c := range choices {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, c.Label), prefix+c.Key)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// photoButtons finishes the photo step of a dialog; skipping and finishing do the same.
+func photoButtons(l i18n.Locale) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "button.done"), callbackDialog+"photos_done"),
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "button.skip"), callbackDialog+"photos_done"),
+	))
+}
+
+// Incident dialog steps
+const (
+	incidentStepCategory = iota
+	incidentStepLocation
+	incidentStepDescription
+	incidentStepPhotos
+	incidentStepSeverity
+)
+
+// incidentDialog guides a worker through reporting a safety hazard.
+type incidentDialog struct {
+	b        *Bot
+	chatID   int64
+	step     int
+	incident database.Incident
+}
+
+func newIncidentDialog(b *Bot, message *tgbotapi.Message) *incidentDialog {
+	return &incidentDialog{
+		b:      b,
+		chatID: message.Chat.ID,
+		incident: database.Incident{
+			ReporterID:   message.From.ID,
+			ReporterName: displayName(message.From),
+			ChatID:       message.Chat.ID,
+		},
+	}
+}
+
+func (d *incidentDialog) start(ctx context.Context) {
+	d.step = incidentStepCategory
+	d.b.sendMessageWithMarkup(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.start"),
+		choiceKeyboard(d.b.locale(ctx, d.chatID), incidentCategories, callbackDialog+"cat:"))
+}
+
+func (d *incidentDialog) handleMessage(ctx context.Context, message *tgbotapi.Message) bool {
+	switch d.step {
+	case incidentStepCategory:
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.choose_category"))
+
+	case incidentStepLocation:
+		if strings.TrimSpace(message.Text) == "" {
+			d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.type_location"))
+			return false
+		}
+		d.incident.Location = strings.TrimSpace(message.Text)
+		d.step = incidentStepDescription
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.step_description"))
+
+	case incidentStepDescription:
+		text := strings.TrimSpace(message.Text)
+		if text == "" {
+			text = strings.TrimSpace(message.Caption)
+		}
+		if text == "" {
+			d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.describe"))
+			return false
+		}
+		d.incident.Description = text
+		if len(message.Photo) > 0 {
+			d.addPhoto(ctx, message)
+		}
+		d.step = incidentStepPhotos
+		d.b.sendMessageWithMarkup(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.step_photos"), photoButtons(d.b.locale(ctx, d.chatID)))
+
+	case incidentStepPhotos:
+		if len(message.Photo) == 0 {
+			d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "photos.send_or_done"))
+			return false
+		}
+		d.addPhoto(ctx, message)
+
+	case incidentStepSeverity:
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.choose_severity"))
+	}
+
+	return false
+}
+
+func (d *incidentDialog) addPhoto(ctx context.Context, message *tgbotapi.Message) {
+	if len(d.incident.PhotoFileIDs) >= maxIncidentPhotos {
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "photos.limit", maxIncidentPhotos))
+		return
+	}
+	d.incident.PhotoFileIDs = append(d.incident.PhotoFileIDs, message.Photo[len(message.Photo)-1].FileID)
+	d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "photos.added", len(d.incident.PhotoFileIDs)))
+}
+
+func (d *incidentDialog) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery, data string) bool {
+	switch {
+	case d.step == incidentStepCategory && strings.HasPrefix(data, "cat:"):
+		key := strings.TrimPrefix(data, "cat:")
+		if !isChoice(incidentCategories, key) {
+			d.b.answerCallback(ctx, query.ID, "")
+			return false
+		}
+		d.incident.Category = key
+		d.b.answerCallback(ctx, query.ID, "")
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		d.step = incidentStepLocation
+		l := d.b.locale(ctx, d.chatID)
+		d.b.sendMessage(ctx, d.chatID, i18n.T(l, "incident.category_chosen", choiceLabel(l, incidentCategories, key)))
+
+	case d.step == incidentStepPhotos && data == "photos_done":
+		d.b.answerCallback(ctx, query.ID, "")
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		d.step = incidentStepSeverity
+		d.b.sendMessageWithMarkup(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.step_severity"),
+			choiceKeyboard(d.b.locale(ctx, d.chatID), incidentSeverities, callbackDialog+"sev:"))
+
+	case d.step == incidentStepSeverity && strings.HasPrefix(data, "sev:"):
+		key := strings.TrimPrefix(data, "sev:")
+		if !isChoice(incidentSeverities, key) {
+			d.b.answerCallback(ctx, query.ID, "")
+			return false
+		}
+		d.incident.Severity = key
+		d.b.answerCallback(ctx, query.ID, "")
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		d.submit(ctx)
+		return true
+
+	default:
+		d.b.answerCallback(ctx, query.ID, "")
+	}
+
+	return false
+}
+
+func (d *incidentDialog) submit(ctx context.Context) {
+	if err := d.b.db.CreateIncident(ctx, &d.incident); err != nil {
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.save_failed"))
+		return
+	}
+
+	d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "incident.registered", d.incident.TrackingNumber()))
+
+	d.b.forwardIncident(ctx, &d.incident)
+}
+
+// forwardIncident posts the report with its photos and status buttons to the safety officers' chat.
+func (b *Bot) forwardIncident(ctx context.Context, inc *database.Incident) {
+	if b.config().SafetyChatID == 0 {
+		logging.From(ctx).WithField("incident_id", inc.ID).Warn("⚠️ SAFETY_CHAT_ID not set, incident not forwarded")
+		return
+	}
+	chatID := b.config().SafetyChatID
+
+	if len(inc.PhotoFileIDs) > 0 {
+		var media []interface{}
+		for _, fileID := range inc.PhotoFileIDs {
+			media = append(media, tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(fileID)))
+		}
+		if _, err := b.sender.SendMediaGroup(ctx, chatID, tgbotapi.NewMediaGroup(chatID, media)); err != nil {
+			logging.From(ctx).WithError(err).WithField("incident_id", inc.ID).Error("❌ Failed to forward incident photos")
+		}
+	}
+
+	l := b.locale(ctx, chatID)
+	b.sendPlainMessageWithMarkup(ctx, chatID, formatIncident(l, inc), incidentStatusKeyboard(l, inc.ID))
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"incident_id": inc.ID,
+		"chat_id":     chatID,
+	}).Info("🚨 Incident forwarded to safety officers")
+}
+
+func formatIncident(l i18n.Locale, inc *database.Incident) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "🚨 %s\n\n", inc.TrackingNumber())
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.category"), choiceLabel(l, incidentCategories, inc.Category))
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.severity"), choiceLabel(l, incidentSeverities, inc.Severity))
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.location"), inc.Location)
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.reporter"), inc.ReporterName)
+	if len(inc.PhotoFileIDs) > 0 {
+		fmt.Fprintf(&text, "%s: %d\n", i18n.T(l, "field.photos"), len(inc.PhotoFileIDs))
+	}
+	fmt.Fprintf(&text, "%s: %s\n\n", i18n.T(l, "field.status"), choiceLabel(l, incidentStatuses, inc.Status))
+	text.WriteString(inc.Description)
+	return text.String()
+}
+
+func incidentStatusKeyboard(l i18n.Locale, incidentID int64) tgbotapi.InlineKeyboardMarkup {
+	id := strconv.FormatInt(incidentID, 10)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, s := range incidentStatuses[1:] {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, s.Label), callbackIncidentStatus+id+":"+s.Key),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// canManageIncidents reports whether the user may change incident statuses from this chat.
+func (b *Bot) canManageIncidents(chatID, userID int64) bool {
+	return b.config().IsAdmin(userID) || (b.config().SafetyChatID != 0 && chatID == b.config().SafetyChatID)
+}
+
+func (b *Bot) handleIncidentCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil || !strings.HasPrefix(query.Data, callbackIncidentStatus) {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	if !b.canManageIncidents(query.Message.Chat.ID, query.From.ID) {
+		b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "not_allowed"))
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(query.Data, callbackIncidentStatus), ":", 2)
+	if len(parts) != 2 || !isChoice(incidentStatuses, parts[1]) {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	inc, err := b.setIncidentStatus(ctx, id, parts[1], "", query.From.ID)
+	if err != nil || inc == nil {
+		b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "error.status_update"))
+		return
+	}
+	b.answerCallback(ctx, query.ID, choiceLabel(b.locale(ctx, query.From.ID), incidentStatuses, inc.Status))
+
+	// Refresh the forwarded report so the chat shows the current status
+	l := b.locale(ctx, query.Message.Chat.ID)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, formatIncident(l, inc), incidentStatusKeyboard(l, inc.ID))
+	if err := b.sender.Request(ctx, query.Message.Chat.ID, edit); err != nil {
+		logging.From(ctx).WithError(err).WithField("incident_id", id).Debug("Failed to refresh incident message")
+	}
+}
+
+// setIncidentStatus updates the status and notifies the reporter. It returns
+// nil without error if the incident does not exist.
+func (b *Bot) setIncidentStatus(ctx context.Context, id int64, status, note string, authorID int64) (*database.Incident, error) {
+	inc, err := b.db.GetIncident(ctx, id)
+	if err != nil || inc == nil {
+		return nil, err
+	}
+
+	if err := b.db.UpdateIncidentStatus(ctx, id, status, note, authorID); err != nil {
+		return nil, err
+	}
+	inc.Status = status
+
+	l := b.locale(ctx, inc.ChatID)
+	notice := i18n.T(l, "incident.status_changed", inc.TrackingNumber(), choiceLabel(l, incidentStatuses, status))
+	if note != "" {
+		notice += "\n" + i18n.T(l, "comment", note)
+	}
+	b.sendPlainMessage(ctx, inc.ChatID, notice)
+
+	return inc, nil
+}
+
+func (b *Bot) cmdIncident(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+
+	if len(args) == 0 {
+		if !message.Chat.IsPrivate() {
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "incident.private_only"))
+			return
+		}
+		b.startDialog(ctx, chatUser{chatID, message.From.ID}, newIncidentDialog(b, message))
+		logging.From(ctx).WithField("user_id", message.From.ID).Info("🚨 Incident report started")
+		return
+	}
+
+	id, ok := database.ParseIncidentNumber(args[0])
+	if !ok {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "usage", b.commands["incident"].synopsis(b.locale(ctx, chatID))))
+		return
+	}
+
+	inc, err := b.db.GetIncident(ctx, id)
+	if err != nil {
+		b.replyError(ctx, chatID, "error.loading", err)
+		return
+	}
+	if inc == nil || (inc.ReporterID != message.From.ID && !b.canManageIncidents(chatID, message.From.ID)) {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "incident.not_found"))
+		return
+	}
+
+	updates, err := b.db.GetIncidentUpdates(ctx, id)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("incident_id", id).Warn("⚠️ Failed to load incident history")
+	}
+
+	l := b.locale(ctx, chatID)
+	var text strings.Builder
+	text.WriteString(formatIncident(l, inc))
+	if len(updates) > 0 {
+		text.WriteString("\n\n" + i18n.T(l, "history") + "\n")
+	}
+	for _, u := range updates {
+		fmt.Fprintf(&text, "• %s — %s", u.CreatedAt.Format("02.01.2006 15:04"), choiceLabel(l, incidentStatuses, u.Status))
+		if u.Note != "" {
+			fmt.Fprintf(&text, " (%s)", u.Note)
+		}
+		text.WriteString("\n")
+	}
+
+	b.sendPlainMessage(ctx, chatID, text.String())
+}
+
+func (b *Bot) cmdIncidentStatus(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+
+	id, ok := database.ParseIncidentNumber(args[0])
+	if !ok || !isChoice(incidentStatuses, args[1]) {
+		keys := make([]string, len(incidentStatuses))
+		for i, s := range incidentStatuses {
+			keys[i] = s.Key
+		}
+		l := b.locale(ctx, chatID)
+		b.sendMessage(ctx, chatID, i18n.T(l, "usage", b.commands["incidentstatus"].synopsis(l))+"\n"+i18n.T(l, "incident.statuses", strings.Join(keys, "|")))
+		return
+	}
+
+	inc, err := b.setIncidentStatus(ctx, id, args[1], strings.Join(args[2:], " "), message.From.ID)
+	if err != nil {
+		b.replyError(ctx, chatID, "error.status_update", err)
+		return
+	}
+	if inc == nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "incident.not_found"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, fmt.Sprintf("✅ %s: %s", inc.TrackingNumber(), choiceLabel(b.locale(ctx, chatID), incidentStatuses, inc.Status)))
+}
+
+func (b *Bot) cmdCancel(ctx context.Context, message *tgbotapi.Message, args []string) {
+	if b.cancelDialog(chatUser{message.Chat.ID, message.From.ID}) {
+		b.sendMessage(ctx, message.Chat.ID, b.t(ctx, message.Chat.ID, "cancelled"))
+		return
+	}
+	b.sendMessage(ctx, message.Chat.ID, b.t(ctx, message.Chat.ID, "cancel.nothing"))
+}
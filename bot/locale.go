@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"factory_bot/i18n"
+	"factory_bot/instructions"
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// callbackLanguage prefixes /lang buttons: "lang:<code>" or "lang:auto".
+const callbackLanguage = "lang:"
+
+// languageAuto restores detection from the Telegram client language.
+const languageAuto = "auto"
+
+// locale returns the language for messages to chatID. Private chats use the
+// user's /lang choice or their Telegram language; group chats use the default.
+func (b *Bot) locale(ctx context.Context, chatID int64) i18n.Locale {
+	if chatID <= 0 {
+		return b.config().DefaultLanguage
+	}
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil || user == nil {
+		return b.config().DefaultLanguage
+	}
+	if l, ok := i18n.Parse(user.Language); ok {
+		return l
+	}
+	return i18n.Detect(user.LanguageCode, b.config().DefaultLanguage)
+}
+
+// t returns the message for key in the language of chatID.
+func (b *Bot) t(ctx context.Context, chatID int64, key string, args ...interface{}) string {
+	return i18n.T(b.locale(ctx, chatID), key, args...)
+}
+
+// languageInstruction is the system prompt part asking the model to answer
+// in the chat's language.
+func (b *Bot) languageInstruction(ctx context.Context, chatID int64) string {
+	return instructions.LanguageInstruction(b.locale(ctx, chatID).PromptName())
+}
+
+func (b *Bot) cmdLang(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	userID := message.From.ID
+
+	if len(args) == 0 {
+		b.sendMessageWithMarkup(ctx, chatID, b.t(ctx, userID, "lang.choose", b.locale(ctx, userID).Name()), b.languageKeyboard(ctx, userID))
+		return
+	}
+
+	value := strings.ToLower(args[0])
+	if _, ok := i18n.Parse(value); !ok && value != languageAuto {
+		b.sendMessage(ctx, chatID, b.t(ctx, userID, "usage", b.commands["lang"].synopsis(b.locale(ctx, userID))))
+		return
+	}
+	b.sendMessage(ctx, chatID, b.setLanguage(ctx, userID, value))
+}
+
+func (b *Bot) languageKeyboard(ctx context.Context, userID int64) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, l := range i18n.Locales {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(l.Name(), callbackLanguage+string(l)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, userID, "lang.auto"), callbackLanguage+languageAuto),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (b *Bot) handleLanguageCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	value := strings.TrimPrefix(query.Data, callbackLanguage)
+	if _, ok := i18n.Parse(value); (!ok && value != languageAuto) || query.Message == nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	reply := b.setLanguage(ctx, query.From.ID, value)
+	b.answerCallback(ctx, query.ID, "")
+	b.removeKeyboard(ctx, query.Message.Chat.ID, query.Message.MessageID)
+	b.sendMessage(ctx, query.Message.Chat.ID, reply)
+}
+
+// setLanguage stores the user's choice ("auto" clears it) and returns the
+// confirmation in the new language.
+func (b *Bot) setLanguage(ctx context.Context, userID int64, value string) string {
+	language := value
+	if value == languageAuto {
+		language = ""
+	}
+	if err := b.db.SetUserLanguage(ctx, userID, language); err != nil {
+		return b.t(ctx, userID, "lang.failed")
+	}
+
+	l := b.locale(ctx, userID)
+	logging.From(ctx).WithFields(logrus.Fields{
+		"user_id":  userID,
+		"language": value,
+		"locale":   string(l),
+	}).Info("🌐 User language changed")
+
+	if value == languageAuto {
+		return i18n.T(l, "lang.auto_set", l.Name())
+	}
+	return i18n.T(l, "lang.set", l.Name())
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"factory_bot/metrics"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// metricsShutdownTimeout bounds waiting for scrapes and health checks in progress on shutdown.
+const metricsShutdownTimeout = 5 * time.Second
+
+var (
+	updatesReceived = metrics.NewCounter("factory_bot_updates_total",
+		"Telegram updates received by type.", "type")
+	commandsHandled = metrics.NewCounter("factory_bot_commands_total",
+		"Bot commands run, by command.", "command")
+	sendFailures = metrics.NewCounter("factory_bot_telegram_send_failures_total",
+		"Telegram requests that failed after retries, by error class.", "class")
+	messageParts = metrics.NewHistogram("factory_bot_message_parts",
+		"Number of Telegram messages a text was split into.", []float64{1, 2, 3, 4, 6, 10})
+)
+
+// updateType names an update for factory_bot_updates_total.
+func updateType(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		return "command"
+	case update.Message != nil && update.Message.Photo != nil:
+		return "photo"
+	case update.Message != nil && update.Message.Document != nil:
+		return "document"
+	case update.Message != nil:
+		return "message"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	}
+	return "other"
+}
+
+// registerDispatcherMetrics exposes the dispatcher queue.
+func (b *Bot) registerDispatcherMetrics() {
+	metrics.NewGaugeFunc("factory_bot_dispatcher_queued",
+		"Updates waiting for a worker.", func() float64 { return float64(b.dispatcher.Stats().Queued) })
+	metrics.NewGaugeFunc("factory_bot_dispatcher_active",
+		"Updates being handled.", func() float64 { return float64(b.dispatcher.Stats().Active) })
+	metrics.NewGaugeFunc("factory_bot_dispatcher_chats",
+		"Chats with queued or running updates.", func() float64 { return float64(b.dispatcher.Stats().Chats) })
+	metrics.NewCounterFunc("factory_bot_dispatcher_rejected_total",
+		"Updates rejected because a queue was full.", func() float64 { return float64(b.dispatcher.Stats().Rejected) })
+}
+
+// serveMonitoring serves /metrics, /healthz and /readyz on the configured
+// port until ctx is cancelled. A server failure is logged and does not stop
+// the bot.
+func (b *Bot) serveMonitoring(ctx context.Context) {
+	port := b.config().MetricsPort
+	if port == 0 {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", healthzHandler())
+	mux.Handle("/readyz", b.readyzHandler())
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	logrus.WithField("port", port).Info("📈 Monitoring server listening")
+
+	select {
+	case err := <-serveErr:
+		logrus.WithError(err).Error("❌ Monitoring server failed")
+		return
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Warn("⚠️ Monitoring server did not shut down cleanly")
+	}
+}
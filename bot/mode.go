@@ -0,0 +1,243 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"factory_bot/config"
+	"factory_bot/database"
+	"factory_bot/i18n"
+	"factory_bot/logging"
+	"factory_bot/tracing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// callbackMode prefixes /mode buttons: "mode:<key>" or "mode:auto".
+const callbackMode = "mode:"
+
+// modeAuto restores the department default.
+const modeAuto = "auto"
+
+// chatMode returns the assistant mode for chatID: the user's /mode choice,
+// else the mode of their department, else the configured default. Group
+// chats always use the default.
+func (b *Bot) chatMode(ctx context.Context, cfg *config.Config, chatID int64) config.Mode {
+	if chatID > 0 {
+		if user, err := b.db.GetUser(ctx, chatID); err == nil && user != nil {
+			if m, ok := cfg.FindMode(user.Mode); ok {
+				return m
+			}
+			if m, ok := cfg.DepartmentMode(user.Department); ok {
+				return m
+			}
+		}
+	}
+	m, _ := cfg.FindMode(cfg.DefaultMode)
+	return m
+}
+
+// modeName is the mode's name for users: the configured name, or the catalog
+// entry of a built-in mode.
+func modeName(l i18n.Locale, m config.Mode) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return i18n.T(l, "mode."+m.Key)
+}
+
+// modeHeader is the line above answers showing the mode they were given in.
+func modeHeader(l i18n.Locale, m config.Mode) string {
+	return "_" + modeName(l, m) + "_\n\n"
+}
+
+// modeModels returns the text and vision models of a mode, falling back to
+// the configured ones.
+func modeModels(cfg *config.Config, m config.Mode) (text, vision string) {
+	text, vision = cfg.TextModel, cfg.VisionModel
+	if m.Model != "" {
+		text = m.Model
+	}
+	if m.VisionModel != "" {
+		vision = m.VisionModel
+	}
+	return text, vision
+}
+
+// systemPrompt renders the system prompt for a mode from the active prompt
+// versions and returns it with the version label stored with answers. The
+// image instruction is included when the prompt carries pictures.
+func (b *Bot) systemPrompt(ctx context.Context, cfg *config.Config, chatID int64, m config.Mode, images bool) (string, string) {
+	ctx, span := tracing.Start(ctx, "prompt.build", tracing.String("mode", m.Key), tracing.Bool("prompt.images", images))
+	defer span.End()
+
+	vars := b.promptContext(ctx, cfg, chatID)
+
+	main := b.prompt(promptMain)
+	used := []database.Prompt{main}
+	parts := []string{renderPrompt(main.Name, main.Text, vars)}
+	if m.Prompt != "" {
+		parts = append(parts, renderPrompt("mode "+m.Key, m.Prompt, vars))
+	}
+	if images {
+		p := b.prompt(promptImage)
+		used = append(used, p)
+		parts = append(parts, renderPrompt(p.Name, p.Text, vars))
+	}
+	if m.Allows(config.ToolTickets) {
+		p := b.prompt(promptTicketSuggestion)
+		used = append(used, p)
+		parts = append(parts, renderPrompt(p.Name, p.Text, vars))
+	}
+	parts = append(parts, b.languageInstruction(ctx, chatID))
+	version := promptVersionLabel(used...)
+	span.SetAttributes(tracing.String("prompt.version", version))
+	return strings.Join(parts, "\n\n"), version
+}
+
+func (b *Bot) cmdMode(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	userID := message.From.ID
+	cfg := b.config()
+	l := b.locale(ctx, userID)
+
+	if len(args) == 0 {
+		department := i18n.T(l, "mode.no_department")
+		if user, err := b.db.GetUser(ctx, userID); err == nil && user != nil && user.Department != "" {
+			department = user.Department
+		}
+		text := i18n.T(l, "mode.current", modeName(l, b.chatMode(ctx, cfg, userID)), department)
+		b.sendMessageWithMarkup(ctx, chatID, text, b.modeKeyboard(cfg, l))
+		return
+	}
+
+	value := strings.ToLower(args[0])
+	if _, ok := cfg.FindMode(value); !ok && value != modeAuto {
+		var keys []string
+		for _, m := range cfg.Modes {
+			keys = append(keys, m.Key)
+		}
+		b.sendMessage(ctx, chatID, i18n.T(l, "mode.unknown", strings.Join(keys, ", ")))
+		return
+	}
+	b.sendMessage(ctx, chatID, b.setMode(ctx, userID, value))
+}
+
+func (b *Bot) modeKeyboard(cfg *config.Config, l i18n.Locale) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, m := range cfg.Modes {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(modeName(l, m), callbackMode+m.Key))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "mode.auto"), callbackMode+modeAuto),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (b *Bot) handleModeCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	value := strings.TrimPrefix(query.Data, callbackMode)
+	if _, ok := b.config().FindMode(value); (!ok && value != modeAuto) || query.Message == nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	reply := b.setMode(ctx, query.From.ID, value)
+	b.answerCallback(ctx, query.ID, "")
+	b.removeKeyboard(ctx, query.Message.Chat.ID, query.Message.MessageID)
+	b.sendMessage(ctx, query.Message.Chat.ID, reply)
+}
+
+// setMode stores the user's choice ("auto" clears it) and returns the
+// confirmation.
+func (b *Bot) setMode(ctx context.Context, userID int64, value string) string {
+	l := b.locale(ctx, userID)
+	mode := value
+	if value == modeAuto {
+		mode = ""
+	}
+	if err := b.db.SetUserMode(ctx, userID, mode); err != nil {
+		return i18n.T(l, "mode.failed")
+	}
+
+	m := b.chatMode(ctx, b.config(), userID)
+	logging.From(ctx).WithFields(logrus.Fields{
+		"user_id": userID,
+		"choice":  value,
+		"mode":    m.Key,
+	}).Info("🎛 User mode changed")
+
+	if value == modeAuto {
+		return i18n.T(l, "mode.auto_set", modeName(l, m))
+	}
+	return i18n.T(l, "mode.set", modeName(l, m))
+}
+
+// cmdDepartment sets the department of a user, which decides their default
+// mode. Without a department it is cleared.
+func (b *Bot) cmdDepartment(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+
+	user, ok := b.lookupUser(ctx, chatID, args[0])
+	if !ok {
+		return
+	}
+
+	department := strings.ToLower(strings.Join(args[1:], " "))
+	if err := b.db.SetUserDepartment(ctx, user.ID, department); err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "department.failed"))
+		return
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"user_id":    user.ID,
+		"department": department,
+		"admin_id":   message.From.ID,
+	}).Info("🏢 User department changed")
+
+	if department == "" {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "department.cleared", userName(user)))
+		return
+	}
+	l := b.locale(ctx, chatID)
+	b.sendMessage(ctx, chatID, i18n.T(l, "department.set", userName(user), department, modeName(l, b.chatMode(ctx, b.config(), user.ID))))
+}
+
+// lookupUser finds a user by numeric ID or @username, replying to chatID if
+// there is none.
+func (b *Bot) lookupUser(ctx context.Context, chatID int64, ref string) (*database.User, bool) {
+	var user *database.User
+	var err error
+	if userID, convErr := strconv.ParseInt(ref, 10, 64); convErr == nil {
+		user, err = b.db.GetUser(ctx, userID)
+	} else {
+		user, err = b.db.FindUserByUsername(ctx, strings.TrimPrefix(ref, "@"))
+	}
+	if err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.user_lookup_failed"))
+		return nil, false
+	}
+	if user == nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.user_not_found"))
+		return nil, false
+	}
+	return user, true
+}
+
+// userName renders a stored user as "First Last (@username)".
+func userName(user *database.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if user.Username != "" {
+		name += " (@" + user.Username + ")"
+	}
+	return name
+}
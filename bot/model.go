@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"factory_bot/config"
+	"factory_bot/i18n"
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// callbackModel prefixes /model buttons: "mdl:<key>" or "mdl:default".
+const callbackModel = "mdl:"
+
+// modelDefault clears the choice so answers use the mode's model.
+const modelDefault = "default"
+
+// withUserModel applies the user's /model choice to a mode. The choice is
+// skipped when it no longer is in the catalog, the user may not use it, it
+// cannot see the images of the prompt, or its daily limit is used up; the
+// user is told about the limit.
+func (b *Bot) withUserModel(ctx context.Context, cfg *config.Config, chatID int64, mode config.Mode, images bool) config.Mode {
+	if chatID <= 0 {
+		return mode
+	}
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil || user == nil || user.Model == "" {
+		return mode
+	}
+	choice, ok := cfg.FindModel(user.Model)
+	if !ok || !b.mayUseModel(cfg, chatID, choice) || (images && !choice.Vision) {
+		return mode
+	}
+
+	if choice.DailyLimit > 0 {
+		used, err := b.db.CountAnswers(ctx, chatID, choice.ID, dayStart(cfg.Location))
+		if err != nil {
+			return mode
+		}
+		if used >= choice.DailyLimit {
+			logging.From(ctx).WithFields(logrus.Fields{
+				"user_id": chatID,
+				"model":   choice.ID,
+				"limit":   choice.DailyLimit,
+			}).Info("⏳ Model daily limit reached, using the mode's model")
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "model.limit_reached", choice.Label, choice.DailyLimit))
+			return mode
+		}
+	}
+
+	mode.Model = choice.ID
+	if choice.Vision {
+		mode.VisionModel = choice.ID
+	}
+	if !choice.Tools {
+		var tools []string
+		for _, t := range mode.Tools {
+			if t != config.ToolTickets {
+				tools = append(tools, t)
+			}
+		}
+		mode.Tools = tools
+	}
+	return mode
+}
+
+// mayUseModel reports whether the user's role allows the catalog model.
+func (b *Bot) mayUseModel(cfg *config.Config, userID int64, m config.ModelOption) bool {
+	return !m.AdminOnly || cfg.IsAdmin(userID)
+}
+
+// dayStart returns the last midnight in loc; daily limits reset then.
+func dayStart(loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+}
+
+// modelLabel renders a catalog model for buttons, e.g. "GPT-4o · 💰💰 · 🖼 · 20/day".
+func modelLabel(l i18n.Locale, m config.ModelOption) string {
+	parts := []string{m.Label, strings.Repeat("💰", config.TierRank(m.Tier)+1)}
+	if m.Vision {
+		parts = append(parts, "🖼")
+	}
+	if m.DailyLimit > 0 {
+		parts = append(parts, i18n.T(l, "model.per_day", m.DailyLimit))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (b *Bot) cmdModel(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	userID := message.From.ID
+	cfg := b.config()
+	l := b.locale(ctx, userID)
+
+	if len(args) == 0 {
+		defaultModel, _ := modeModels(cfg, b.chatMode(ctx, cfg, userID))
+		if len(cfg.Models) == 0 {
+			b.sendMessage(ctx, chatID, i18n.T(l, "model.no_catalog", defaultModel))
+			return
+		}
+
+		current := i18n.T(l, "model.default", defaultModel)
+		if user, err := b.db.GetUser(ctx, userID); err == nil && user != nil {
+			if m, ok := cfg.FindModel(user.Model); ok && b.mayUseModel(cfg, userID, m) {
+				current = modelLabel(l, m)
+			}
+		}
+		b.sendPlainMessageWithMarkup(ctx, chatID, i18n.T(l, "model.current", current), b.modelKeyboard(cfg, l, userID))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, b.setModel(ctx, userID, strings.ToLower(args[0])))
+}
+
+// modelKeyboard lists the catalog models the user may pick, one per row.
+func (b *Bot) modelKeyboard(cfg *config.Config, l i18n.Locale, userID int64) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, m := range cfg.Models {
+		if !b.mayUseModel(cfg, userID, m) {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(modelLabel(l, m), callbackModel+m.Key),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "model.button.default"), callbackModel+modelDefault),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (b *Bot) handleModelCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	reply := b.setModel(ctx, query.From.ID, strings.TrimPrefix(query.Data, callbackModel))
+	b.answerCallback(ctx, query.ID, "")
+	b.removeKeyboard(ctx, query.Message.Chat.ID, query.Message.MessageID)
+	b.sendMessage(ctx, query.Message.Chat.ID, reply)
+}
+
+// setModel stores the user's choice ("default" clears it) and returns the
+// reply.
+func (b *Bot) setModel(ctx context.Context, userID int64, key string) string {
+	cfg := b.config()
+	l := b.locale(ctx, userID)
+
+	var m config.ModelOption
+	if key != modelDefault {
+		var ok bool
+		if m, ok = cfg.FindModel(key); !ok {
+			var keys []string
+			for _, option := range cfg.Models {
+				if b.mayUseModel(cfg, userID, option) {
+					keys = append(keys, option.Key)
+				}
+			}
+			return i18n.T(l, "model.unknown", strings.Join(keys, ", "))
+		}
+		if !b.mayUseModel(cfg, userID, m) {
+			return i18n.T(l, "model.not_allowed", m.Label)
+		}
+	}
+
+	if err := b.db.SetUserModel(ctx, userID, m.Key); err != nil {
+		return i18n.T(l, "model.failed")
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"user_id": userID,
+		"choice":  key,
+		"model":   m.ID,
+	}).Info("🤖 User model changed")
+
+	if key == modelDefault {
+		defaultModel, _ := modeModels(cfg, b.chatMode(ctx, cfg, userID))
+		return i18n.T(l, "model.default_set", defaultModel)
+	}
+	if m.DailyLimit > 0 {
+		return i18n.T(l, "model.set_limited", modelLabel(l, m), m.DailyLimit)
+	}
+	return i18n.T(l, "model.set", modelLabel(l, m))
+}
@@ -0,0 +1,593 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"factory_bot/config"
+	"factory_bot/database"
+	"factory_bot/i18n"
+	"factory_bot/instructions"
+	"factory_bot/logging"
+	"factory_bot/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Prompt names in the prompt table.
+const (
+	promptMain             = "main"
+	promptImage            = "image"
+	promptTicketSuggestion = "ticket_suggestion"
+	promptShiftReport      = "shift_report"
+)
+
+var promptNames = []string{promptMain, promptImage, promptTicketSuggestion, promptShiftReport}
+
+// callbackPrompt prefixes prompt admin buttons: "pr:act:<id>" and "pr:diff:<id>".
+const (
+	callbackPrompt         = "pr:"
+	callbackPromptActivate = "pr:act:"
+	callbackPromptDiff     = "pr:diff:"
+)
+
+// maxPromptSize caps uploaded prompt files.
+const maxPromptSize = 64 << 10
+
+// promptHistoryLength is the number of versions listed by /prompt history.
+const promptHistoryLength = 10
+
+// configAuthorID marks versions published from the config file.
+const configAuthorID = 0
+
+// configPrompt returns the prompt text from the config file or the built-in default.
+func configPrompt(cfg *config.Config, name string) string {
+	switch name {
+	case promptMain:
+		return cfg.Prompts.Main
+	case promptImage:
+		return cfg.Prompts.Image
+	case promptTicketSuggestion:
+		return cfg.Prompts.TicketSuggestion
+	case promptShiftReport:
+		return cfg.Prompts.ShiftReport
+	}
+	return ""
+}
+
+func isPromptName(name string) bool {
+	for _, n := range promptNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// syncPrompts publishes config prompts that changed since they were last
+// published as new active versions, then reloads the active versions. Admin
+// edits stay active until the config file text changes.
+func (b *Bot) syncPrompts(ctx context.Context, cfg *config.Config) error {
+	for _, name := range promptNames {
+		text := configPrompt(cfg, name)
+		last, err := b.db.GetLatestPromptByAuthor(ctx, name, configAuthorID)
+		if err != nil {
+			return err
+		}
+		if last != nil && last.Text == text {
+			continue
+		}
+
+		id, err := b.db.SavePrompt(ctx, name, text, configAuthorID, "config")
+		if err != nil {
+			return err
+		}
+		if err := b.db.ActivatePrompt(ctx, id); err != nil {
+			return err
+		}
+		logging.From(ctx).WithFields(logrus.Fields{
+			"name":      name,
+			"prompt_id": id,
+		}).Info("📝 Prompt published from configuration")
+	}
+	return b.loadPrompts(ctx)
+}
+
+// loadPrompts refreshes the cache of active prompt versions.
+func (b *Bot) loadPrompts(ctx context.Context) error {
+	active, err := b.db.GetActivePrompts(ctx)
+	if err != nil {
+		return err
+	}
+
+	prompts := make(map[string]database.Prompt, len(active))
+	for _, p := range active {
+		prompts[p.Name] = p
+	}
+
+	b.promptsMu.Lock()
+	b.prompts = prompts
+	b.promptsMu.Unlock()
+	return nil
+}
+
+// prompt returns the active version of a prompt. Without one the config text
+// is used with version 0.
+func (b *Bot) prompt(name string) database.Prompt {
+	b.promptsMu.RLock()
+	p, ok := b.prompts[name]
+	b.promptsMu.RUnlock()
+	if ok {
+		return p
+	}
+	return database.Prompt{Name: name, Text: configPrompt(b.config(), name)}
+}
+
+// promptContext returns the template variables for prompts in chatID. User
+// fields are empty for group chats and chatID 0.
+func (b *Bot) promptContext(ctx context.Context, cfg *config.Config, chatID int64) instructions.Context {
+	now := time.Now().In(cfg.Location)
+	vars := instructions.Context{
+		PlantName:     cfg.PlantName,
+		PlantLocation: cfg.PlantLocation,
+		Now:           now,
+		Date:          now.Format("02.01.2006"),
+		Time:          now.Format("15:04"),
+		Weekday:       now.Weekday().String(),
+		Equipment:     cfg.Equipment,
+	}
+	if shift, _, _, ok := cfg.ShiftAt(now); ok {
+		vars.Shift = shift.Number
+		vars.ShiftTime = shift.Label()
+	}
+
+	if chatID > 0 {
+		if user, err := b.db.GetUser(ctx, chatID); err == nil && user != nil {
+			vars.UserName = strings.TrimSpace(user.FirstName + " " + user.LastName)
+			vars.Department = user.Department
+		}
+		vars.Role = "user"
+		if b.userRole(chatID) == RoleAdmin {
+			vars.Role = "admin"
+		}
+	}
+	return vars
+}
+
+// renderPrompt executes a prompt template. A template that fails is logged
+// and used as is, so a bad edit never blocks answers.
+func renderPrompt(name, text string, vars instructions.Context) string {
+	rendered, err := instructions.Render(text, vars)
+	if err != nil {
+		logrus.WithError(err).WithField("name", name).Error("❌ Failed to render prompt")
+		return text
+	}
+	return rendered
+}
+
+// promptVersionLabel joins the version IDs of the prompts used for an answer,
+// e.g. "12+7".
+func promptVersionLabel(prompts ...database.Prompt) string {
+	ids := make([]string, len(prompts))
+	for i, p := range prompts {
+		ids[i] = strconv.FormatInt(p.ID, 10)
+	}
+	return strings.Join(ids, "+")
+}
+
+func promptAuthor(l i18n.Locale, p *database.Prompt) string {
+	if p.AuthorID == configAuthorID {
+		return i18n.T(l, "prompt.config_author")
+	}
+	return p.AuthorName
+}
+
+func (b *Bot) cmdPrompt(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	sub := "list"
+	if len(args) > 0 {
+		sub = strings.ToLower(args[0])
+	}
+
+	switch {
+	case sub == "list":
+		b.listPrompts(ctx, chatID)
+	case sub == "show" && len(args) == 2:
+		if p, ok := b.findPrompt(ctx, chatID, args[1]); ok {
+			b.sendPromptFile(ctx, chatID, p)
+		}
+	case sub == "history" && len(args) == 2:
+		b.promptHistory(ctx, chatID, strings.ToLower(args[1]))
+	case sub == "edit" && len(args) == 2:
+		name := strings.ToLower(args[1])
+		if !isPromptName(name) {
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "prompt.unknown_name", strings.Join(promptNames, ", ")))
+			return
+		}
+		b.startDialog(ctx, chatUser{chatID, message.From.ID}, &promptEditDialog{b: b, chatID: chatID, author: message.From, name: name})
+	case sub == "diff" && (len(args) == 2 || len(args) == 3):
+		b.diffPrompts(ctx, chatID, args[1:])
+	case sub == "activate" && len(args) == 2:
+		if p, ok := b.findPrompt(ctx, chatID, args[1]); ok {
+			b.activatePrompt(ctx, chatID, p)
+		}
+	case sub == "rollback" && len(args) == 2:
+		b.rollbackPrompt(ctx, chatID, strings.ToLower(args[1]))
+	default:
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "prompt.usage"))
+	}
+}
+
+// findPrompt resolves a version number ("12" or "v12") or a prompt name (its
+// active version), replying to chatID if there is none.
+func (b *Bot) findPrompt(ctx context.Context, chatID int64, ref string) (*database.Prompt, bool) {
+	ref = strings.ToLower(ref)
+	if id, err := strconv.ParseInt(strings.TrimPrefix(ref, "v"), 10, 64); err == nil {
+		p, err := b.db.GetPrompt(ctx, id)
+		if err != nil {
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "prompt.load_failed"))
+			return nil, false
+		}
+		if p == nil {
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "prompt.not_found"))
+			return nil, false
+		}
+		return p, true
+	}
+
+	if !isPromptName(ref) {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "prompt.unknown_name", strings.Join(promptNames, ", ")))
+		return nil, false
+	}
+	p := b.prompt(ref)
+	return &p, true
+}
+
+func (b *Bot) listPrompts(ctx context.Context, chatID int64) {
+	l := b.locale(ctx, chatID)
+	loc := b.config().Location
+
+	var text strings.Builder
+	text.WriteString(i18n.T(l, "prompt.list_title") + "\n")
+	for _, name := range promptNames {
+		p := b.prompt(name)
+		fmt.Fprintf(&text, "• %s — v%d · %s · %s · %d\n", name, p.ID, promptAuthor(l, &p),
+			p.CreatedAt.In(loc).Format("02.01.2006 15:04"), markdown.UTF16Len(p.Text))
+	}
+	text.WriteString("\n" + i18n.T(l, "prompt.usage"))
+	b.sendPlainMessage(ctx, chatID, text.String())
+}
+
+func (b *Bot) promptHistory(ctx context.Context, chatID int64, name string) {
+	l := b.locale(ctx, chatID)
+	if !isPromptName(name) {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.unknown_name", strings.Join(promptNames, ", ")))
+		return
+	}
+
+	versions, err := b.db.GetPromptHistory(ctx, name, promptHistoryLength)
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.load_failed"))
+		return
+	}
+
+	loc := b.config().Location
+	var text strings.Builder
+	text.WriteString(i18n.T(l, "prompt.history_title", name) + "\n")
+	for _, p := range versions {
+		fmt.Fprintf(&text, "v%d · %s · %s · %d", p.ID, promptAuthor(l, &p),
+			p.CreatedAt.In(loc).Format("02.01.2006 15:04"), markdown.UTF16Len(p.Text))
+		if p.Active {
+			text.WriteString(" · " + i18n.T(l, "prompt.active_mark"))
+		}
+		text.WriteString("\n")
+	}
+	b.sendPlainMessage(ctx, chatID, text.String())
+}
+
+// sendPromptFile sends the version's text as a .txt document.
+func (b *Bot) sendPromptFile(ctx context.Context, chatID int64, p *database.Prompt) {
+	l := b.locale(ctx, chatID)
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("prompt-%s-v%d.txt", p.Name, p.ID),
+		Bytes: []byte(p.Text),
+	})
+	doc.Caption = fmt.Sprintf("%s v%d · %s · %s", p.Name, p.ID, promptAuthor(l, p),
+		p.CreatedAt.In(b.config().Location).Format("02.01.2006 15:04"))
+	if p.Active {
+		doc.Caption += " · " + i18n.T(l, "prompt.active_mark")
+	}
+
+	if _, err := b.sender.Send(ctx, chatID, doc); err != nil {
+		logging.From(ctx).WithError(err).WithField("prompt_id", p.ID).Error("❌ Failed to send prompt file")
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.load_failed"))
+	}
+}
+
+// diffPrompts compares two versions; with one version it is compared to the
+// active version of the same prompt.
+func (b *Bot) diffPrompts(ctx context.Context, chatID int64, refs []string) {
+	to, ok := b.findPrompt(ctx, chatID, refs[len(refs)-1])
+	if !ok {
+		return
+	}
+
+	var from *database.Prompt
+	if len(refs) == 2 {
+		if from, ok = b.findPrompt(ctx, chatID, refs[0]); !ok {
+			return
+		}
+	} else {
+		active := b.prompt(to.Name)
+		from = &active
+	}
+	b.sendPromptDiff(ctx, chatID, from, to)
+}
+
+func (b *Bot) sendPromptDiff(ctx context.Context, chatID int64, from, to *database.Prompt) {
+	l := b.locale(ctx, chatID)
+	diff, added, removed := lineDiff(from.Text, to.Text)
+	if added == 0 && removed == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.diff_same"))
+		return
+	}
+
+	title := i18n.T(l, "prompt.diff_title", to.Name, from.ID, to.ID, added, removed)
+	// Sent as plain text: prompts often contain ``` and Markdown that would
+	// break a code fence
+	text := title + "\n\n" + diff
+	if markdown.UTF16Len(text) > maxMessageLength-partReserve {
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+			Name:  fmt.Sprintf("prompt-%s-v%d-v%d.diff", to.Name, from.ID, to.ID),
+			Bytes: []byte(diff),
+		})
+		doc.Caption = title
+		if _, err := b.sender.Send(ctx, chatID, doc); err != nil {
+			logging.From(ctx).WithError(err).WithField("prompt_id", to.ID).Error("❌ Failed to send prompt diff")
+		}
+		return
+	}
+	b.sendPlainMessage(ctx, chatID, text)
+}
+
+func (b *Bot) activatePrompt(ctx context.Context, chatID int64, p *database.Prompt) {
+	l := b.locale(ctx, chatID)
+	if p.Active {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.already_active", p.Name, p.ID))
+		return
+	}
+	if err := b.db.ActivatePrompt(ctx, p.ID); err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.activate_failed"))
+		return
+	}
+	if err := b.loadPrompts(ctx); err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Failed to reload prompts")
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"name":      p.Name,
+		"prompt_id": p.ID,
+		"chat_id":   chatID,
+	}).Info("📝 Prompt version activated")
+	b.sendMessage(ctx, chatID, i18n.T(l, "prompt.activated", p.Name, p.ID))
+}
+
+func (b *Bot) rollbackPrompt(ctx context.Context, chatID int64, name string) {
+	l := b.locale(ctx, chatID)
+	if !isPromptName(name) {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.unknown_name", strings.Join(promptNames, ", ")))
+		return
+	}
+
+	previous, err := b.db.GetPreviousPrompt(ctx, name)
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.load_failed"))
+		return
+	}
+	if previous == nil {
+		b.sendMessage(ctx, chatID, i18n.T(l, "prompt.no_previous", name))
+		return
+	}
+	b.activatePrompt(ctx, chatID, previous)
+}
+
+func (b *Bot) handlePromptCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil || !b.config().IsAdmin(query.From.ID) {
+		b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "not_allowed"))
+		return
+	}
+	chatID := query.Message.Chat.ID
+
+	var ref string
+	activate := strings.HasPrefix(query.Data, callbackPromptActivate)
+	if activate {
+		ref = strings.TrimPrefix(query.Data, callbackPromptActivate)
+	} else {
+		ref = strings.TrimPrefix(query.Data, callbackPromptDiff)
+	}
+
+	b.answerCallback(ctx, query.ID, "")
+	p, ok := b.findPrompt(ctx, chatID, ref)
+	if !ok {
+		return
+	}
+	if activate {
+		b.removeKeyboard(ctx, chatID, query.Message.MessageID)
+		b.activatePrompt(ctx, chatID, p)
+		return
+	}
+	active := b.prompt(p.Name)
+	b.sendPromptDiff(ctx, chatID, &active, p)
+}
+
+// promptEditDialog takes the new text of a prompt as a file or a message and
+// saves it as an inactive version.
+type promptEditDialog struct {
+	b      *Bot
+	chatID int64
+	author *tgbotapi.User
+	name   string
+}
+
+func (d *promptEditDialog) start(ctx context.Context) {
+	current := d.b.prompt(d.name)
+	d.b.sendPromptFile(ctx, d.chatID, &current)
+	d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "prompt.edit_start", d.name))
+}
+
+func (d *promptEditDialog) handleMessage(ctx context.Context, message *tgbotapi.Message) bool {
+	l := d.b.locale(ctx, d.chatID)
+
+	var text string
+	switch {
+	case message.Document != nil:
+		data, err := d.b.downloadFile(ctx, message.Document.FileID, maxPromptSize)
+		if err != nil || !utf8.Valid(data) {
+			logging.From(ctx).WithError(err).WithField("chat_id", d.chatID).Warn("⚠️ Rejected prompt file")
+			d.b.sendMessage(ctx, d.chatID, i18n.T(l, "prompt.file_invalid", maxPromptSize>>10))
+			return false
+		}
+		text = string(data)
+	case message.Text != "":
+		text = message.Text
+	default:
+		d.b.sendMessage(ctx, d.chatID, i18n.T(l, "prompt.edit_expect"))
+		return false
+	}
+
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		d.b.sendMessage(ctx, d.chatID, i18n.T(l, "prompt.empty"))
+		return false
+	}
+	if err := instructions.Validate(text); err != nil {
+		d.b.sendPlainMessage(ctx, d.chatID, i18n.T(l, "prompt.template_invalid", err))
+		return false
+	}
+
+	active := d.b.prompt(d.name)
+	if text == active.Text {
+		d.b.sendMessage(ctx, d.chatID, i18n.T(l, "prompt.unchanged", active.ID))
+		return true
+	}
+
+	id, err := d.b.db.SavePrompt(ctx, d.name, text, d.author.ID, displayName(d.author))
+	if err != nil {
+		d.b.sendMessage(ctx, d.chatID, i18n.T(l, "prompt.save_failed"))
+		return true
+	}
+
+	_, added, removed := lineDiff(active.Text, text)
+	ref := strconv.FormatInt(id, 10)
+	d.b.sendMessageWithMarkup(ctx, d.chatID, i18n.T(l, "prompt.saved", d.name, id, added, removed), tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "prompt.button.activate"), callbackPromptActivate+ref),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "prompt.button.diff"), callbackPromptDiff+ref),
+		),
+	))
+	return true
+}
+
+func (d *promptEditDialog) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery, data string) bool {
+	d.b.answerCallback(ctx, query.ID, "")
+	return false
+}
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 2
+
+// maxDiffCells bounds the LCS table; larger inputs are shown as a full replacement.
+const maxDiffCells = 4 << 20
+
+// lineDiff renders a line diff of a and b with "-", "+" and " " prefixes,
+// eliding unchanged runs, and counts added and removed lines.
+func lineDiff(a, b string) (string, int, int) {
+	x := strings.Split(a, "\n")
+	y := strings.Split(b, "\n")
+
+	type op struct {
+		kind byte // ' ', '-' or '+'
+		line string
+	}
+	var ops []op
+
+	if len(x)*len(y) > maxDiffCells {
+		for _, line := range x {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range y {
+			ops = append(ops, op{'+', line})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of x[i:] and y[j:]
+		lcs := make([][]int, len(x)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(y)+1)
+		}
+		for i := len(x) - 1; i >= 0; i-- {
+			for j := len(y) - 1; j >= 0; j-- {
+				if x[i] == y[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(x) || j < len(y) {
+			switch {
+			case i < len(x) && j < len(y) && x[i] == y[j]:
+				ops = append(ops, op{' ', x[i]})
+				i++
+				j++
+			case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, op{'-', x[i]})
+				i++
+			default:
+				ops = append(ops, op{'+', y[j]})
+				j++
+			}
+		}
+	}
+
+	// Keep changed lines and diffContext unchanged lines around them
+	keep := make([]bool, len(ops))
+	added, removed := 0, 0
+	for k, o := range ops {
+		if o.kind == ' ' {
+			continue
+		}
+		if o.kind == '+' {
+			added++
+		} else {
+			removed++
+		}
+		for c := max(0, k-diffContext); c <= min(len(ops)-1, k+diffContext); c++ {
+			keep[c] = true
+		}
+	}
+
+	var out strings.Builder
+	skipped := false
+	for k, o := range ops {
+		if !keep[k] {
+			skipped = true
+			continue
+		}
+		if skipped && out.Len() > 0 {
+			out.WriteString("…\n")
+		}
+		skipped = false
+		out.WriteByte(o.kind)
+		out.WriteByte(' ')
+		out.WriteString(o.line)
+		out.WriteByte('\n')
+	}
+	return out.String(), added, removed
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"factory_bot/config"
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// config returns the current configuration. Handlers that read several
+// settings should take one snapshot so a reload cannot mix old and new values.
+func (b *Bot) config() *config.Config {
+	return b.cfg.Load()
+}
+
+// Reload re-reads the config file and applies the settings that are safe to
+// change at runtime: models, limits, prompts and the admin list. Other changed
+// settings are returned in restart and take effect after a restart. On error
+// the current configuration stays in place.
+func (b *Bot) Reload() (changed, restart []string, err error) {
+	b.reloadMu.Lock()
+	defer b.reloadMu.Unlock()
+
+	prev := b.config()
+	next, changed, restart, err := config.Reload(prev)
+	if err != nil {
+		logrus.WithError(err).Error("❌ Configuration reload failed")
+		return nil, nil, err
+	}
+	b.cfg.Store(next)
+
+	for _, key := range changed {
+		if key == "telegram.admin_ids" {
+			// New admins get the admin menu, removed ones fall back to the user menu
+			b.syncCommands()
+			for _, id := range prev.AdminIDs {
+				if !next.IsAdmin(id) {
+					b.deleteAdminCommands(id)
+				}
+			}
+			break
+		}
+	}
+
+	for _, key := range changed {
+		if strings.HasPrefix(key, "log.") {
+			// Values were validated by config.Reload
+			if err := logging.Configure(next.LogLevel, next.LogFormat); err != nil {
+				logrus.WithError(err).Error("❌ Failed to apply logging settings")
+			}
+			break
+		}
+	}
+	for _, key := range changed {
+		if strings.HasPrefix(key, "prompts.") {
+			// Keep the new config and report the failure; the cached prompts stay in use
+			if err := b.syncPrompts(context.Background(), next); err != nil {
+				logrus.WithError(err).Error("❌ Failed to publish prompts from configuration")
+			}
+			break
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"file":    next.File,
+		"changed": changed,
+	}).Info("🔄 Configuration reloaded")
+	if len(restart) > 0 {
+		logrus.WithField("settings", restart).Warn("⚠️ Changed settings need a restart to take effect")
+	}
+	return changed, restart, nil
+}
+
+func (b *Bot) cmdReload(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+
+	changed, restart, err := b.Reload()
+	if err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "reload.failed", err.Error()))
+		return
+	}
+
+	var text strings.Builder
+	if len(changed) == 0 {
+		text.WriteString(b.t(ctx, chatID, "reload.unchanged"))
+	} else {
+		text.WriteString(b.t(ctx, chatID, "reload.done", strings.Join(changed, ", ")))
+	}
+	if len(restart) > 0 {
+		text.WriteString("\n\n" + b.t(ctx, chatID, "reload.restart", strings.Join(restart, ", ")))
+	}
+	b.sendMessage(ctx, chatID, text.String())
+}
@@ -0,0 +1,307 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"factory_bot/database"
+	"factory_bot/logging"
+	"factory_bot/scheduler"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Role targets accepted by /schedule in addition to numeric chat IDs.
+const (
+	targetAdmins     = "admins"
+	targetSafety     = "safety"
+	targetManagement = "management"
+)
+
+// runJob delivers a scheduled job to its target chats.
+func (b *Bot) runJob(ctx context.Context, job database.Job) error {
+	chatIDs := b.resolveTargets(job.Target)
+	if len(chatIDs) == 0 {
+		return fmt.Errorf("job %d: target %q resolves to no chats", job.ID, job.Target)
+	}
+
+	delivered := 0
+	for _, chatID := range chatIDs {
+		text := "🔔 " + job.Text
+		if job.Kind == database.JobReminder {
+			text = b.t(ctx, chatID, "reminder.header", job.Text)
+		}
+		if b.sendPlainMessage(ctx, chatID, text) != nil {
+			delivered++
+		}
+	}
+	if delivered == 0 {
+		return fmt.Errorf("job %d: delivery failed for all %d chats", job.ID, len(chatIDs))
+	}
+	return nil
+}
+
+// resolveTargets expands a comma-separated list of chat IDs and role names.
+func (b *Bot) resolveTargets(target string) []int64 {
+	seen := make(map[int64]bool)
+	var chatIDs []int64
+	add := func(id int64) {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			chatIDs = append(chatIDs, id)
+		}
+	}
+
+	for _, part := range strings.Split(target, ",") {
+		part = strings.TrimSpace(part)
+		switch strings.ToLower(part) {
+		case targetAdmins:
+			for _, id := range b.config().AdminIDs {
+				add(id)
+			}
+		case targetSafety:
+			add(b.config().SafetyChatID)
+		case targetManagement:
+			add(b.config().ShiftReportChatID)
+		default:
+			if id, err := strconv.ParseInt(part, 10, 64); err == nil {
+				add(id)
+			}
+		}
+	}
+	return chatIDs
+}
+
+// validTarget reports whether every element of target is a chat ID or a known role.
+func validTarget(target string) bool {
+	for _, part := range strings.Split(target, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		switch part {
+		case targetAdmins, targetSafety, targetManagement:
+			continue
+		}
+		if _, err := strconv.ParseInt(part, 10, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parseWhen reads a reminder time from the start of args: a duration ("30m",
+// "2h", "3d"), a time of day ("14:30", today or tomorrow) or a date and time
+// ("25.12 08:00", "25.12.2025 08:00", "2025-12-25 08:00"). It returns the run
+// time and the number of arguments consumed.
+func (b *Bot) parseWhen(args []string, now time.Time) (time.Time, int, bool) {
+	if len(args) == 0 {
+		return time.Time{}, 0, false
+	}
+	loc := b.config().Location
+	now = now.In(loc)
+	first := args[0]
+
+	if strings.HasSuffix(first, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(first, "d")); err == nil && days > 0 {
+			return now.AddDate(0, 0, days), 1, true
+		}
+	}
+	if d, err := time.ParseDuration(first); err == nil && d > 0 {
+		return now.Add(d), 1, true
+	}
+
+	// Date and time split across two arguments, or quoted as one
+	candidates := []struct {
+		value    string
+		consumed int
+	}{{first, 1}}
+	if len(args) > 1 {
+		candidates = append([]struct {
+			value    string
+			consumed int
+		}{{first + " " + args[1], 2}}, candidates...)
+	}
+
+	for _, c := range candidates {
+		for _, layout := range []string{"02.01.2006 15:04", "2006-01-02 15:04"} {
+			if t, err := time.ParseInLocation(layout, c.value, loc); err == nil {
+				return t, c.consumed, t.After(now)
+			}
+		}
+		if t, err := time.ParseInLocation("02.01 15:04", c.value, loc); err == nil {
+			t = time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+			if !t.After(now) {
+				t = t.AddDate(1, 0, 0)
+			}
+			return t, c.consumed, true
+		}
+	}
+
+	if t, err := time.ParseInLocation("15:04", first, loc); err == nil {
+		run := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+		if !run.After(now) {
+			run = run.AddDate(0, 0, 1)
+		}
+		return run, 1, true
+	}
+
+	return time.Time{}, 0, false
+}
+
+func (b *Bot) cmdRemind(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	usage := b.t(ctx, chatID, "remind.usage")
+
+	if len(args) == 0 {
+		b.sendPlainMessage(ctx, chatID, usage)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		b.listJobs(ctx, chatID, database.JobReminder, message.From.ID)
+		return
+	case "cancel":
+		if len(args) < 2 {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		b.cancelJob(ctx, chatID, args[1], database.JobReminder, message.From.ID)
+		return
+	}
+
+	job := &database.Job{
+		Kind:    database.JobReminder,
+		OwnerID: message.From.ID,
+		Target:  strconv.FormatInt(chatID, 10),
+	}
+
+	var rest []string
+	if strings.ToLower(args[0]) == "cron" {
+		if len(args) < 3 {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		job.Cron = args[1]
+		rest = args[2:]
+	} else {
+		runAt, consumed, ok := b.parseWhen(args, time.Now())
+		if !ok || consumed >= len(args) {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		job.NextRun = runAt
+		rest = args[consumed:]
+	}
+	job.Text = strings.Join(rest, " ")
+
+	if err := b.scheduler.Add(ctx, job); err != nil {
+		logging.From(ctx).WithError(err).WithField("user_id", message.From.ID).Warn("⚠️ Failed to schedule reminder")
+		b.sendPlainMessage(ctx, chatID, b.t(ctx, chatID, "remind.failed", err.Error()))
+		return
+	}
+
+	b.sendPlainMessage(ctx, chatID, b.t(ctx, chatID, "remind.created",
+		job.ID, job.NextRun.In(b.config().Location).Format("02.01.2006 15:04"), b.cronNote(ctx, chatID, job)))
+}
+
+func (b *Bot) cmdSchedule(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	usage := b.t(ctx, chatID, "schedule.usage")
+
+	if len(args) == 0 {
+		b.sendPlainMessage(ctx, chatID, usage)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if len(args) < 4 || !validTarget(args[2]) {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		job := &database.Job{
+			Kind:    database.JobRecurring,
+			OwnerID: message.From.ID,
+			Cron:    args[1],
+			Target:  args[2],
+			Text:    strings.Join(args[3:], " "),
+		}
+		if err := b.scheduler.Add(ctx, job); err != nil {
+			b.sendPlainMessage(ctx, chatID, b.t(ctx, chatID, "schedule.failed", err.Error()))
+			return
+		}
+		b.sendPlainMessage(ctx, chatID, b.t(ctx, chatID, "schedule.created",
+			job.ID, job.Target, job.NextRun.In(b.config().Location).Format("02.01.2006 15:04")))
+
+	case "list":
+		b.listJobs(ctx, chatID, database.JobRecurring, 0)
+
+	case "remove":
+		if len(args) < 2 {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		b.cancelJob(ctx, chatID, args[1], database.JobRecurring, 0)
+
+	default:
+		b.sendPlainMessage(ctx, chatID, usage)
+	}
+}
+
+func (b *Bot) listJobs(ctx context.Context, chatID int64, kind string, ownerID int64) {
+	jobs, err := b.db.ListJobs(ctx, kind, ownerID)
+	if err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "jobs.list_failed"))
+		return
+	}
+	if len(jobs) == 0 {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "jobs.empty"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(b.t(ctx, chatID, "jobs.title") + "\n\n")
+	for _, job := range jobs {
+		fmt.Fprintf(&text, "#%d · %s", job.ID, job.NextRun.In(b.config().Location).Format("02.01.2006 15:04"))
+		if job.Cron != "" {
+			fmt.Fprintf(&text, " · cron %q", job.Cron)
+		}
+		if job.Kind == database.JobRecurring {
+			fmt.Fprintf(&text, " · → %s", job.Target)
+		}
+		fmt.Fprintf(&text, "\n%s\n\n", truncateText(job.Text, 200))
+	}
+	b.sendPlainMessage(ctx, chatID, text.String())
+}
+
+func (b *Bot) cancelJob(ctx context.Context, chatID int64, value, kind string, ownerID int64) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(value, "#"), 10, 64)
+	if err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "jobs.invalid_number"))
+		return
+	}
+
+	ok, err := b.db.DeactivateJob(ctx, id, ownerID, kind)
+	switch {
+	case err != nil:
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "jobs.cancel_failed"))
+	case !ok:
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "jobs.not_found"))
+	default:
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "jobs.cancelled", id))
+	}
+}
+
+func (b *Bot) cronNote(ctx context.Context, chatID int64, job *database.Job) string {
+	if job.Cron == "" {
+		return ""
+	}
+	return b.t(ctx, chatID, "jobs.repeats", job.Cron)
+}
+
+// newScheduler wires the persistent scheduler to job delivery.
+func (b *Bot) newScheduler() *scheduler.Scheduler {
+	return scheduler.New(b.db, b.config().Location, b.runJob)
+}
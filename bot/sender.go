@@ -0,0 +1,331 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"factory_bot/logging"
+	"factory_bot/tracing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Telegram flood limits: about 30 messages per second in total, one per
+// second in a private chat and 20 per minute in a group. Short bursts are
+// tolerated, so a split answer goes out without delay.
+const (
+	globalSendRate  = 30.0
+	globalSendBurst = 30
+	chatSendRate    = 1.0
+	groupSendRate   = 20.0 / 60
+	chatSendBurst   = 3
+)
+
+const (
+	maxSendAttempts = 4
+	maxRetryAfter   = time.Minute // longer flood waits are not worth holding a worker for
+	sendBackoff     = time.Second // first retry delay after a network or server error
+	maxChatBuckets  = 10000       // idle per-chat buckets are pruned above this
+)
+
+// sendErrorKind classifies Telegram send failures.
+type sendErrorKind int
+
+const (
+	sendErrPermanent   sendErrorKind = iota // chat not found, bot blocked, bad request
+	sendErrParse                            // entities in the text could not be parsed
+	sendErrRateLimited                      // 429 Too Many Requests
+	sendErrTransient                        // network failure or Telegram server error
+)
+
+func (k sendErrorKind) String() string {
+	switch k {
+	case sendErrParse:
+		return "parse"
+	case sendErrRateLimited:
+		return "rate_limited"
+	case sendErrTransient:
+		return "transient"
+	}
+	return "permanent"
+}
+
+// classifySendError returns the kind of err and, for flood control, how long
+// Telegram asked to wait.
+func classifySendError(err error) (sendErrorKind, time.Duration) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		// No API response: connection, timeout or decoding failure
+		return sendErrTransient, 0
+	}
+
+	switch {
+	case apiErr.Code == 429 || apiErr.RetryAfter > 0:
+		return sendErrRateLimited, time.Duration(apiErr.RetryAfter) * time.Second
+	case apiErr.Code >= 500:
+		return sendErrTransient, 0
+	case apiErr.Code == 400 && isParseErrorMessage(apiErr.Message):
+		return sendErrParse, 0
+	}
+	return sendErrPermanent, 0
+}
+
+func isParseErrorMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "can't parse entities") ||
+		strings.Contains(message, "can't find end of the entity") ||
+		strings.Contains(message, "unsupported start tag")
+}
+
+// isParseError reports whether Telegram rejected a message because of its formatting.
+func isParseError(err error) bool {
+	kind, _ := classifySendError(err)
+	return kind == sendErrParse
+}
+
+// tokenBucket is a reservation-style rate limiter: tokens may go negative,
+// and the deficit is the wait before the reserved send may happen.
+type tokenBucket struct {
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+func (tb *tokenBucket) refill(now time.Time) {
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (tb *tokenBucket) reserve(now time.Time) time.Duration {
+	tb.refill(now)
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+// available reports whether a token can be taken without waiting.
+func (tb *tokenBucket) available(now time.Time) bool {
+	tb.refill(now)
+	return tb.tokens >= 1
+}
+
+// full reports whether the bucket has refilled completely and can be dropped.
+func (tb *tokenBucket) full(now time.Time) bool {
+	tb.refill(now)
+	return tb.tokens >= tb.burst
+}
+
+// sender is the outgoing side of the bot: every message goes through a global
+// and a per-chat rate limit, and flood-control and network errors are retried.
+type sender struct {
+	api *tgbotapi.BotAPI
+
+	mu     sync.Mutex
+	global *tokenBucket
+	chats  map[int64]*tokenBucket
+}
+
+func newSender(api *tgbotapi.BotAPI) *sender {
+	return &sender{
+		api:    api,
+		global: newTokenBucket(globalSendRate, globalSendBurst, time.Now()),
+		chats:  make(map[int64]*tokenBucket),
+	}
+}
+
+// wait blocks until a message may be sent to chatID or ctx is done.
+func (s *sender) wait(ctx context.Context, chatID int64) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	bucket := s.chatBucket(chatID, now)
+	delay := s.global.reserve(now)
+	if d := bucket.reserve(now); d > delay {
+		delay = d
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		logging.From(ctx).WithFields(logrus.Fields{
+			"chat_id": chatID,
+			"delay":   delay.String(),
+		}).Debug("⏳ Send delayed by rate limit")
+	}
+	return sleep(ctx, delay)
+}
+
+// reserveNow takes a send slot for chatID if one is free right away.
+func (s *sender) reserveNow(chatID int64) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bucket := s.chatBucket(chatID, now)
+	if !s.global.available(now) || !bucket.available(now) {
+		return false
+	}
+	s.global.reserve(now)
+	bucket.reserve(now)
+	return true
+}
+
+// chatBucket returns the bucket of chatID, creating it if needed. The caller
+// holds s.mu.
+func (s *sender) chatBucket(chatID int64, now time.Time) *tokenBucket {
+	bucket, ok := s.chats[chatID]
+	if !ok {
+		if len(s.chats) >= maxChatBuckets {
+			s.prune(now)
+		}
+		rate := chatSendRate
+		if chatID < 0 {
+			// Groups, supergroups and channels have negative IDs
+			rate = groupSendRate
+		}
+		bucket = newTokenBucket(rate, chatSendBurst, now)
+		s.chats[chatID] = bucket
+	}
+	return bucket
+}
+
+// sleep waits for d or until ctx is done, returning ctx's error in that case.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// prune drops buckets of chats that have been idle long enough to refill.
+func (s *sender) prune(now time.Time) {
+	for id, bucket := range s.chats {
+		if bucket.full(now) {
+			delete(s.chats, id)
+		}
+	}
+}
+
+// do runs call under the rate limit of chatID (none when chatID is 0),
+// retrying flood-control, network and server errors.
+func (s *sender) do(ctx context.Context, chatID int64, method string, call func() error) error {
+	ctx, span := tracing.StartKind(ctx, tracing.KindClient, "telegram "+strings.TrimPrefix(method, "tgbotapi."),
+		tracing.Int64("telegram.chat_id", chatID))
+	defer span.End()
+
+	backoff := sendBackoff
+
+	for attempt := 1; ; attempt++ {
+		if chatID != 0 {
+			if err := s.wait(ctx, chatID); err != nil {
+				span.RecordError(err)
+				return err
+			}
+		}
+
+		err := call()
+		if err == nil {
+			span.SetAttributes(tracing.Int("telegram.attempts", attempt))
+			return nil
+		}
+
+		kind, retryAfter := classifySendError(err)
+		retryable := kind == sendErrRateLimited || kind == sendErrTransient
+		if !retryable || attempt == maxSendAttempts || retryAfter > maxRetryAfter {
+			sendFailures.Inc(kind.String())
+			span.SetAttributes(tracing.Int("telegram.attempts", attempt), tracing.String("error.class", kind.String()))
+			span.RecordError(err)
+			return err
+		}
+
+		delay := backoff
+		if kind == sendErrRateLimited {
+			delay = retryAfter
+			if delay == 0 {
+				delay = backoff
+			}
+		} else {
+			backoff *= 2
+		}
+
+		logging.From(ctx).WithError(err).WithFields(logrus.Fields{
+			"chat_id": chatID,
+			"request": method,
+			"class":   kind.String(),
+			"attempt": attempt,
+			"retry":   delay.String(),
+		}).Warn("⚠️ Telegram request failed, retrying")
+		if err := sleep(ctx, delay); err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+}
+
+// TrySend makes a request in the background with a single attempt, or drops
+// it when the rate limit of chatID would delay it. It is meant for notices
+// sent while receiving updates, which must not wait for a busy chat. Requests
+// with chatID 0 are not rate limited.
+func (s *sender) TrySend(ctx context.Context, chatID int64, c tgbotapi.Chattable) bool {
+	if chatID != 0 && !s.reserveNow(chatID) {
+		logging.From(ctx).WithField("chat_id", chatID).Debug("⏳ Notice dropped by rate limit")
+		return false
+	}
+	go func() {
+		if _, err := s.api.Request(c); err != nil {
+			logging.From(ctx).WithError(err).WithField("chat_id", chatID).Warn("⚠️ Failed to send notice")
+		}
+	}()
+	return true
+}
+
+// Send sends a message-producing request to chatID.
+func (s *sender) Send(ctx context.Context, chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var sent tgbotapi.Message
+	err := s.do(ctx, chatID, fmt.Sprintf("%T", c), func() (err error) {
+		sent, err = s.api.Send(c)
+		return err
+	})
+	return sent, err
+}
+
+// SendMediaGroup sends an album to chatID.
+func (s *sender) SendMediaGroup(ctx context.Context, chatID int64, config tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	var sent []tgbotapi.Message
+	err := s.do(ctx, chatID, "sendMediaGroup", func() (err error) {
+		sent, err = s.api.SendMediaGroup(config)
+		return err
+	})
+	return sent, err
+}
+
+// Request makes a request whose result is not a message (edits, callback
+// answers, chat actions). Requests with chatID 0 are not rate limited.
+func (s *sender) Request(ctx context.Context, chatID int64, c tgbotapi.Chattable) error {
+	return s.do(ctx, chatID, fmt.Sprintf("%T", c), func() error {
+		_, err := s.api.Request(c)
+		return err
+	})
+}
@@ -0,0 +1,220 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"factory_bot/logging"
+	"factory_bot/tracing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+)
+
+// shiftReportMaxInput caps the conversation transcript sent to the model.
+const shiftReportMaxInput = 60000
+
+// runShiftReports posts a report to the management chat at the end of every shift.
+func (b *Bot) runShiftReports(ctx context.Context) {
+	if b.config().ShiftReportChatID == 0 {
+		logrus.Info("SHIFT_REPORT_CHAT_ID not set, end-of-shift reports disabled")
+		return
+	}
+
+	for {
+		shift, start, end, ok := b.config().NextShiftEnd(time.Now())
+		if !ok {
+			logrus.Warn("⚠️ No shifts configured, end-of-shift reports disabled")
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"shift":     shift.Number,
+			"report_at": end.Format(time.RFC3339),
+		}).Info("⏰ Next shift report scheduled")
+
+		timer := time.NewTimer(time.Until(end))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// A report in progress runs on like an update handler until Shutdown cancels it
+		chatID := b.config().ShiftReportChatID
+		reportCtx := logging.WithRequest(b.workCtx, logging.Request{ID: logging.NewRequestID(), ChatID: chatID, Job: "shift_report"})
+		title := b.t(reportCtx, chatID, "shiftreport.title", shift.Number, shift.Label(), start.Format("02.01.2006"))
+		b.postShiftReport(reportCtx, chatID, title, start, end)
+	}
+}
+
+// postShiftReport generates a summary for [from, to) and sends it to chatID.
+func (b *Bot) postShiftReport(ctx context.Context, chatID int64, title string, from, to time.Time) {
+	ctx, span := tracing.Start(ctx, "shift_report", tracing.Int64("telegram.chat_id", chatID))
+	defer span.End()
+
+	startTime := time.Now()
+	logging.From(ctx).WithFields(logrus.Fields{
+		"chat_id": chatID,
+		"from":    from.Format(time.RFC3339),
+		"to":      to.Format(time.RFC3339),
+	}).Info("📋 Generating shift report")
+
+	data, err := b.collectShiftData(ctx, from, to)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Failed to collect shift data")
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "shiftreport.collect_failed"))
+		return
+	}
+
+	cfg := b.config()
+	p := b.prompt(promptShiftReport)
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: renderPrompt(p.Name, p.Text, b.promptContext(ctx, cfg, 0)),
+		},
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: data,
+		},
+	}
+
+	report, err := b.aiProvider.Generate(ctx, messages, cfg.TextModel, cfg.ReportMaxTokens, 0)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Failed to generate shift report")
+		b.replyError(ctx, chatID, "shiftreport.failed", err)
+		return
+	}
+
+	b.sendMessage(ctx, chatID, title+"\n\n"+report)
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"chat_id":         chatID,
+		"report_length":   len(report),
+		"processing_time": time.Since(startTime).String(),
+	}).Info("✅ Shift report posted")
+}
+
+// collectShiftData renders the period's conversations, incidents and tickets as model input.
+func (b *Bot) collectShiftData(ctx context.Context, from, to time.Time) (string, error) {
+	messages, err := b.db.GetMessagesBetween(ctx, from, to)
+	if err != nil {
+		return "", err
+	}
+	incidents, err := b.db.GetIncidentsBetween(ctx, from, to)
+	if err != nil {
+		return "", err
+	}
+	tickets, err := b.db.GetTicketsBetween(ctx, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	loc := b.config().Location
+	var data strings.Builder
+	fmt.Fprintf(&data, "Период: %s — %s\n\n", from.In(loc).Format("02.01.2006 15:04"), to.In(loc).Format("02.01.2006 15:04"))
+
+	fmt.Fprintf(&data, "=== ПРОИСШЕСТВИЯ (%d) ===\n", len(incidents))
+	for _, inc := range incidents {
+		fmt.Fprintf(&data, "%s | %s | серьёзность: %s | статус: %s | место: %s\n%s\n\n",
+			inc.TrackingNumber(), inc.Category, inc.Severity, inc.Status, inc.Location, inc.Description)
+	}
+
+	fmt.Fprintf(&data, "\n=== ЗАЯВКИ НА РЕМОНТ (%d) ===\n", len(tickets))
+	for _, t := range tickets {
+		fmt.Fprintf(&data, "%s | %s | приоритет: %s | статус: %s | исполнитель: %s\n%s\n\n",
+			t.Number(), t.Equipment, t.Priority, t.Status, t.AssigneeName, t.Description)
+	}
+
+	// Conversations come last so truncation drops the least important part
+	fmt.Fprintf(&data, "\n=== ОБРАЩЕНИЯ К АССИСТЕНТУ (%d сообщений) ===\n", len(messages))
+	for _, msg := range messages {
+		if data.Len() > shiftReportMaxInput {
+			data.WriteString("\n[…остальные сообщения опущены]\n")
+			break
+		}
+
+		text := msg.Text
+		if msg.ImageFileID != "" {
+			text = imagePlaceholder(msg.Text)
+		}
+		if msg.Role == "assistant" {
+			fmt.Fprintf(&data, "[%s] Ассистент: %s\n", msg.Timestamp.In(loc).Format("15:04"), truncateText(text, 400))
+		} else {
+			fmt.Fprintf(&data, "[%s] %s: %s\n", msg.Timestamp.In(loc).Format("15:04"), msg.Username, truncateText(text, 1000))
+		}
+	}
+
+	return data.String(), nil
+}
+
+// reportTimeLayouts are accepted by /shiftreport, interpreted in the plant time zone.
+var reportTimeLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"02.01.2006 15:04",
+	"2006-01-02",
+	"02.01.2006",
+}
+
+func (b *Bot) parseReportTime(value string) (time.Time, bool) {
+	for _, layout := range reportTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, b.config().Location); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (b *Bot) cmdShiftReport(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	now := time.Now().In(b.config().Location)
+	usage := b.t(ctx, chatID, "shiftreport.usage")
+
+	var from, to time.Time
+	var title string
+
+	switch len(args) {
+	case 0:
+		shift, start, _, ok := b.config().ShiftAt(now)
+		if !ok {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		from, to = start, now
+		title = b.t(ctx, chatID, "shiftreport.current_title", shift.Number, shift.Label(), now.Format("02.01.2006 15:04"))
+
+	case 1:
+		if d, err := time.ParseDuration(args[0]); err == nil && d > 0 {
+			from, to = now.Add(-d), now
+			break
+		}
+		start, ok := b.parseReportTime(args[0])
+		if !ok {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		from, to = start, now
+
+	default:
+		start, ok1 := b.parseReportTime(args[0])
+		end, ok2 := b.parseReportTime(args[1])
+		if !ok1 || !ok2 || !end.After(start) {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		from, to = start, end
+	}
+
+	if title == "" {
+		title = b.t(ctx, chatID, "shiftreport.period_title", from.Format("02.01.2006 15:04"), to.Format("02.01.2006 15:04"))
+	}
+
+	b.sendTyping(ctx, chatID)
+	b.postShiftReport(ctx, chatID, title, from, to)
+}
@@ -0,0 +1,610 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"factory_bot/database"
+	"factory_bot/i18n"
+	"factory_bot/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Callback data for ticket buttons
+const (
+	callbackTicketDraft  = "tk:draft"
+	callbackTicketStatus = "tk:st:" // "tk:st:<id>:<status>"
+)
+
+// errNotAllowed is returned when the user lacks permission for a change.
+var errNotAllowed = errors.New("not allowed")
+
+// maxTicketPhotos limits the photos attached to one ticket.
+const maxTicketPhotos = 10
+
+var ticketPriorities = []choice{
+	{"low", "ticket.priority.low"},
+	{"normal", "ticket.priority.normal"},
+	{"high", "ticket.priority.high"},
+	{"urgent", "ticket.priority.urgent"},
+}
+
+var ticketStatuses = []choice{
+	{database.TicketOpen, "ticket.status.open"},
+	{database.TicketInProgress, "ticket.status.in_progress"},
+	{database.TicketOnHold, "ticket.status.on_hold"},
+	{database.TicketClosed, "ticket.status.closed"},
+}
+
+// ticketMarker matches the suggestion line the model appends when a
+// conversation describes a fault (see instructions.TicketSuggestionInstruction).
+var ticketMarker = regexp.MustCompile(`(?m)^\s*\[\[TICKET\|([^|\]]*)\|([^|\]]*)\|([^\]]*)\]\]\s*$`)
+
+// extractTicketSuggestion strips the ticket marker from a model answer and
+// returns the prefilled draft, or nil if the model did not suggest a ticket.
+func extractTicketSuggestion(answer string) (string, *database.Ticket) {
+	match := ticketMarker.FindStringSubmatch(answer)
+	if match == nil {
+		return answer, nil
+	}
+
+	clean := strings.TrimSpace(ticketMarker.ReplaceAllString(answer, ""))
+	priority := strings.ToLower(strings.TrimSpace(match[2]))
+	if !isChoice(ticketPriorities, priority) {
+		priority = "normal"
+	}
+
+	return clean, &database.Ticket{
+		Equipment:   strings.TrimSpace(match[1]),
+		Priority:    priority,
+		Description: strings.TrimSpace(match[3]),
+	}
+}
+
+// offerTicketDraft remembers the model's suggestion for the user who asked
+// and returns the button row to attach to the answer.
+func (b *Bot) offerTicketDraft(ctx context.Context, asker chatUser, draft *database.Ticket) []tgbotapi.InlineKeyboardButton {
+	b.ticketsMu.Lock()
+	b.ticketDrafts[asker] = draft
+	b.ticketsMu.Unlock()
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"chat_id":   asker.chatID,
+		"user_id":   asker.userID,
+		"equipment": draft.Equipment,
+	}).Info("🛠 Ticket suggested by AI")
+
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, asker.chatID, "ticket.create_button"), callbackTicketDraft),
+	)
+}
+
+// takeTicketDraft removes and returns the user's ticket suggestion. When the
+// user has none, others reports whether another member of the chat has one.
+func (b *Bot) takeTicketDraft(key chatUser) (draft *database.Ticket, others bool) {
+	b.ticketsMu.Lock()
+	defer b.ticketsMu.Unlock()
+
+	draft, ok := b.ticketDrafts[key]
+	if ok {
+		delete(b.ticketDrafts, key)
+		return draft, false
+	}
+	for k := range b.ticketDrafts {
+		if k.chatID == key.chatID {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// Ticket dialog steps
+const (
+	ticketStepConfirm = iota
+	ticketStepEquipment
+	ticketStepDescription
+	ticketStepPhotos
+	ticketStepPriority
+)
+
+// ticketDialog collects a new maintenance ticket, optionally starting from an AI draft.
+type ticketDialog struct {
+	b      *Bot
+	chatID int64
+	step   int
+	ticket database.Ticket
+	draft  bool
+}
+
+func newTicketDialog(b *Bot, chatID int64, from *tgbotapi.User, draft *database.Ticket) *ticketDialog {
+	d := &ticketDialog{b: b, chatID: chatID}
+	if draft != nil {
+		d.ticket = *draft
+		d.draft = true
+	}
+	d.ticket.ReporterID = from.ID
+	d.ticket.ReporterName = displayName(from)
+	d.ticket.ChatID = chatID
+	return d
+}
+
+// displayName renders a Telegram user as "First Last (@username)".
+func displayName(user *tgbotapi.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if user.UserName != "" {
+		name += " (@" + user.UserName + ")"
+	}
+	return name
+}
+
+func (d *ticketDialog) start(ctx context.Context) {
+	l := d.b.locale(ctx, d.chatID)
+	if d.draft {
+		d.step = ticketStepConfirm
+		fields := fmt.Sprintf("%s: %s\n%s: %s\n%s: %s",
+			i18n.T(l, "field.equipment"), d.ticket.Equipment,
+			i18n.T(l, "field.priority"), choiceLabel(l, ticketPriorities, d.ticket.Priority),
+			i18n.T(l, "field.description"), d.ticket.Description)
+		d.b.sendPlainMessageWithMarkup(ctx, d.chatID, i18n.T(l, "ticket.draft", fields),
+			tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "ticket.button.create"), callbackDialog+"confirm"),
+					tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "ticket.button.photos"), callbackDialog+"photos"),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, "ticket.button.start_over"), callbackDialog+"edit"),
+				),
+			))
+		return
+	}
+
+	d.step = ticketStepEquipment
+	d.b.sendMessage(ctx, d.chatID, i18n.T(l, "ticket.start"))
+}
+
+func (d *ticketDialog) handleMessage(ctx context.Context, message *tgbotapi.Message) bool {
+	text := strings.TrimSpace(message.Text)
+
+	switch d.step {
+	case ticketStepConfirm:
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.use_buttons"))
+
+	case ticketStepEquipment:
+		if text == "" {
+			d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.type_equipment"))
+			return false
+		}
+		d.ticket.Equipment = text
+		d.step = ticketStepDescription
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.step_description"))
+
+	case ticketStepDescription:
+		if text == "" {
+			text = strings.TrimSpace(message.Caption)
+		}
+		if text == "" {
+			d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.describe"))
+			return false
+		}
+		d.ticket.Description = text
+		if len(message.Photo) > 0 {
+			d.addPhoto(ctx, message)
+		}
+		d.askPhotos(ctx)
+
+	case ticketStepPhotos:
+		if len(message.Photo) == 0 {
+			d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "photos.send_or_done"))
+			return false
+		}
+		d.addPhoto(ctx, message)
+
+	case ticketStepPriority:
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.choose_priority"))
+	}
+
+	return false
+}
+
+func (d *ticketDialog) askPhotos(ctx context.Context) {
+	d.step = ticketStepPhotos
+	d.b.sendMessageWithMarkup(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.step_photos"), photoButtons(d.b.locale(ctx, d.chatID)))
+}
+
+func (d *ticketDialog) addPhoto(ctx context.Context, message *tgbotapi.Message) {
+	if len(d.ticket.PhotoFileIDs) >= maxTicketPhotos {
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "photos.limit", maxTicketPhotos))
+		return
+	}
+	d.ticket.PhotoFileIDs = append(d.ticket.PhotoFileIDs, message.Photo[len(message.Photo)-1].FileID)
+	d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "photos.added", len(d.ticket.PhotoFileIDs)))
+}
+
+func (d *ticketDialog) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery, data string) bool {
+	d.b.answerCallback(ctx, query.ID, "")
+
+	switch {
+	case d.step == ticketStepConfirm && data == "confirm":
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		d.submit(ctx)
+		return true
+
+	case d.step == ticketStepConfirm && data == "photos":
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		d.askPhotos(ctx)
+
+	case d.step == ticketStepConfirm && data == "edit":
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		d.draft = false
+		d.start(ctx)
+
+	case d.step == ticketStepPhotos && data == "photos_done":
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		if d.draft {
+			// The AI draft already carries a priority
+			d.submit(ctx)
+			return true
+		}
+		d.step = ticketStepPriority
+		d.b.sendMessageWithMarkup(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.step_priority"),
+			choiceKeyboard(d.b.locale(ctx, d.chatID), ticketPriorities, callbackDialog+"prio:"))
+
+	case d.step == ticketStepPriority && strings.HasPrefix(data, "prio:"):
+		key := strings.TrimPrefix(data, "prio:")
+		if !isChoice(ticketPriorities, key) {
+			return false
+		}
+		d.ticket.Priority = key
+		d.b.removeKeyboard(ctx, d.chatID, query.Message.MessageID)
+		d.submit(ctx)
+		return true
+	}
+
+	return false
+}
+
+func (d *ticketDialog) submit(ctx context.Context) {
+	if err := d.b.db.CreateTicket(ctx, &d.ticket); err != nil {
+		d.b.sendMessage(ctx, d.chatID, d.b.t(ctx, d.chatID, "ticket.create_failed"))
+		return
+	}
+
+	l := d.b.locale(ctx, d.chatID)
+	d.b.sendPlainMessageWithMarkup(ctx, d.chatID,
+		i18n.T(l, "ticket.created", d.ticket.Number(), formatTicket(l, &d.ticket)),
+		ticketStatusKeyboard(l, &d.ticket))
+}
+
+func formatTicket(l i18n.Locale, t *database.Ticket) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "🛠 %s\n", t.Number())
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.equipment"), t.Equipment)
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.priority"), choiceLabel(l, ticketPriorities, t.Priority))
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.status"), choiceLabel(l, ticketStatuses, t.Status))
+	fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.author"), t.ReporterName)
+	if t.AssigneeName != "" {
+		fmt.Fprintf(&text, "%s: %s\n", i18n.T(l, "field.assignee"), t.AssigneeName)
+	}
+	if len(t.PhotoFileIDs) > 0 {
+		fmt.Fprintf(&text, "%s: %d\n", i18n.T(l, "field.photos"), len(t.PhotoFileIDs))
+	}
+	fmt.Fprintf(&text, "\n%s", t.Description)
+	return text.String()
+}
+
+// ticketStatusKeyboard offers the statuses the ticket can move to.
+func ticketStatusKeyboard(l i18n.Locale, t *database.Ticket) tgbotapi.InlineKeyboardMarkup {
+	id := strconv.FormatInt(t.ID, 10)
+	var row []tgbotapi.InlineKeyboardButton
+	for _, s := range ticketStatuses {
+		if s.Key == t.Status {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(l, s.Label), callbackTicketStatus+id+":"+s.Key))
+	}
+
+	// Two buttons per row keeps the labels readable on phones
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(row); i += 2 {
+		end := i + 2
+		if end > len(row) {
+			end = len(row)
+		}
+		rows = append(rows, row[i:end])
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// canManageTicket reports whether the user may change the ticket's status.
+func (b *Bot) canManageTicket(t *database.Ticket, userID int64) bool {
+	return b.config().IsAdmin(userID) || t.ReporterID == userID || t.AssigneeID == userID
+}
+
+func (b *Bot) handleTicketCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	chatID := query.Message.Chat.ID
+
+	if query.Data == callbackTicketDraft {
+		// Only the user who asked may turn the suggestion into their ticket
+		key := chatUser{chatID, query.From.ID}
+		draft, others := b.takeTicketDraft(key)
+		switch {
+		case draft == nil && others:
+			b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "dialog.not_yours"))
+			return
+		case draft == nil:
+			b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "ticket.suggestion_expired"))
+			return
+		}
+		b.answerCallback(ctx, query.ID, "")
+		b.startDialog(ctx, key, newTicketDialog(b, chatID, query.From, draft))
+		return
+	}
+
+	if !strings.HasPrefix(query.Data, callbackTicketStatus) {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	parts := strings.SplitN(strings.TrimPrefix(query.Data, callbackTicketStatus), ":", 2)
+	if len(parts) != 2 || !isChoice(ticketStatuses, parts[1]) {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	t, err := b.setTicketStatus(ctx, id, parts[1], "", query.From)
+	if err == errNotAllowed {
+		b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "not_allowed"))
+		return
+	}
+	if err != nil || t == nil {
+		b.answerCallback(ctx, query.ID, b.t(ctx, query.From.ID, "error.status_update"))
+		return
+	}
+	b.answerCallback(ctx, query.ID, choiceLabel(b.locale(ctx, query.From.ID), ticketStatuses, t.Status))
+
+	l := b.locale(ctx, chatID)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID, formatTicket(l, t), ticketStatusKeyboard(l, t))
+	if err := b.sender.Request(ctx, chatID, edit); err != nil {
+		logging.From(ctx).WithError(err).WithField("ticket_id", id).Debug("Failed to refresh ticket message")
+	}
+}
+
+// setTicketStatus checks permissions, updates the status and notifies the
+// reporter and assignee. It returns nil without error if the ticket does not exist.
+func (b *Bot) setTicketStatus(ctx context.Context, id int64, status, note string, author *tgbotapi.User) (*database.Ticket, error) {
+	t, err := b.db.GetTicket(ctx, id)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	if !b.canManageTicket(t, author.ID) {
+		return nil, errNotAllowed
+	}
+
+	if err := b.db.UpdateTicketStatus(ctx, id, status, note, author.ID); err != nil {
+		return nil, err
+	}
+	t.Status = status
+
+	b.notifyTicketParticipants(ctx, t, author.ID, func(l i18n.Locale) string {
+		notice := i18n.T(l, "ticket.status_changed", t.Number(), t.Equipment, choiceLabel(l, ticketStatuses, status))
+		if note != "" {
+			notice += "\n" + i18n.T(l, "comment", note)
+		}
+		return notice
+	})
+
+	return t, nil
+}
+
+// notifyTicketParticipants messages the reporter and assignee, skipping the
+// author of the change. notice renders the message in each recipient's language.
+func (b *Bot) notifyTicketParticipants(ctx context.Context, t *database.Ticket, authorID int64, notice func(l i18n.Locale) string) {
+	notified := map[int64]bool{authorID: true}
+	for _, chatID := range []int64{t.ChatID, t.AssigneeID} {
+		if chatID == 0 || notified[chatID] {
+			continue
+		}
+		notified[chatID] = true
+		b.sendPlainMessage(ctx, chatID, notice(b.locale(ctx, chatID)))
+	}
+}
+
+func (b *Bot) cmdTicket(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	usage := b.t(ctx, chatID, "ticket.usage")
+
+	if len(args) == 0 {
+		b.sendPlainMessage(ctx, chatID, usage)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "new":
+		b.startDialog(ctx, chatUser{chatID, message.From.ID}, newTicketDialog(b, chatID, message.From, nil))
+		logging.From(ctx).WithField("user_id", message.From.ID).Info("🛠 Ticket creation started")
+
+	case "list":
+		b.listTickets(ctx, message, args[1:])
+
+	case "show":
+		if len(args) < 2 {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		b.showTicket(ctx, chatID, args[1])
+
+	case "assign":
+		if len(args) < 3 {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		b.assignTicket(ctx, message, args[1], args[2])
+
+	case "close":
+		if len(args) < 2 {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		id, ok := database.ParseTicketNumber(args[1])
+		if !ok {
+			b.sendPlainMessage(ctx, chatID, usage)
+			return
+		}
+		t, err := b.setTicketStatus(ctx, id, database.TicketClosed, strings.Join(args[2:], " "), message.From)
+		switch {
+		case err == errNotAllowed:
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.close_not_allowed"))
+		case err != nil:
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.update_failed"))
+		case t == nil:
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.not_found"))
+		default:
+			b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.closed", t.Number()))
+		}
+
+	default:
+		b.sendPlainMessage(ctx, chatID, usage)
+	}
+}
+
+func (b *Bot) listTickets(ctx context.Context, message *tgbotapi.Message, args []string) {
+	chatID := message.Chat.ID
+	includeClosed := false
+	var userID int64
+	for _, arg := range args {
+		switch strings.ToLower(arg) {
+		case "all":
+			includeClosed = true
+		case "mine":
+			userID = message.From.ID
+		}
+	}
+
+	tickets, err := b.db.ListTickets(ctx, includeClosed, userID, 20)
+	if err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.list_failed"))
+		return
+	}
+	if len(tickets) == 0 {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.none"))
+		return
+	}
+
+	l := b.locale(ctx, chatID)
+	var text strings.Builder
+	text.WriteString(i18n.T(l, "ticket.list_title") + "\n\n")
+	for _, t := range tickets {
+		fmt.Fprintf(&text, "%s · %s · %s\n%s — %s\n\n", t.Number(),
+			choiceLabel(l, ticketStatuses, t.Status), choiceLabel(l, ticketPriorities, t.Priority),
+			t.Equipment, truncateText(t.Description, 80))
+	}
+	b.sendPlainMessage(ctx, chatID, text.String())
+}
+
+func (b *Bot) showTicket(ctx context.Context, chatID int64, number string) {
+	id, ok := database.ParseTicketNumber(number)
+	if !ok {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.invalid_number"))
+		return
+	}
+
+	t, err := b.db.GetTicket(ctx, id)
+	if err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.load_failed"))
+		return
+	}
+	if t == nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.not_found"))
+		return
+	}
+
+	history, err := b.db.GetTicketHistory(ctx, id)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("ticket_id", id).Warn("⚠️ Failed to load ticket history")
+	}
+
+	l := b.locale(ctx, chatID)
+	var text strings.Builder
+	text.WriteString(formatTicket(l, t))
+	if len(history) > 0 {
+		text.WriteString("\n\n" + i18n.T(l, "history") + "\n")
+	}
+	for _, e := range history {
+		label := choiceLabel(l, ticketStatuses, e.Status)
+		if e.Status == "assigned" {
+			label = i18n.T(l, "ticket.status.assigned")
+		}
+		fmt.Fprintf(&text, "• %s — %s", e.CreatedAt.Format("02.01.2006 15:04"), label)
+		if e.Note != "" {
+			fmt.Fprintf(&text, " (%s)", e.Note)
+		}
+		text.WriteString("\n")
+	}
+
+	if len(t.PhotoFileIDs) > 0 {
+		var media []interface{}
+		for _, fileID := range t.PhotoFileIDs {
+			media = append(media, tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(fileID)))
+		}
+		if _, err := b.sender.SendMediaGroup(ctx, chatID, tgbotapi.NewMediaGroup(chatID, media)); err != nil {
+			logging.From(ctx).WithError(err).WithField("ticket_id", id).Warn("⚠️ Failed to send ticket photos")
+		}
+	}
+
+	b.sendPlainMessageWithMarkup(ctx, chatID, text.String(), ticketStatusKeyboard(l, t))
+}
+
+func (b *Bot) assignTicket(ctx context.Context, message *tgbotapi.Message, number, assignee string) {
+	chatID := message.Chat.ID
+	if !b.config().IsAdmin(message.From.ID) {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.assign_admin_only"))
+		return
+	}
+
+	id, ok := database.ParseTicketNumber(number)
+	if !ok {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.invalid_number"))
+		return
+	}
+
+	t, err := b.db.GetTicket(ctx, id)
+	if err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.load_failed"))
+		return
+	}
+	if t == nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.not_found"))
+		return
+	}
+
+	user, ok := b.lookupUser(ctx, chatID, assignee)
+	if !ok {
+		return
+	}
+	name := userName(user)
+
+	if err := b.db.AssignTicket(ctx, id, user.ID, name, message.From.ID); err != nil {
+		b.sendMessage(ctx, chatID, b.t(ctx, chatID, "ticket.assign_failed"))
+		return
+	}
+	t.AssigneeID, t.AssigneeName = user.ID, name
+
+	b.sendPlainMessage(ctx, chatID, fmt.Sprintf("✅ %s → %s", t.Number(), name))
+	b.notifyTicketParticipants(ctx, t, message.From.ID, func(l i18n.Locale) string {
+		return i18n.T(l, "ticket.assigned", t.Number(), name, formatTicket(l, t))
+	})
+}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultWebhookPath is served when WEBHOOK_URL is not set (local testing).
+const defaultWebhookPath = "/telegram/webhook"
+
+// secretTokenHeader carries the secret_token given to setWebhook.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// webhookShutdownTimeout bounds waiting for webhook requests being read on shutdown.
+const webhookShutdownTimeout = 5 * time.Second
+
+// maxUpdateSize bounds the request body of a single webhook update.
+const maxUpdateSize = 1 << 20
+
+// webhookPath returns the HTTP path updates are posted to.
+func (b *Bot) webhookPath() (string, error) {
+	if b.config().WebhookURL == "" {
+		return defaultWebhookPath, nil
+	}
+	u, err := url.Parse(b.config().WebhookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid WEBHOOK_URL: %w", err)
+	}
+	if u.Path == "" {
+		return "/", nil
+	}
+	return u.Path, nil
+}
+
+// setWebhook registers the webhook with Telegram. The library's WebhookConfig
+// predates secret_token, so the request is built by hand.
+func (b *Bot) setWebhook() error {
+	params := tgbotapi.Params{"url": b.config().WebhookURL}
+	params.AddNonEmpty("secret_token", b.config().WebhookSecret)
+	if err := params.AddInterface("allowed_updates", []string{"message", "callback_query"}); err != nil {
+		return err
+	}
+
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("setWebhook failed: %w", err)
+	}
+
+	logrus.WithField("url", b.config().WebhookURL).Info("🔗 Webhook registered")
+	return nil
+}
+
+// deleteWebhook removes any registered webhook; Telegram rejects getUpdates while one is set.
+func (b *Bot) deleteWebhook() error {
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("deleteWebhook failed: %w", err)
+	}
+	logrus.Info("🔗 Webhook removed")
+	return nil
+}
+
+// webhookHandler accepts updates POSTed by Telegram (or by hand, for local
+// testing) and dispatches them to the same handlers as long polling.
+func (b *Bot) webhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if b.config().WebhookSecret != "" {
+			token := r.Header.Get(secretTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(b.config().WebhookSecret)) != 1 {
+				logrus.WithField("remote_addr", r.RemoteAddr).Warn("⛔ Webhook request with invalid secret token")
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+			logrus.WithError(err).Warn("⚠️ Invalid webhook update")
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+
+		logrus.WithField("update_id", update.UpdateID).Debug("📥 Webhook update received")
+
+		// Reply immediately; Telegram retries updates that take too long
+		w.WriteHeader(http.StatusOK)
+		b.dispatchUpdate(update)
+	})
+}
+
+// serveWebhook registers the webhook and serves updates until ctx is
+// cancelled or the server fails. The webhook stays registered on shutdown so
+// Telegram queues updates for the next start (or other replicas).
+func (b *Bot) serveWebhook(ctx context.Context) error {
+	path, err := b.webhookPath()
+	if err != nil {
+		return err
+	}
+
+	if b.config().WebhookURL != "" {
+		if err := b.setWebhook(); err != nil {
+			return err
+		}
+	} else {
+		logrus.Warn("⚠️ WEBHOOK_URL not set: webhook not registered, accepting local POSTs only")
+	}
+	if b.config().WebhookSecret == "" {
+		logrus.Warn("⚠️ WEBHOOK_SECRET not set: webhook requests are not authenticated")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, b.webhookHandler())
+	server := &http.Server{
+		Addr:    b.config().WebhookListen,
+		Handler: mux,
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"listen": b.config().WebhookListen,
+		"path":   path,
+		"tls":    b.config().WebhookCertFile != "",
+	}).Info("🌐 Webhook server listening")
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if b.config().WebhookCertFile != "" {
+			serveErr <- server.ListenAndServeTLS(b.config().WebhookCertFile, b.config().WebhookKeyFile)
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("webhook server: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Warn("⚠️ Webhook server did not shut down cleanly")
+	}
+
+	logrus.Info("🛑 Stopped receiving updates")
+	return nil
+}
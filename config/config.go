@@ -1,29 +1,572 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the runtime image may lack a zoneinfo database
+
+	"factory_bot/i18n"
+	"factory_bot/instructions"
+	"factory_bot/logging"
+	"factory_bot/tracing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultFile is read when CONFIG_FILE is not set; it may be absent.
+const DefaultFile = "config.toml"
 
 type Config struct {
-	OpenRouterKey string
-	BotToken      string
-	TextModel     string
-	VisionModel   string
+	OpenRouterKey   string
+	BotToken        string
+	TextModel       string
+	VisionModel     string
+	MaxPromptImages int
+	AdminIDs        []int64
+	SafetyChatID    int64 // chat receiving incident reports
+
+	DatabasePath string
+
+	// Prompt sizes and answer limits
+	HistoryLimit    int    // stored messages loaded into every prompt
+	TextMaxTokens   int    // answer limit for text-only prompts
+	VisionMaxTokens int    // answer limit for prompts with images
+	ReportMaxTokens int    // answer limit for shift reports
+	ImageDetail     string // "low", "high" or "auto"
+
+	// System prompts published to the prompt table when they change; default
+	// to the instructions package
+	Prompts Prompts
+
+	// Assistant personas chosen with /mode; users without a choice or a
+	// department mode get DefaultMode
+	Modes       []Mode
+	DefaultMode string
+
+	// Models users may pick with /model instead of the mode's model
+	Models []ModelOption
+
+	Location          *time.Location // plant time zone
+	Shifts            []Shift
+	ShiftReportChatID int64 // chat receiving end-of-shift reports
+
+	// Update delivery: "polling" (default) or "webhook"
+	Mode            string
+	WebhookURL      string // public URL registered with Telegram; empty skips setWebhook
+	WebhookListen   string // local address of the webhook HTTP server
+	WebhookSecret   string // checked against X-Telegram-Bot-Api-Secret-Token
+	WebhookCertFile string // optional TLS certificate; plain HTTP behind a proxy otherwise
+	WebhookKeyFile  string
+
+	// Number the parts of split messages, e.g. "(1/3)"
+	PartMarkers bool
+
+	// Language for group chats and users whose Telegram language is unknown
+	DefaultLanguage i18n.Locale
+
+	PlantName            string // shown in document headers and prompts
+	AnswerDocumentLength int    // answers longer than this are sent as DOCX; 0 disables
+
+	// Prompt context: site location and the equipment in service
+	PlantLocation string
+	Equipment     []string
+
+	// Update handling: worker pool size and queue limits
+	Workers        int
+	ChatQueueLimit int // unprocessed updates per chat
+	QueueLimit     int // unprocessed updates in total
+
+	// How long in-flight requests may run after SIGTERM before being cancelled
+	ShutdownGracePeriod time.Duration
+
+	// Port of the HTTP server exposing /metrics, /healthz and /readyz; 0 disables it
+	MetricsPort int
+	// /readyz fails when getUpdates has not succeeded for this long
+	UpdatesThreshold time.Duration
+
+	LogLevel  string // logrus level name, e.g. "info" or "debug"
+	LogFormat string // "text" or "json"
+
+	// Span export: "none" (default), "otlp" or "stdout"
+	TracingExporter    string
+	TracingEndpoint    string // OTLP/HTTP collector base URL
+	TracingServiceName string // service.name reported with every span
+
+	File   string              // config file that was read; empty if none
+	values map[string]string   // resolved setting values, compared on Reload
+	lists  map[string][]string // elements of list settings, applied on Reload
 }
 
-func Load() *Config {
-	textModel := os.Getenv("TEXT_MODEL")
-	if textModel == "" {
-		textModel = "google/gemini-2.5-pro"
+// Prompts are the system prompts sent to the model.
+type Prompts struct {
+	Main             string
+	Image            string
+	TicketSuggestion string
+	ShiftReport      string
+}
+
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+// setting is a configuration value with its config file key, environment
+// variable and default. Environment variables override the file.
+type setting struct {
+	key    string // "table.name" in the config file
+	env    string // empty for file-only settings
+	kind   valueKind
+	def    string
+	reload bool // applied by Reload without a restart
+	apply  func(c *Config, value string) error
+	// applyList replaces apply for kindList settings. Environment variables
+	// and defaults are split on commas; file arrays are passed as they are.
+	applyList func(c *Config, items []string) error
+}
+
+// set applies a resolved value to c and records it for Reload.
+func (s setting) set(c *Config, value string, items []string) error {
+	if s.kind != kindList {
+		c.values[s.key] = value
+		return s.apply(c, value)
+	}
+
+	// Quoting keeps ["a, b"] and ["a", "b"] apart when Reload compares values
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	c.values[s.key] = strings.Join(quoted, ",")
+	c.lists[s.key] = items
+	return s.applyList(c, items)
+}
+
+var settings = []setting{
+	// Telegram
+	{key: "telegram.bot_token", env: "BOT_TOKEN", apply: func(c *Config, v string) error {
+		c.BotToken = v
+		return required(v)
+	}},
+	{key: "telegram.mode", env: "BOT_MODE", def: ModePolling, apply: func(c *Config, v string) error {
+		c.Mode = strings.ToLower(v)
+		if c.Mode != ModePolling && c.Mode != ModeWebhook {
+			return fmt.Errorf("must be %q or %q", ModePolling, ModeWebhook)
+		}
+		return nil
+	}},
+	{key: "telegram.webhook_url", env: "WEBHOOK_URL", apply: func(c *Config, v string) error {
+		c.WebhookURL = v
+		return nil
+	}},
+	{key: "telegram.webhook_listen", env: "WEBHOOK_LISTEN", def: ":8443", apply: func(c *Config, v string) error {
+		c.WebhookListen = v
+		return required(v)
+	}},
+	{key: "telegram.webhook_secret", env: "WEBHOOK_SECRET", apply: func(c *Config, v string) error {
+		c.WebhookSecret = v
+		return nil
+	}},
+	{key: "telegram.webhook_cert_file", env: "WEBHOOK_CERT_FILE", apply: func(c *Config, v string) error {
+		c.WebhookCertFile = v
+		return nil
+	}},
+	{key: "telegram.webhook_key_file", env: "WEBHOOK_KEY_FILE", apply: func(c *Config, v string) error {
+		c.WebhookKeyFile = v
+		return nil
+	}},
+	{key: "telegram.admin_ids", env: "ADMIN_IDS", kind: kindList, reload: true, applyList: func(c *Config, v []string) (err error) {
+		c.AdminIDs, err = parseIDs(v)
+		return err
+	}},
+	{key: "telegram.safety_chat_id", env: "SAFETY_CHAT_ID", kind: kindInt, def: "0", apply: func(c *Config, v string) (err error) {
+		c.SafetyChatID, err = parseID(v)
+		return err
+	}},
+	{key: "telegram.shift_report_chat_id", env: "SHIFT_REPORT_CHAT_ID", kind: kindInt, def: "0", apply: func(c *Config, v string) (err error) {
+		c.ShiftReportChatID, err = parseID(v)
+		return err
+	}},
+	{key: "telegram.part_markers", env: "MESSAGE_PART_MARKERS", kind: kindBool, def: "true", reload: true, apply: func(c *Config, v string) (err error) {
+		c.PartMarkers, err = parseBool(v)
+		return err
+	}},
+
+	// AI
+	{key: "ai.openrouter_key", env: "OPENROUTER_KEY", apply: func(c *Config, v string) error {
+		c.OpenRouterKey = v
+		return required(v)
+	}},
+	{key: "ai.text_model", env: "TEXT_MODEL", def: "google/gemini-2.5-pro", reload: true, apply: func(c *Config, v string) error {
+		c.TextModel = v
+		return required(v)
+	}},
+	{key: "ai.vision_model", env: "VISION_MODEL", def: "google/gemini-2.5-pro", reload: true, apply: func(c *Config, v string) error {
+		c.VisionModel = v
+		return required(v)
+	}},
+	// Maximum number of images (current photo included) sent in a single prompt
+	{key: "ai.max_prompt_images", env: "MAX_PROMPT_IMAGES", kind: kindInt, def: "3", reload: true, apply: func(c *Config, v string) (err error) {
+		c.MaxPromptImages, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "ai.history_limit", env: "HISTORY_LIMIT", kind: kindInt, def: "20", reload: true, apply: func(c *Config, v string) (err error) {
+		c.HistoryLimit, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "ai.text_max_tokens", env: "TEXT_MAX_TOKENS", kind: kindInt, def: "1024", reload: true, apply: func(c *Config, v string) (err error) {
+		c.TextMaxTokens, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "ai.vision_max_tokens", env: "VISION_MAX_TOKENS", kind: kindInt, def: "1500", reload: true, apply: func(c *Config, v string) (err error) {
+		c.VisionMaxTokens, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "ai.report_max_tokens", env: "REPORT_MAX_TOKENS", kind: kindInt, def: "2048", reload: true, apply: func(c *Config, v string) (err error) {
+		c.ReportMaxTokens, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "ai.image_detail", env: "IMAGE_DETAIL", def: "low", reload: true, apply: func(c *Config, v string) error {
+		c.ImageDetail = strings.ToLower(v)
+		switch c.ImageDetail {
+		case "low", "high", "auto":
+			return nil
+		}
+		return fmt.Errorf("must be low, high or auto")
+	}},
+
+	{key: "ai.default_mode", env: "DEFAULT_MODE", def: ModeGeneral, reload: true, apply: func(c *Config, v string) error {
+		c.DefaultMode = v
+		return required(v)
+	}},
+
+	// Prompts
+	{key: "prompts.main", def: instructions.MainInstructions, reload: true, apply: func(c *Config, v string) error {
+		c.Prompts.Main = v
+		return prompt(v)
+	}},
+	{key: "prompts.image", def: instructions.ImageInstruction, reload: true, apply: func(c *Config, v string) error {
+		c.Prompts.Image = v
+		return prompt(v)
+	}},
+	{key: "prompts.ticket_suggestion", def: instructions.TicketSuggestionInstruction, reload: true, apply: func(c *Config, v string) error {
+		c.Prompts.TicketSuggestion = v
+		return prompt(v)
+	}},
+	{key: "prompts.shift_report", def: instructions.ShiftReportInstruction, reload: true, apply: func(c *Config, v string) error {
+		c.Prompts.ShiftReport = v
+		return prompt(v)
+	}},
+
+	// Plant
+	{key: "plant.name", env: "PLANT_NAME", def: "Sector Prom", reload: true, apply: func(c *Config, v string) error {
+		c.PlantName = v
+		return required(v)
+	}},
+	{key: "plant.location", env: "PLANT_LOCATION", def: "Верхний Тагил, Свердловская область", reload: true, apply: func(c *Config, v string) error {
+		c.PlantLocation = v
+		return nil
+	}},
+	{key: "plant.equipment", env: "PLANT_EQUIPMENT", kind: kindList, reload: true, applyList: func(c *Config, v []string) error {
+		c.Equipment = v
+		return nil
+	}},
+	{key: "plant.timezone", env: "TIMEZONE", def: "Asia/Yekaterinburg", apply: func(c *Config, v string) (err error) {
+		c.Location, err = time.LoadLocation(v)
+		return err
+	}},
+	{key: "plant.shifts", env: "SHIFTS", kind: kindList, def: DefaultShifts, applyList: func(c *Config, v []string) (err error) {
+		c.Shifts, err = ParseShifts(v)
+		return err
+	}},
+	{key: "plant.default_language", env: "DEFAULT_LANGUAGE", def: string(i18n.Russian), apply: func(c *Config, v string) error {
+		l, ok := i18n.Parse(v)
+		if !ok {
+			return fmt.Errorf("unsupported language %q", v)
+		}
+		c.DefaultLanguage = l
+		return nil
+	}},
+
+	// Answers
+	{key: "answers.document_length", env: "ANSWER_DOCUMENT_LENGTH", kind: kindInt, def: "6000", reload: true, apply: func(c *Config, v string) (err error) {
+		c.AnswerDocumentLength, err = parseInt(v, 0)
+		return err
+	}},
+
+	// Processing
+	{key: "processing.workers", env: "WORKERS", kind: kindInt, def: "8", apply: func(c *Config, v string) (err error) {
+		c.Workers, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "processing.chat_queue_limit", env: "CHAT_QUEUE_LIMIT", kind: kindInt, def: "5", apply: func(c *Config, v string) (err error) {
+		c.ChatQueueLimit, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "processing.queue_limit", env: "QUEUE_LIMIT", kind: kindInt, def: "200", apply: func(c *Config, v string) (err error) {
+		c.QueueLimit, err = parseInt(v, 1)
+		return err
+	}},
+	{key: "processing.shutdown_grace_period", env: "SHUTDOWN_GRACE_PERIOD", def: "30s", apply: func(c *Config, v string) (err error) {
+		c.ShutdownGracePeriod, err = time.ParseDuration(v)
+		if err == nil && c.ShutdownGracePeriod < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+		return err
+	}},
+
+	// Monitoring: metrics and health checks
+	{key: "metrics.port", env: "METRICS_PORT", kind: kindInt, def: "9090", apply: func(c *Config, v string) (err error) {
+		c.MetricsPort, err = parseInt(v, 0)
+		if err == nil && c.MetricsPort > 65535 {
+			err = fmt.Errorf("must be at most 65535")
+		}
+		return err
+	}},
+
+	{key: "metrics.updates_threshold", env: "HEALTH_UPDATES_THRESHOLD", def: "3m", reload: true, apply: func(c *Config, v string) (err error) {
+		c.UpdatesThreshold, err = time.ParseDuration(v)
+		if err == nil && c.UpdatesThreshold <= 0 {
+			err = fmt.Errorf("must be positive")
+		}
+		return err
+	}},
+
+	// Logging
+	{key: "log.level", env: "LOG_LEVEL", def: "info", reload: true, apply: func(c *Config, v string) error {
+		c.LogLevel = strings.ToLower(v)
+		_, err := logrus.ParseLevel(c.LogLevel)
+		return err
+	}},
+	{key: "log.format", env: "LOG_FORMAT", def: logging.FormatText, reload: true, apply: func(c *Config, v string) error {
+		c.LogFormat = strings.ToLower(v)
+		if c.LogFormat != logging.FormatText && c.LogFormat != logging.FormatJSON {
+			return fmt.Errorf("must be %q or %q", logging.FormatText, logging.FormatJSON)
+		}
+		return nil
+	}},
+
+	// Tracing
+	{key: "tracing.exporter", env: "TRACING_EXPORTER", def: tracing.ExporterNone, apply: func(c *Config, v string) error {
+		c.TracingExporter = strings.ToLower(v)
+		switch c.TracingExporter {
+		case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+			return nil
+		}
+		return fmt.Errorf("must be %q, %q or %q", tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout)
+	}},
+	{key: "tracing.endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", def: "http://localhost:4318", apply: func(c *Config, v string) error {
+		c.TracingEndpoint = v
+		if !strings.HasPrefix(v, "http://") && !strings.HasPrefix(v, "https://") {
+			return fmt.Errorf("must be an http:// or https:// URL")
+		}
+		return nil
+	}},
+	{key: "tracing.service_name", env: "OTEL_SERVICE_NAME", def: "factory_bot", apply: func(c *Config, v string) error {
+		c.TracingServiceName = v
+		return required(v)
+	}},
+
+	// Database
+	{key: "database.path", env: "DATABASE_PATH", def: "./data/bot.db", apply: func(c *Config, v string) error {
+		c.DatabasePath = v
+		return required(v)
+	}},
+}
+
+// Load reads the config file named by CONFIG_FILE (default config.toml, which
+// may be absent) and applies environment variables on top. All invalid
+// values are reported together.
+func Load() (*Config, error) {
+	path := os.Getenv("CONFIG_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFile
+	}
+
+	file, err := readFile(path, explicit)
+	if err != nil {
+		return nil, err
 	}
 
-	visionModel := os.Getenv("VISION_MODEL")
-	if visionModel == "" {
-		visionModel = "google/gemini-2.5-pro"
+	c := &Config{values: make(map[string]string, len(settings)), lists: make(map[string][]string)}
+	if file != nil {
+		c.File = path
 	}
 
-	return &Config{
-		OpenRouterKey: os.Getenv("OPENROUTER_KEY"),
-		BotToken:      os.Getenv("BOT_TOKEN"),
-		TextModel:     textModel,
-		VisionModel:   visionModel,
+	var problems []string
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.key] = true
+		value, source := s.def, "not set"
+		items := splitList(value)
+
+		if v, ok := file[s.key]; ok {
+			source = fmt.Sprintf("%s:%d", path, v.line)
+			if v.kind != s.kind && !(s.kind == kindList && v.kind == kindString) {
+				problems = append(problems, fmt.Sprintf("%s (%s): expected %s, got %s", s.key, source, s.kind, v.kind))
+				continue
+			}
+			value, items = v.text, v.list()
+		}
+		if s.env != "" {
+			if v := os.Getenv(s.env); v != "" {
+				value, items, source = v, splitList(v), s.env
+			}
+		}
+
+		if err := s.set(c, value, items); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", s.key, source, err))
+		}
+	}
+
+	var modeProblems []string
+	c.Modes, modeProblems = parseModes(path, file)
+	problems = append(problems, modeProblems...)
+	if _, ok := c.FindMode(c.DefaultMode); !ok && c.DefaultMode != "" {
+		problems = append(problems, fmt.Sprintf("ai.default_mode: unknown mode %q", c.DefaultMode))
+	}
+
+	var modelProblems []string
+	c.Models, modelProblems = parseModels(path, file)
+	problems = append(problems, modelProblems...)
+
+	var unknown []string
+	for key := range file {
+		if !known[key] && !strings.HasPrefix(key, "modes.") && !strings.HasPrefix(key, "models.") {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool { return file[unknown[i]].line < file[unknown[j]].line })
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("%s:%d: unknown setting %q", path, file[key].line, key))
+	}
+
+	if (c.WebhookCertFile == "") != (c.WebhookKeyFile == "") {
+		problems = append(problems, "telegram.webhook_cert_file and telegram.webhook_key_file must be set together")
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return c, nil
+}
+
+// Reload loads the configuration again and returns a copy of current with the
+// settings that can change at runtime (models, limits, prompts, modes, the
+// model catalog) updated. changed lists the updated settings; restart lists
+// changed settings that keep their current value until the bot is restarted.
+func Reload(current *Config) (next *Config, changed, restart []string, err error) {
+	fresh, err := Load()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	next = new(Config)
+	*next = *current
+	next.values = make(map[string]string, len(current.values))
+	for key, value := range current.values {
+		next.values[key] = value
+	}
+	next.lists = make(map[string][]string, len(current.lists))
+	for key, items := range current.lists {
+		next.lists[key] = items
+	}
+
+	for _, s := range settings {
+		value := fresh.values[s.key]
+		if value == current.values[s.key] {
+			continue
+		}
+		if !s.reload {
+			restart = append(restart, s.key)
+			continue
+		}
+		// The value was validated by Load
+		_ = s.set(next, value, fresh.lists[s.key])
+		changed = append(changed, s.key)
+	}
+	if !reflect.DeepEqual(fresh.Modes, current.Modes) {
+		next.Modes = fresh.Modes
+		changed = append(changed, "modes")
+	}
+	if !reflect.DeepEqual(fresh.Models, current.Models) {
+		next.Models = fresh.Models
+		changed = append(changed, "models")
+	}
+	return next, changed, restart, nil
+}
+
+// prompt checks a prompt template.
+func prompt(value string) error {
+	if err := required(value); err != nil {
+		return err
+	}
+	return instructions.Validate(value)
+}
+
+func required(value string) error {
+	if value == "" {
+		return fmt.Errorf("is required")
+	}
+	return nil
+}
+
+// parseInt parses an integer of at least min.
+func parseInt(value string, min int) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an integer", value)
+	}
+	if n < min {
+		return 0, fmt.Errorf("must be at least %d", min)
+	}
+	return n, nil
+}
+
+func parseBool(value string) (bool, error) {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("%q is not true or false", value)
+	}
+	return b, nil
+}
+
+// parseIDs parses a list of Telegram user or chat IDs.
+func parseIDs(values []string) ([]int64, error) {
+	var ids []int64
+	for _, part := range values {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		id, err := parseID(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseID parses a single Telegram chat ID.
+func parseID(value string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a Telegram ID", value)
+	}
+	return id, nil
+}
+
+// IsAdmin reports whether the Telegram user is listed in ADMIN_IDS.
+func (c *Config) IsAdmin(userID int64) bool {
+	for _, id := range c.AdminIDs {
+		if id == userID {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,412 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The config file is a TOML subset: [tables], key = value pairs, comments,
+// basic and literal strings (single- and multi-line), integers, floats,
+// booleans and arrays of strings and integers. Dates, inline tables and arrays of tables are not supported.
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindInt
+	kindBool
+	kindFloat
+	kindList // array; a plain string is split on commas like an environment variable
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindInt:
+		return "integer"
+	case kindBool:
+		return "boolean"
+	case kindFloat:
+		return "float"
+	case kindList:
+		return "array"
+	default:
+		return "string"
+	}
+}
+
+// fileValue is a config file entry. text holds a scalar in the form an
+// environment variable would, so both sources go through the same parsing.
+// Arrays keep their elements in items, which may contain commas.
+type fileValue struct {
+	kind  valueKind
+	text  string
+	items []string
+	line  int
+}
+
+// list returns the elements of an array, or of a comma-separated string.
+func (v fileValue) list() []string {
+	if v.kind == kindList {
+		return v.items
+	}
+	return splitList(v.text)
+}
+
+// readFile parses the config file at path. A missing file is an error only
+// when required is set.
+func readFile(path string, required bool) (map[string]fileValue, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values, err := parseFile(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return values, nil
+}
+
+// parseError is reported as "<line>: <message>" after the file name.
+type parseError struct {
+	line int
+	msg  string
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("%d: %s", e.line, e.msg)
+}
+
+type fileParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func parseFile(src string) (map[string]fileValue, error) {
+	p := &fileParser{src: src, line: 1}
+	values := make(map[string]fileValue)
+	table := ""
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return values, nil
+		}
+
+		if p.peek() == '[' {
+			p.pos++
+			p.skipSpace()
+			name, err := p.key()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if p.eof() || p.peek() != ']' {
+				return nil, p.errorf("expected ] after table name")
+			}
+			p.pos++
+			if err := p.endLine(); err != nil {
+				return nil, err
+			}
+			table = name
+			continue
+		}
+
+		line := p.line
+		name, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if table != "" {
+			name = table + "." + name
+		}
+		p.skipSpace()
+		if p.eof() || p.peek() != '=' {
+			return nil, p.errorf("expected = after %q", name)
+		}
+		p.pos++
+		p.skipSpace()
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := values[name]; ok {
+			return nil, &parseError{line, fmt.Sprintf("%q already set on line %d", name, prev.line)}
+		}
+		value.line = line
+		values[name] = value
+
+		if err := p.endLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *fileParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *fileParser) peek() byte { return p.src[p.pos] }
+
+func (p *fileParser) errorf(format string, args ...interface{}) error {
+	return &parseError{p.line, fmt.Sprintf(format, args...)}
+}
+
+// skipSpace skips spaces and tabs.
+func (p *fileParser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *fileParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			p.pos++
+			p.line++
+		case '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endLine accepts trailing spaces and a comment before the end of the line.
+func (p *fileParser) endLine() error {
+	p.skipSpace()
+	if !p.eof() && p.peek() == '#' {
+		for !p.eof() && p.peek() != '\n' {
+			p.pos++
+		}
+	}
+	if !p.eof() && p.peek() == '\r' {
+		p.pos++
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q after value", p.peek())
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+// key reads a bare or dotted key such as "ai" or "ai.text_model".
+func (p *fileParser) key() (string, error) {
+	var parts []string
+	for {
+		start := p.pos
+		for !p.eof() && isBareKeyChar(p.peek()) {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.errorf("expected a key")
+		}
+		parts = append(parts, p.src[start:p.pos])
+		p.skipSpace()
+		if p.eof() || p.peek() != '.' {
+			return strings.Join(parts, "."), nil
+		}
+		p.pos++
+		p.skipSpace()
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *fileParser) value() (fileValue, error) {
+	if p.eof() {
+		return fileValue{}, p.errorf("missing value")
+	}
+
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		s, err := p.str()
+		return fileValue{kind: kindString, text: s}, err
+	case c == '[':
+		return p.array()
+	case strings.HasPrefix(p.src[p.pos:], "true"):
+		p.pos += len("true")
+		return fileValue{kind: kindBool, text: "true"}, nil
+	case strings.HasPrefix(p.src[p.pos:], "false"):
+		p.pos += len("false")
+		return fileValue{kind: kindBool, text: "false"}, nil
+	case c == '+' || c == '-' || c >= '0' && c <= '9':
+		start := p.pos
+		p.pos++
+		float := false
+		for !p.eof() && strings.IndexByte("0123456789_.eE+-", p.peek()) >= 0 {
+			float = float || strings.IndexByte(".eE", p.peek()) >= 0
+			p.pos++
+		}
+		raw := p.src[start:p.pos]
+		if float {
+			f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64)
+			if err != nil {
+				return fileValue{}, p.errorf("invalid number %q", raw)
+			}
+			return fileValue{kind: kindFloat, text: strconv.FormatFloat(f, 'g', -1, 64)}, nil
+		}
+		n, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 10, 64)
+		if err != nil {
+			return fileValue{}, p.errorf("invalid integer %q", raw)
+		}
+		return fileValue{kind: kindInt, text: strconv.FormatInt(n, 10)}, nil
+	default:
+		return fileValue{}, p.errorf("invalid value: strings must be quoted")
+	}
+}
+
+// array reads [a, b, ...] of strings or integers, possibly over several
+// lines.
+func (p *fileParser) array() (fileValue, error) {
+	p.pos++ // [
+	var items []string
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return fileValue{}, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return fileValue{kind: kindList, items: items}, nil
+		}
+
+		item, err := p.value()
+		if err != nil {
+			return fileValue{}, err
+		}
+		if item.kind != kindString && item.kind != kindInt {
+			return fileValue{}, p.errorf("arrays may only hold strings and integers")
+		}
+		items = append(items, item.text)
+
+		p.skipBlank()
+		if !p.eof() && p.peek() == ',' {
+			p.pos++
+		} else if p.eof() || p.peek() != ']' {
+			return fileValue{}, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// str reads a basic ("...") or literal ('...') string, or the multi-line form
+// of either with tripled quotes.
+func (p *fileParser) str() (string, error) {
+	quote := p.peek()
+	multiline := strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(quote), 3))
+	if multiline {
+		p.pos += 3
+		// A newline right after the opening quotes is not part of the string
+		if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+			p.pos += 2
+			p.line++
+		} else if !p.eof() && p.peek() == '\n' {
+			p.pos++
+			p.line++
+		}
+	} else {
+		p.pos++
+	}
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+
+		if c == quote {
+			if !multiline {
+				p.pos++
+				return sb.String(), nil
+			}
+			if strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(quote), 3)) {
+				p.pos += 3
+				return sb.String(), nil
+			}
+		}
+		if c == '\n' {
+			if !multiline {
+				return "", p.errorf("newline in string; use \"\"\" for multi-line text")
+			}
+			p.line++
+		}
+		if c == '\\' && quote == '"' {
+			if err := p.escape(&sb, multiline); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		sb.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *fileParser) escape(sb *strings.Builder, multiline bool) error {
+	p.pos++ // backslash
+	if p.eof() {
+		return p.errorf("unterminated string")
+	}
+
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case '"', '\\':
+		sb.WriteByte(c)
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return p.errorf("invalid \\%c escape", c)
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid \\%c escape", c)
+		}
+		sb.WriteRune(rune(code))
+		p.pos += size
+	case '\n', ' ', '\t', '\r':
+		// A backslash at the end of a line joins it with the next non-blank text
+		if !multiline {
+			return p.errorf("invalid escape \\%q", c)
+		}
+		p.pos--
+		for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+			if p.peek() == '\n' {
+				p.line++
+			}
+			p.pos++
+		}
+	default:
+		return p.errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFileValues(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		key  string
+		want fileValue
+	}{
+		{"basic string", `a = "text"`, "a", fileValue{kind: kindString, text: "text"}},
+		{"literal string keeps backslashes", `a = 'C:\path\n'`, "a", fileValue{kind: kindString, text: `C:\path\n`}},
+		{"escapes", `a = "tab\tquote\" slash\\ \u0416\U0001F600"`, "a", fileValue{kind: kindString, text: "tab\tquote\" slash\\ Ж😀"}},
+		{"hash inside string", `a = "#1" # comment`, "a", fileValue{kind: kindString, text: "#1"}},
+		{"multi-line basic", "a = \"\"\"\nline 1\nline 2\"\"\"", "a", fileValue{kind: kindString, text: "line 1\nline 2"}},
+		{"multi-line line continuation", "a = \"\"\"one \\\n    two\"\"\"", "a", fileValue{kind: kindString, text: "one two"}},
+		{"multi-line literal", "a = '''\nno \\n escapes'''", "a", fileValue{kind: kindString, text: `no \n escapes`}},
+		{"integer with separators", `a = 1_000`, "a", fileValue{kind: kindInt, text: "1000"}},
+		{"negative integer", `a = -42`, "a", fileValue{kind: kindInt, text: "-42"}},
+		{"float", `a = 0.5`, "a", fileValue{kind: kindFloat, text: "0.5"}},
+		{"boolean", `a = false`, "a", fileValue{kind: kindBool, text: "false"}},
+		{"table", "[ai]\ntext_model = \"m\"", "ai.text_model", fileValue{kind: kindString, text: "m"}},
+		{"dotted key", `ai . text_model = "m"`, "ai.text_model", fileValue{kind: kindString, text: "m"}},
+		{"empty array", `a = []`, "a", fileValue{kind: kindList}},
+		{"array items keep commas", `a = ["Пресс КД2330, цех 1", "Станок 16К20"]`, "a",
+			fileValue{kind: kindList, items: []string{"Пресс КД2330, цех 1", "Станок 16К20"}}},
+		{"array of integers", `a = [1, -2]`, "a", fileValue{kind: kindList, items: []string{"1", "-2"}}},
+		{"multi-line array with comments", "a = [\n  \"x\", # first\n  'y',\n]", "a",
+			fileValue{kind: kindList, items: []string{"x", "y"}}},
+		{"CRLF line endings", "# c\r\na = 1\r\nb = 2\r\n", "b", fileValue{kind: kindInt, text: "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := parseFile(tt.src)
+			if err != nil {
+				t.Fatalf("parseFile: %v", err)
+			}
+			got, ok := values[tt.key]
+			if !ok {
+				t.Fatalf("key %q missing, got %v", tt.key, values)
+			}
+			got.line = 0
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFileLines(t *testing.T) {
+	src := "# header\n\n[ai]\nprompt = \"\"\"\nfirst\nsecond\n\"\"\"\nlimit = 3 # trailing\n"
+	values, err := parseFile(src)
+	if err != nil {
+		t.Fatalf("parseFile: %v", err)
+	}
+	if got := values["ai.prompt"].line; got != 4 {
+		t.Errorf("ai.prompt on line %d, want 4", got)
+	}
+	if got := values["ai.limit"].line; got != 8 {
+		t.Errorf("ai.limit on line %d, want 8", got)
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unquoted string", "a = text", "1: invalid value: strings must be quoted"},
+		{"missing equals", "\n\na \"x\"", `3: expected = after "a"`},
+		{"missing value", "a =", "1: missing value"},
+		{"unterminated string", "a = \"open", "1: unterminated string"},
+		{"newline in string", "a = \"one\ntwo\"", "1: newline in string"},
+		{"unterminated multi-line string", "a = \"\"\"\none\ntwo", "3: unterminated string"},
+		{"invalid escape", `a = "\q"`, `1: invalid escape \q`},
+		{"invalid unicode escape", `a = "\u12"`, `1: invalid \u escape`},
+		{"text after value", "a = 1 2", "1: unexpected '2' after value"},
+		{"empty table name", "a = 1\n\n[t]\nx = 1\n[]\n", "5: expected a key"},
+		{"duplicate setting", "a = 1\na = 2", `2: "a" already set on line 1`},
+		{"unclosed table", "[ai\nx = 1", "1: expected ] after table name"},
+		{"unterminated array", "a = [\n\"x\",\n", "3: unterminated array"},
+		{"missing comma", "a = [\"x\" \"y\"]", "1: expected , or ] in array"},
+		{"nested array", "a = [[1]]", "1: arrays may only hold strings and integers"},
+		{"invalid integer", "a = 12ab", "1: unexpected 'a' after value"},
+		{"invalid float", "a = 1.2.3", `1: invalid number "1.2.3"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFile(tt.src)
+			if err == nil {
+				t.Fatalf("parseFile succeeded, want error %q", tt.want)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("error %q, want prefix %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadListsKeepCommas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	src := `[telegram]
+bot_token = "token"
+admin_ids = [1, 2]
+
+[ai]
+openrouter_key = "key"
+
+[plant]
+equipment = ["Пресс КД2330, цех 1", "Станок 16К20"]
+
+[modes.safety]
+name = "Safety"
+departments = ["Цех 1, участок 2"]
+`
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	for _, env := range []string{"BOT_TOKEN", "OPENROUTER_KEY", "ADMIN_IDS", "PLANT_EQUIPMENT"} {
+		t.Setenv(env, "")
+	}
+
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := []string{"Пресс КД2330, цех 1", "Станок 16К20"}; !reflect.DeepEqual(c.Equipment, want) {
+		t.Errorf("Equipment = %q, want %q", c.Equipment, want)
+	}
+	if want := []int64{1, 2}; !reflect.DeepEqual(c.AdminIDs, want) {
+		t.Errorf("AdminIDs = %v, want %v", c.AdminIDs, want)
+	}
+	m, ok := c.FindMode("safety")
+	if !ok {
+		t.Fatal("mode safety missing")
+	}
+	if want := []string{"Цех 1, участок 2"}; !reflect.DeepEqual(m.Departments, want) {
+		t.Errorf("Departments = %q, want %q", m.Departments, want)
+	}
+
+	// An environment variable is still split on commas
+	t.Setenv("PLANT_EQUIPMENT", "Пресс, Станок")
+	c, err = Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := []string{"Пресс", "Станок"}; !reflect.DeepEqual(c.Equipment, want) {
+		t.Errorf("Equipment from environment = %q, want %q", c.Equipment, want)
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Cost tiers of catalog models, cheapest first.
+const (
+	TierLow      = "low"
+	TierStandard = "standard"
+	TierHigh     = "high"
+)
+
+var tiers = []string{TierLow, TierStandard, TierHigh}
+
+// ModelOption is a model users may pick with /model. The catalog is
+// maintained by admins in the [models.<key>] tables of the config file.
+type ModelOption struct {
+	Key        string
+	ID         string // OpenRouter model ID
+	Label      string // shown on the /model buttons
+	Tier       string // TierLow, TierStandard or TierHigh
+	Vision     bool   // also answers prompts with images
+	Tools      bool   // may propose maintenance tickets
+	AdminOnly  bool   // only admins may pick it
+	DailyLimit int    // answers per user and day; 0 is unlimited
+}
+
+// FindModel returns the catalog model with the given key.
+func (c *Config) FindModel(key string) (ModelOption, bool) {
+	for _, m := range c.Models {
+		if m.Key == key {
+			return m, true
+		}
+	}
+	return ModelOption{}, false
+}
+
+// parseModels reads the models.<key>.<field> entries of the config file.
+// Problems are returned as messages for Load.
+func parseModels(path string, file map[string]fileValue) ([]ModelOption, []string) {
+	// Keep the order models were written in
+	var keys []string
+	for key := range file {
+		if strings.HasPrefix(key, "models.") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return file[keys[i]].line < file[keys[j]].line })
+
+	var models []ModelOption
+	var problems []string
+	for _, key := range keys {
+		v := file[key]
+		source := fmt.Sprintf("%s:%d", path, v.line)
+
+		parts := strings.Split(key, ".")
+		if len(parts) != 3 {
+			problems = append(problems, fmt.Sprintf("%s: unknown setting %q", source, key))
+			continue
+		}
+		name, field := parts[1], parts[2]
+
+		i := -1
+		for j := range models {
+			if models[j].Key == name {
+				i = j
+			}
+		}
+		if i < 0 {
+			models = append(models, ModelOption{Key: name, Tier: TierStandard, Tools: true})
+			i = len(models) - 1
+		}
+
+		if err := setModelField(&models[i], field, v); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", key, source, err))
+		}
+	}
+
+	for _, m := range models {
+		if m.ID == "" {
+			problems = append(problems, fmt.Sprintf("models.%s.id: is required", m.Key))
+		}
+		if m.Label == "" {
+			problems = append(problems, fmt.Sprintf("models.%s.label: is required", m.Key))
+		}
+	}
+	return models, problems
+}
+
+func setModelField(m *ModelOption, field string, v fileValue) error {
+	expect := func(kind valueKind) error {
+		if v.kind != kind {
+			return fmt.Errorf("expected %s, got %s", kind, v.kind)
+		}
+		return nil
+	}
+
+	switch field {
+	case "id", "label":
+		if err := expect(kindString); err != nil {
+			return err
+		}
+		if field == "id" {
+			m.ID = v.text
+		} else {
+			m.Label = v.text
+		}
+	case "tier":
+		if err := expect(kindString); err != nil {
+			return err
+		}
+		if !isTier(v.text) {
+			return fmt.Errorf("unknown tier %q, expected one of %s", v.text, strings.Join(tiers, ", "))
+		}
+		m.Tier = v.text
+	case "vision", "tools", "admin_only":
+		if err := expect(kindBool); err != nil {
+			return err
+		}
+		value := v.text == "true"
+		switch field {
+		case "vision":
+			m.Vision = value
+		case "tools":
+			m.Tools = value
+		case "admin_only":
+			m.AdminOnly = value
+		}
+	case "daily_limit":
+		if err := expect(kindInt); err != nil {
+			return err
+		}
+		n, err := parseInt(v.text, 0)
+		if err != nil {
+			return err
+		}
+		m.DailyLimit = n
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+	return nil
+}
+
+func isTier(name string) bool {
+	for _, t := range tiers {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TierRank orders tiers from cheap (0) to expensive.
+func TierRank(tier string) int {
+	for i, t := range tiers {
+		if t == tier {
+			return i
+		}
+	}
+	return len(tiers)
+}
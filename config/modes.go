@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"factory_bot/instructions"
+)
+
+// Tools a mode may allow.
+const (
+	ToolTickets = "tickets" // the model may propose maintenance tickets
+	ToolImages  = "images"  // photos are analyzed with the vision model
+)
+
+var tools = []string{ToolTickets, ToolImages}
+
+// ModeGeneral is the built-in default mode.
+const ModeGeneral = "general"
+
+// Mode is an assistant persona chosen with /mode or by the user's department.
+type Mode struct {
+	Key         string
+	Name        string   // shown to users; built-in modes use the message catalog when empty
+	Prompt      string   // added to the main prompt
+	Model       string   // text model; empty uses ai.text_model
+	VisionModel string   // empty uses ai.vision_model
+	Temperature float32  // 0 uses the model default
+	Tools       []string // ToolTickets, ToolImages
+	Departments []string // departments starting in this mode besides the one named like it
+}
+
+// Allows reports whether the mode may use tool.
+func (m *Mode) Allows(tool string) bool {
+	for _, t := range m.Tools {
+		if t == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinModes are available without configuration. A [modes.<key>] table in
+// the config file overrides their fields or adds a new mode.
+func builtinModes() []Mode {
+	return []Mode{
+		{Key: ModeGeneral, Tools: []string{ToolTickets, ToolImages}},
+		{Key: "maintenance", Prompt: instructions.MaintenanceInstructions, Temperature: 0.3, Tools: []string{ToolTickets, ToolImages}},
+		{Key: "quality", Prompt: instructions.QualityInstructions, Temperature: 0.2, Tools: []string{ToolTickets, ToolImages}},
+		{Key: "safety", Prompt: instructions.SafetyInstructions, Temperature: 0.2, Tools: []string{ToolImages}},
+		{Key: "planning", Prompt: instructions.PlanningInstructions, Temperature: 0.5},
+	}
+}
+
+// FindMode returns the mode with the given key.
+func (c *Config) FindMode(key string) (Mode, bool) {
+	for _, m := range c.Modes {
+		if m.Key == key {
+			return m, true
+		}
+	}
+	return Mode{}, false
+}
+
+// DepartmentMode returns the mode users of department start in: the mode
+// listing it in departments, or the one with the same key.
+func (c *Config) DepartmentMode(department string) (Mode, bool) {
+	department = strings.ToLower(strings.TrimSpace(department))
+	if department == "" {
+		return Mode{}, false
+	}
+	for _, m := range c.Modes {
+		for _, d := range m.Departments {
+			if strings.ToLower(d) == department {
+				return m, true
+			}
+		}
+	}
+	return c.FindMode(department)
+}
+
+// parseModes applies the modes.<key>.<field> entries of the config file to
+// the built-in modes. Problems are returned as messages for Load.
+func parseModes(path string, file map[string]fileValue) ([]Mode, []string) {
+	modes := builtinModes()
+
+	// Apply fields in file order so new modes keep the order they were written in
+	var keys []string
+	for key := range file {
+		if strings.HasPrefix(key, "modes.") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return file[keys[i]].line < file[keys[j]].line })
+
+	var problems []string
+	for _, key := range keys {
+		v := file[key]
+		source := fmt.Sprintf("%s:%d", path, v.line)
+
+		parts := strings.Split(key, ".")
+		if len(parts) != 3 {
+			problems = append(problems, fmt.Sprintf("%s: unknown setting %q", source, key))
+			continue
+		}
+		name, field := parts[1], parts[2]
+
+		i := -1
+		for j := range modes {
+			if modes[j].Key == name {
+				i = j
+			}
+		}
+		if i < 0 {
+			modes = append(modes, Mode{Key: name, Tools: []string{ToolTickets, ToolImages}})
+			i = len(modes) - 1
+		}
+
+		if err := setModeField(&modes[i], field, v); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", key, source, err))
+		}
+	}
+
+	for _, m := range modes {
+		if m.Name == "" && !isBuiltinMode(m.Key) {
+			problems = append(problems, fmt.Sprintf("modes.%s.name: is required for a new mode", m.Key))
+		}
+	}
+	return modes, problems
+}
+
+func setModeField(m *Mode, field string, v fileValue) error {
+	expect := func(kinds ...valueKind) error {
+		for _, k := range kinds {
+			if v.kind == k {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected %s, got %s", kinds[0], v.kind)
+	}
+
+	switch field {
+	case "name", "prompt", "model", "vision_model":
+		if err := expect(kindString); err != nil {
+			return err
+		}
+		switch field {
+		case "name":
+			m.Name = v.text
+		case "prompt":
+			if err := instructions.Validate(v.text); err != nil {
+				return err
+			}
+			m.Prompt = v.text
+		case "model":
+			m.Model = v.text
+		case "vision_model":
+			m.VisionModel = v.text
+		}
+	case "temperature":
+		if err := expect(kindFloat, kindInt); err != nil {
+			return err
+		}
+		t, _ := strconv.ParseFloat(v.text, 32)
+		if t < 0 || t > 2 {
+			return fmt.Errorf("must be between 0 and 2")
+		}
+		m.Temperature = float32(t)
+	case "tools":
+		if err := expect(kindList, kindString); err != nil {
+			return err
+		}
+		m.Tools = nil
+		for _, t := range v.list() {
+			if !isTool(t) {
+				return fmt.Errorf("unknown tool %q, expected one of %s", t, strings.Join(tools, ", "))
+			}
+			m.Tools = append(m.Tools, t)
+		}
+	case "departments":
+		if err := expect(kindList, kindString); err != nil {
+			return err
+		}
+		m.Departments = v.list()
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+	return nil
+}
+
+func isTool(name string) bool {
+	for _, t := range tools {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isBuiltinMode(key string) bool {
+	for _, m := range builtinModes() {
+		if m.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated list, dropping empty elements.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultShifts is the plant's two-shift schedule.
+const DefaultShifts = "08:00-20:00,20:00-08:00"
+
+// Shift is a work shift defined by its start and end time of day. A shift
+// whose end is not after its start runs past midnight.
+type Shift struct {
+	Number int // 1-based position in the schedule
+	Start  time.Duration
+	End    time.Duration
+}
+
+// ParseShifts parses "HH:MM-HH:MM" ranges.
+func ParseShifts(ranges []string) ([]Shift, error) {
+	var shifts []Shift
+	for i, part := range ranges {
+		bounds := strings.Split(strings.TrimSpace(part), "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("shift %q: expected HH:MM-HH:MM", part)
+		}
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("shift %q: %w", part, err)
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, fmt.Errorf("shift %q: %w", part, err)
+		}
+		shifts = append(shifts, Shift{Number: i + 1, Start: start, End: end})
+	}
+	return shifts, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Label renders the shift as "08:00–20:00".
+func (s Shift) Label() string {
+	return fmt.Sprintf("%02d:%02d–%02d:%02d",
+		int(s.Start.Hours()), int(s.Start.Minutes())%60, int(s.End.Hours()), int(s.End.Minutes())%60)
+}
+
+// bounds returns the occurrence of the shift that starts on the given day.
+func (s Shift) bounds(day time.Time) (time.Time, time.Time) {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	start := midnight.Add(s.Start)
+	end := midnight.Add(s.End)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// ShiftAt returns the shift in progress at t and its boundaries. ok is false if
+// no configured shift covers t.
+func (c *Config) ShiftAt(t time.Time) (shift Shift, start, end time.Time, ok bool) {
+	t = t.In(c.Location)
+	for _, dayOffset := range []int{0, -1} {
+		day := t.AddDate(0, 0, dayOffset)
+		for _, s := range c.Shifts {
+			start, end := s.bounds(day)
+			if !t.Before(start) && t.Before(end) {
+				return s, start, end, true
+			}
+		}
+	}
+	return Shift{}, time.Time{}, time.Time{}, false
+}
+
+// NextShiftEnd returns the first shift end strictly after t together with that
+// shift and its start.
+func (c *Config) NextShiftEnd(t time.Time) (shift Shift, start, end time.Time, ok bool) {
+	t = t.In(c.Location)
+	for _, dayOffset := range []int{-1, 0, 1} {
+		day := t.AddDate(0, 0, dayOffset)
+		for _, s := range c.Shifts {
+			sStart, sEnd := s.bounds(day)
+			if sEnd.After(t) && (!ok || sEnd.Before(end)) {
+				shift, start, end, ok = s, sStart, sEnd, true
+			}
+		}
+	}
+	return shift, start, end, ok
+}
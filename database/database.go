@@ -1,32 +1,42 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
+	"factory_bot/logging"
+
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/sirupsen/logrus"
 )
 
 type Database struct {
-	db *sql.DB
+	db instrumentedDB
 }
 
 type Message struct {
-	ID        int64
-	UserID    int64
-	Username  string
-	Text      string
-	Role      string // "user" or "assistant"
-	Timestamp time.Time
+	ID          int64
+	UserID      int64
+	Username    string
+	Text        string
+	Role        string // "user" or "assistant"
+	ImageFileID string // Telegram file ID for image messages, empty otherwise
+	Timestamp   time.Time
 }
 
 type User struct {
-	ID        int64
-	Username  string
-	FirstName string
-	LastName  string
-	CreatedAt time.Time
+	ID           int64
+	Username     string
+	FirstName    string
+	LastName     string
+	LanguageCode string // from the user's Telegram client
+	Language     string // chosen with /lang, empty for automatic
+	Department   string // set by admins with /department
+	Mode         string // chosen with /mode, empty for the department default
+	Model        string // catalog key chosen with /model, empty for the mode's model
+	CreatedAt    time.Time
 }
 
 func New(dbPath string) (*Database, error) {
@@ -35,15 +45,15 @@ func New(dbPath string) (*Database, error) {
 		return nil, err
 	}
 
-	database := &Database{db: db}
-	if err := database.init(); err != nil {
+	database := &Database{db: instrumentedDB{db}}
+	if err := database.init(context.Background()); err != nil {
 		return nil, err
 	}
 
 	return database, nil
 }
 
-func (d *Database) init() error {
+func (d *Database) init(ctx context.Context) error {
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS users (
 			id INTEGER PRIMARY KEY,
@@ -61,10 +71,135 @@ func (d *Database) init() error {
 			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users (id)
 		)`,
+		`CREATE TABLE IF NOT EXISTS answers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			chat_id INTEGER,
+			telegram_message_id INTEGER,
+			model TEXT,
+			prompt_version TEXT,
+			text TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS answer_ratings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			answer_id INTEGER,
+			user_id INTEGER,
+			rating INTEGER,
+			comment TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (answer_id, user_id),
+			FOREIGN KEY (answer_id) REFERENCES answers (id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS incidents (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			reporter_id INTEGER,
+			reporter_name TEXT,
+			chat_id INTEGER,
+			category TEXT,
+			location TEXT,
+			description TEXT,
+			severity TEXT,
+			status TEXT DEFAULT 'new',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS incident_photos (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			incident_id INTEGER,
+			file_id TEXT,
+			FOREIGN KEY (incident_id) REFERENCES incidents (id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS tickets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			equipment TEXT,
+			description TEXT,
+			priority TEXT DEFAULT 'normal',
+			status TEXT DEFAULT 'open',
+			reporter_id INTEGER,
+			reporter_name TEXT,
+			chat_id INTEGER,
+			assignee_id INTEGER DEFAULT 0,
+			assignee_name TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS ticket_photos (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticket_id INTEGER,
+			file_id TEXT,
+			FOREIGN KEY (ticket_id) REFERENCES tickets (id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS ticket_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticket_id INTEGER,
+			status TEXT,
+			note TEXT,
+			author_id INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (ticket_id) REFERENCES tickets (id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS scheduled_jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT,
+			owner_id INTEGER,
+			target TEXT,
+			text TEXT,
+			cron TEXT DEFAULT '',
+			next_run DATETIME,
+			last_run DATETIME,
+			active INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS prompts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			text TEXT,
+			author_id INTEGER DEFAULT 0,
+			author_name TEXT DEFAULT '',
+			active INTEGER DEFAULT 0,
+			activated_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS incident_updates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			incident_id INTEGER,
+			status TEXT,
+			note TEXT,
+			author_id INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (incident_id) REFERENCES incidents (id)
+		)`,
+		// Conversation history is read per user and time on every answer
+		`CREATE INDEX IF NOT EXISTS idx_messages_user_ts ON messages(user_id, timestamp)`,
 	}
 
 	for _, query := range queries {
-		if _, err := d.db.Exec(query); err != nil {
+		if _, err := d.db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return d.migrate(ctx)
+}
+
+// migrate adds columns introduced after the initial schema to existing databases.
+func (d *Database) migrate(ctx context.Context) error {
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"messages", "image_file_id", "TEXT"},
+		{"users", "language_code", "TEXT"},
+		{"users", "language", "TEXT"},
+		{"users", "department", "TEXT"},
+		{"users", "mode", "TEXT"},
+		{"users", "model", "TEXT"},
+		{"scheduled_jobs", "attempts", "INTEGER DEFAULT 0"},
+	}
+
+	for _, c := range columns {
+		if err := d.addColumnIfMissing(ctx, c.table, c.column, c.definition); err != nil {
 			return err
 		}
 	}
@@ -72,31 +207,144 @@ func (d *Database) init() error {
 	return nil
 }
 
-func (d *Database) AddUser(userID int64, username, firstName, lastName string) error {
-	query := `INSERT OR REPLACE INTO users (id, username, first_name, last_name) 
-			  VALUES (?, ?, ?, ?)`
-	_, err := d.db.Exec(query, userID, username, firstName, lastName)
+func (d *Database) addColumnIfMissing(ctx context.Context, table, column, definition string) error {
+	rows, err := d.db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			ctype      string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultVal, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = d.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err == nil {
+		logging.From(ctx).WithFields(logrus.Fields{
+			"table":  table,
+			"column": column,
+		}).Info("🔧 Database: Column added")
+	}
+	return err
+}
+
+// AddUser creates or updates a user from their latest message. The language
+// chosen with /lang is kept.
+func (d *Database) AddUser(ctx context.Context, userID int64, username, firstName, lastName, languageCode string) error {
+	query := `INSERT INTO users (id, username, first_name, last_name, language_code)
+			  VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET
+				username = excluded.username,
+				first_name = excluded.first_name,
+				last_name = excluded.last_name,
+				language_code = excluded.language_code`
+	_, err := d.db.ExecContext(ctx, query, userID, username, firstName, lastName, languageCode)
 	
 	if err != nil {
-		logrus.WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to add/update user")
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to add/update user")
 	} else {
-		logrus.WithField("user_id", userID).Debug("✅ Database: User added/updated")
+		logging.From(ctx).WithField("user_id", userID).Debug("✅ Database: User added/updated")
 	}
 	
 	return err
 }
 
-func (d *Database) SaveMessage(userID int64, username, text, role string) error {
+// SetUserLanguage stores the user's /lang choice; an empty language restores
+// detection from the Telegram client.
+func (d *Database) SetUserLanguage(ctx context.Context, userID int64, language string) error {
+	_, err := d.db.ExecContext(ctx, `UPDATE users SET language = ? WHERE id = ?`, language, userID)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to set user language")
+	}
+	return err
+}
+
+// SetUserMode stores the user's /mode choice; an empty mode restores the
+// department default.
+func (d *Database) SetUserMode(ctx context.Context, userID int64, mode string) error {
+	_, err := d.db.ExecContext(ctx, `UPDATE users SET mode = ? WHERE id = ?`, mode, userID)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to set user mode")
+	}
+	return err
+}
+
+// SetUserModel stores the user's /model choice; an empty key restores the
+// mode's model.
+func (d *Database) SetUserModel(ctx context.Context, userID int64, model string) error {
+	_, err := d.db.ExecContext(ctx, `UPDATE users SET model = ? WHERE id = ?`, model, userID)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to set user model")
+	}
+	return err
+}
+
+// SetUserDepartment stores the user's department; an empty department clears it.
+func (d *Database) SetUserDepartment(ctx context.Context, userID int64, department string) error {
+	_, err := d.db.ExecContext(ctx, `UPDATE users SET department = ? WHERE id = ?`, department, userID)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to set user department")
+	}
+	return err
+}
+
+// FindUserByUsername looks up a user by Telegram username (without "@"). It
+// returns nil if the user has never written to the bot.
+func (d *Database) FindUserByUsername(ctx context.Context, username string) (*User, error) {
+	var user User
+	query := `SELECT id, username, first_name, last_name, COALESCE(language_code, ''), COALESCE(language, ''), COALESCE(department, ''), COALESCE(mode, ''), COALESCE(model, ''), created_at FROM users WHERE username = ? COLLATE NOCASE`
+	err := d.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.LanguageCode, &user.Language, &user.Department, &user.Mode, &user.Model, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("username", username).Error("❌ Database: Failed to find user")
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (d *Database) GetUser(ctx context.Context, userID int64) (*User, error) {
+	var user User
+	query := `SELECT id, username, first_name, last_name, COALESCE(language_code, ''), COALESCE(language, ''), COALESCE(department, ''), COALESCE(mode, ''), COALESCE(model, ''), created_at FROM users WHERE id = ?`
+	err := d.db.QueryRowContext(ctx, query, userID).Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.LanguageCode, &user.Language, &user.Department, &user.Mode, &user.Model, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to get user")
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (d *Database) SaveMessage(ctx context.Context, userID int64, username, text, role string) error {
 	query := `INSERT INTO messages (user_id, username, text, role) VALUES (?, ?, ?, ?)`
-	_, err := d.db.Exec(query, userID, username, text, role)
+	_, err := d.db.ExecContext(ctx, query, userID, username, text, role)
 	
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+		logging.From(ctx).WithError(err).WithFields(logrus.Fields{
 			"user_id": userID,
 			"role":    role,
 		}).Error("❌ Database: Failed to save message")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		logging.From(ctx).WithFields(logrus.Fields{
 			"user_id":    userID,
 			"role":       role,
 			"text_len":   len(text),
@@ -106,21 +354,41 @@ func (d *Database) SaveMessage(userID int64, username, text, role string) error
 	return err
 }
 
-func (d *Database) GetChatHistory(userID int64, limit int) ([]Message, error) {
-	logrus.WithFields(logrus.Fields{
+// SaveImageMessage stores a user image turn so it can be re-sent to the model
+// as part of the conversation history.
+func (d *Database) SaveImageMessage(ctx context.Context, userID int64, username, caption, fileID string) error {
+	query := `INSERT INTO messages (user_id, username, text, role, image_file_id) VALUES (?, ?, ?, 'user', ?)`
+	_, err := d.db.ExecContext(ctx, query, userID, username, caption, fileID)
+
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to save image message")
+	} else {
+		logging.From(ctx).WithFields(logrus.Fields{
+			"user_id":     userID,
+			"caption_len": len(caption),
+		}).Debug("✅ Database: Image message saved")
+	}
+
+	return err
+}
+
+// GetChatHistory returns the user's latest messages stored since the given
+// time, oldest first.
+func (d *Database) GetChatHistory(ctx context.Context, userID int64, since time.Time, limit int) ([]Message, error) {
+	logging.From(ctx).WithFields(logrus.Fields{
 		"user_id": userID,
 		"limit":   limit,
 	}).Debug("📚 Database: Retrieving chat history")
 
-	query := `SELECT id, user_id, username, text, role, timestamp 
+	query := `SELECT id, user_id, username, text, role, COALESCE(image_file_id, ''), timestamp 
 			  FROM messages 
-			  WHERE user_id = ? 
-			  ORDER BY timestamp DESC 
+			  WHERE user_id = ? AND timestamp >= ? 
+			  ORDER BY timestamp DESC, id DESC 
 			  LIMIT ?`
 	
-	rows, err := d.db.Query(query, userID, limit)
+	rows, err := d.db.QueryContext(ctx, query, userID, sqliteTime(since), limit)
 	if err != nil {
-		logrus.WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to get chat history")
+		logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to get chat history")
 		return nil, err
 	}
 	defer rows.Close()
@@ -128,9 +396,9 @@ func (d *Database) GetChatHistory(userID int64, limit int) ([]Message, error) {
 	var messages []Message
 	for rows.Next() {
 		var msg Message
-		err := rows.Scan(&msg.ID, &msg.UserID, &msg.Username, &msg.Text, &msg.Role, &msg.Timestamp)
+		err := rows.Scan(&msg.ID, &msg.UserID, &msg.Username, &msg.Text, &msg.Role, &msg.ImageFileID, &msg.Timestamp)
 		if err != nil {
-			logrus.WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to scan message")
+			logging.From(ctx).WithError(err).WithField("user_id", userID).Error("❌ Database: Failed to scan message")
 			return nil, err
 		}
 		messages = append(messages, msg)
@@ -142,7 +410,7 @@ func (d *Database) GetChatHistory(userID int64, limit int) ([]Message, error) {
 		messages[i], messages[j] = messages[j], messages[i]
 	}
 
-	logrus.WithFields(logrus.Fields{
+	logging.From(ctx).WithFields(logrus.Fields{
 		"user_id":     userID,
 		"found_count": len(messages),
 	}).Debug("✅ Database: Chat history retrieved")
@@ -150,29 +418,45 @@ func (d *Database) GetChatHistory(userID int64, limit int) ([]Message, error) {
 	return messages, nil
 }
 
-func (d *Database) GetDailyStats() (int, error) {
-	query := `SELECT COUNT(*) FROM messages 
-			  WHERE DATE(timestamp) = DATE('now')`
-	var count int
-	err := d.db.QueryRow(query).Scan(&count)
-	return count, err
-}
+// GetMessagesBetween returns all users' messages in [from, to), oldest first.
+func (d *Database) GetMessagesBetween(ctx context.Context, from, to time.Time) ([]Message, error) {
+	query := `SELECT id, user_id, username, text, role, COALESCE(image_file_id, ''), timestamp 
+			  FROM messages 
+			  WHERE timestamp >= ? AND timestamp < ? 
+			  ORDER BY timestamp, id`
 
-func (d *Database) ClearAllChatHistory() error {
-	logrus.Info("🗑️ Database: Clearing all chat history")
-	
-	query := `DELETE FROM messages`
-	_, err := d.db.Exec(query)
-	
+	rows, err := d.db.QueryContext(ctx, query, sqliteTime(from), sqliteTime(to))
 	if err != nil {
-		logrus.WithError(err).Error("❌ Database: Failed to clear chat history")
-	} else {
-		logrus.Info("✅ Database: All chat history cleared")
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to get messages for period")
+		return nil, err
 	}
-	
-	return err
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		err := rows.Scan(&msg.ID, &msg.UserID, &msg.Username, &msg.Text, &msg.Role, &msg.ImageFileID, &msg.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// Ping checks that the database answers a query, which fails while another
+// connection holds it locked.
+func (d *Database) Ping(ctx context.Context) error {
+	var n int
+	return d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM (SELECT 1 FROM users LIMIT 1)`).Scan(&n)
 }
 
 func (d *Database) Close() error {
 	return d.db.Close()
 }
+
+// sqliteTime formats t the way CURRENT_TIMESTAMP stores it so string comparisons work.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
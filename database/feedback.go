@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"factory_bot/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RatingUp   = 1
+	RatingDown = -1
+)
+
+// Answer is a bot answer kept for quality reporting, so late ratings still
+// have context.
+type Answer struct {
+	ID                int64
+	ChatID            int64
+	TelegramMessageID int
+	Model             string
+	PromptVersion     string
+	Text              string
+	CreatedAt         time.Time
+}
+
+// RatedAnswer is a low-rated answer with the feedback left by the user.
+type RatedAnswer struct {
+	Answer
+	RatingID int64
+	UserID   int64
+	Rating   int
+	Comment  string
+	RatedAt  time.Time
+}
+
+// RatingSummary aggregates ratings for one model and prompt version.
+type RatingSummary struct {
+	Model         string
+	PromptVersion string
+	Up            int
+	Down          int
+}
+
+func (d *Database) SaveAnswer(ctx context.Context, chatID int64, model, promptVersion, text string) (int64, error) {
+	query := `INSERT INTO answers (chat_id, model, prompt_version, text) VALUES (?, ?, ?, ?)`
+	res, err := d.db.ExecContext(ctx, query, chatID, model, promptVersion, text)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("chat_id", chatID).Error("❌ Database: Failed to save answer")
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"chat_id":   chatID,
+		"answer_id": id,
+		"model":     model,
+	}).Debug("✅ Database: Answer saved")
+
+	return id, nil
+}
+
+// CountAnswers returns the number of answers the model gave in the chat since
+// the given time.
+func (d *Database) CountAnswers(ctx context.Context, chatID int64, model string, since time.Time) (int, error) {
+	var count int
+	err := d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM answers WHERE chat_id = ? AND model = ? AND created_at >= ?`,
+		chatID, model, sqliteTime(since)).Scan(&count)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("chat_id", chatID).Error("❌ Database: Failed to count answers")
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetAnswer returns the answer or nil if it does not exist.
+func (d *Database) GetAnswer(ctx context.Context, id int64) (*Answer, error) {
+	var a Answer
+	err := d.db.QueryRowContext(ctx, `SELECT id, chat_id, COALESCE(telegram_message_id, 0), COALESCE(model, ''),
+			  COALESCE(prompt_version, ''), text, created_at
+			  FROM answers WHERE id = ?`, id).Scan(
+		&a.ID, &a.ChatID, &a.TelegramMessageID, &a.Model, &a.PromptVersion, &a.Text, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("answer_id", id).Error("❌ Database: Failed to get answer")
+		return nil, err
+	}
+	return &a, nil
+}
+
+// SetAnswerTelegramMessageID links an answer to the Telegram message carrying its rating buttons.
+func (d *Database) SetAnswerTelegramMessageID(ctx context.Context, answerID int64, messageID int) error {
+	query := `UPDATE answers SET telegram_message_id = ? WHERE id = ?`
+	_, err := d.db.ExecContext(ctx, query, messageID, answerID)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("answer_id", answerID).Error("❌ Database: Failed to link answer message")
+	}
+	return err
+}
+
+// GetRatingUserID returns the user who left the rating, or 0 if it does not exist.
+func (d *Database) GetRatingUserID(ctx context.Context, ratingID int64) (int64, error) {
+	var userID int64
+	err := d.db.QueryRowContext(ctx, `SELECT user_id FROM answer_ratings WHERE id = ?`, ratingID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("rating_id", ratingID).Error("❌ Database: Failed to get rating")
+		return 0, err
+	}
+	return userID, nil
+}
+
+// RateAnswer stores or replaces the user's rating of an answer and returns the rating ID.
+func (d *Database) RateAnswer(ctx context.Context, answerID, userID int64, rating int) (int64, error) {
+	query := `INSERT INTO answer_ratings (answer_id, user_id, rating) VALUES (?, ?, ?)
+			  ON CONFLICT (answer_id, user_id) DO UPDATE SET rating = excluded.rating, created_at = CURRENT_TIMESTAMP`
+	if _, err := d.db.ExecContext(ctx, query, answerID, userID, rating); err != nil {
+		logging.From(ctx).WithError(err).WithFields(logrus.Fields{
+			"answer_id": answerID,
+			"user_id":   userID,
+		}).Error("❌ Database: Failed to save rating")
+		return 0, err
+	}
+
+	var ratingID int64
+	err := d.db.QueryRowContext(ctx, `SELECT id FROM answer_ratings WHERE answer_id = ? AND user_id = ?`, answerID, userID).Scan(&ratingID)
+	if err != nil {
+		return 0, err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"answer_id": answerID,
+		"user_id":   userID,
+		"rating":    rating,
+	}).Info("⭐ Database: Answer rated")
+
+	return ratingID, nil
+}
+
+func (d *Database) SetRatingComment(ctx context.Context, ratingID int64, comment string) error {
+	query := `UPDATE answer_ratings SET comment = ? WHERE id = ?`
+	_, err := d.db.ExecContext(ctx, query, comment, ratingID)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("rating_id", ratingID).Error("❌ Database: Failed to save rating comment")
+	}
+	return err
+}
+
+// GetLowRatedAnswers returns negatively rated answers since the given time, newest first.
+func (d *Database) GetLowRatedAnswers(ctx context.Context, since time.Time, limit int) ([]RatedAnswer, error) {
+	query := `SELECT a.id, a.chat_id, COALESCE(a.telegram_message_id, 0), COALESCE(a.model, ''),
+			  COALESCE(a.prompt_version, ''), a.text, a.created_at,
+			  r.id, r.user_id, r.rating, COALESCE(r.comment, ''), r.created_at
+			  FROM answer_ratings r
+			  JOIN answers a ON a.id = r.answer_id
+			  WHERE r.rating < 0 AND r.created_at >= ?
+			  ORDER BY r.created_at DESC
+			  LIMIT ?`
+
+	rows, err := d.db.QueryContext(ctx, query, sqliteTime(since), limit)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to get low-rated answers")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var answers []RatedAnswer
+	for rows.Next() {
+		var a RatedAnswer
+		err := rows.Scan(&a.ID, &a.ChatID, &a.TelegramMessageID, &a.Model, &a.PromptVersion, &a.Text, &a.CreatedAt,
+			&a.RatingID, &a.UserID, &a.Rating, &a.Comment, &a.RatedAt)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, a)
+	}
+
+	return answers, rows.Err()
+}
+
+// GetRatingSummary counts ratings per model and prompt version since the given time.
+func (d *Database) GetRatingSummary(ctx context.Context, since time.Time) ([]RatingSummary, error) {
+	query := `SELECT COALESCE(a.model, ''), COALESCE(a.prompt_version, ''),
+			  SUM(CASE WHEN r.rating > 0 THEN 1 ELSE 0 END),
+			  SUM(CASE WHEN r.rating < 0 THEN 1 ELSE 0 END)
+			  FROM answer_ratings r
+			  JOIN answers a ON a.id = r.answer_id
+			  WHERE r.created_at >= ?
+			  GROUP BY a.model, a.prompt_version
+			  ORDER BY a.model, a.prompt_version`
+
+	rows, err := d.db.QueryContext(ctx, query, sqliteTime(since))
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to get rating summary")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summary []RatingSummary
+	for rows.Next() {
+		var s RatingSummary
+		if err := rows.Scan(&s.Model, &s.PromptVersion, &s.Up, &s.Down); err != nil {
+			return nil, err
+		}
+		summary = append(summary, s)
+	}
+
+	return summary, rows.Err()
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"factory_bot/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Incident statuses
+const (
+	IncidentNew        = "new"
+	IncidentInProgress = "in_progress"
+	IncidentResolved   = "resolved"
+	IncidentRejected   = "rejected"
+)
+
+// Incident is a safety hazard reported by a worker.
+type Incident struct {
+	ID           int64
+	ReporterID   int64
+	ReporterName string
+	ChatID       int64
+	Category     string
+	Location     string
+	Description  string
+	Severity     string
+	Status       string
+	PhotoFileIDs []string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// IncidentUpdate is an entry in an incident's status history.
+type IncidentUpdate struct {
+	Status    string
+	Note      string
+	AuthorID  int64
+	CreatedAt time.Time
+}
+
+// TrackingNumber returns the number quoted to reporters and safety officers.
+func (i *Incident) TrackingNumber() string {
+	return FormatIncidentNumber(i.ID)
+}
+
+func FormatIncidentNumber(id int64) string {
+	return fmt.Sprintf("INC-%05d", id)
+}
+
+// ParseIncidentNumber accepts "INC-00012", "inc-12" or "12".
+func ParseIncidentNumber(value string) (int64, bool) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "INC-")
+	id, err := strconv.ParseInt(value, 10, 64)
+	return id, err == nil && id > 0
+}
+
+// CreateIncident stores a new incident with its photos and initial status entry.
+func (d *Database) CreateIncident(ctx context.Context, inc *Incident) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO incidents (reporter_id, reporter_name, chat_id, category, location, description, severity, status)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		inc.ReporterID, inc.ReporterName, inc.ChatID, inc.Category, inc.Location, inc.Description, inc.Severity, IncidentNew)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("reporter_id", inc.ReporterID).Error("❌ Database: Failed to create incident")
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, fileID := range inc.PhotoFileIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO incident_photos (incident_id, file_id) VALUES (?, ?)`, id, fileID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO incident_updates (incident_id, status, author_id) VALUES (?, ?, ?)`, id, IncidentNew, inc.ReporterID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	inc.ID = id
+	inc.Status = IncidentNew
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"incident_id": id,
+		"reporter_id": inc.ReporterID,
+		"severity":    inc.Severity,
+	}).Info("🚨 Database: Incident created")
+
+	return nil
+}
+
+// GetIncident returns the incident or nil if it does not exist.
+func (d *Database) GetIncident(ctx context.Context, id int64) (*Incident, error) {
+	var inc Incident
+	err := d.db.QueryRowContext(ctx, `SELECT id, reporter_id, reporter_name, chat_id, category, location, description, severity, status, created_at, updated_at
+			  FROM incidents WHERE id = ?`, id).Scan(
+		&inc.ID, &inc.ReporterID, &inc.ReporterName, &inc.ChatID, &inc.Category, &inc.Location,
+		&inc.Description, &inc.Severity, &inc.Status, &inc.CreatedAt, &inc.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("incident_id", id).Error("❌ Database: Failed to get incident")
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `SELECT file_id FROM incident_photos WHERE incident_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fileID string
+		if err := rows.Scan(&fileID); err != nil {
+			return nil, err
+		}
+		inc.PhotoFileIDs = append(inc.PhotoFileIDs, fileID)
+	}
+
+	return &inc, rows.Err()
+}
+
+// UpdateIncidentStatus changes the status and records it in the incident history.
+func (d *Database) UpdateIncidentStatus(ctx context.Context, id int64, status, note string, authorID int64) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE incidents SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, status, id)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("incident_id", id).Error("❌ Database: Failed to update incident status")
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO incident_updates (incident_id, status, note, author_id) VALUES (?, ?, ?, ?)`, id, status, note, authorID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"incident_id": id,
+		"status":      status,
+		"author_id":   authorID,
+	}).Info("✅ Database: Incident status updated")
+
+	return nil
+}
+
+// GetIncidentUpdates returns the status history of an incident, oldest first.
+func (d *Database) GetIncidentUpdates(ctx context.Context, id int64) ([]IncidentUpdate, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT status, COALESCE(note, ''), author_id, created_at
+			  FROM incident_updates WHERE incident_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var updates []IncidentUpdate
+	for rows.Next() {
+		var u IncidentUpdate
+		if err := rows.Scan(&u.Status, &u.Note, &u.AuthorID, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		updates = append(updates, u)
+	}
+
+	return updates, rows.Err()
+}
+
+// GetIncidentsByReporter returns the reporter's most recent incidents.
+func (d *Database) GetIncidentsByReporter(ctx context.Context, reporterID int64, limit int) ([]Incident, error) {
+	return d.queryIncidents(ctx, `SELECT id, reporter_id, reporter_name, chat_id, category, location, description, severity, status, created_at, updated_at
+			  FROM incidents WHERE reporter_id = ? ORDER BY id DESC LIMIT ?`, reporterID, limit)
+}
+
+// GetIncidentsBetween returns incidents created in [from, to), oldest first.
+func (d *Database) GetIncidentsBetween(ctx context.Context, from, to time.Time) ([]Incident, error) {
+	return d.queryIncidents(ctx, `SELECT id, reporter_id, reporter_name, chat_id, category, location, description, severity, status, created_at, updated_at
+			  FROM incidents WHERE created_at >= ? AND created_at < ? ORDER BY id`, sqliteTime(from), sqliteTime(to))
+}
+
+func (d *Database) queryIncidents(ctx context.Context, query string, args ...interface{}) ([]Incident, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to list incidents")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []Incident
+	for rows.Next() {
+		var inc Incident
+		err := rows.Scan(&inc.ID, &inc.ReporterID, &inc.ReporterName, &inc.ChatID, &inc.Category, &inc.Location,
+			&inc.Description, &inc.Severity, &inc.Status, &inc.CreatedAt, &inc.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, inc)
+	}
+
+	return incidents, rows.Err()
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"factory_bot/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job kinds
+const (
+	JobReminder  = "reminder"  // one-off or recurring user reminder
+	JobRecurring = "recurring" // admin-defined notification, e.g. weekly lubrication check
+)
+
+// Job is a persisted scheduled message. Cron is empty for one-off jobs, which
+// are deactivated after they run.
+type Job struct {
+	ID        int64
+	Kind      string
+	OwnerID   int64
+	Target    string // chat IDs and/or role names, comma-separated
+	Text      string
+	Cron      string
+	NextRun   time.Time
+	Attempts  int // failed runs of a one-off job
+	CreatedAt time.Time
+}
+
+const jobColumns = `id, kind, owner_id, target, text, cron, next_run, COALESCE(attempts, 0), created_at`
+
+func (d *Database) CreateJob(ctx context.Context, job *Job) error {
+	res, err := d.db.ExecContext(ctx, `INSERT INTO scheduled_jobs (kind, owner_id, target, text, cron, next_run) VALUES (?, ?, ?, ?, ?, ?)`,
+		job.Kind, job.OwnerID, job.Target, job.Text, job.Cron, sqliteTime(job.NextRun))
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("owner_id", job.OwnerID).Error("❌ Database: Failed to create job")
+		return err
+	}
+
+	job.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"job_id":   job.ID,
+		"kind":     job.Kind,
+		"next_run": job.NextRun.Format(time.RFC3339),
+	}).Info("⏰ Database: Job scheduled")
+
+	return nil
+}
+
+// GetDueJobs returns active jobs whose next run is at or before now.
+func (d *Database) GetDueJobs(ctx context.Context, now time.Time) ([]Job, error) {
+	return d.queryJobs(ctx, `SELECT `+jobColumns+` FROM scheduled_jobs WHERE active = 1 AND next_run <= ? ORDER BY next_run`, sqliteTime(now))
+}
+
+// GetNextJobTime returns the earliest next run of active jobs, or false if there are none.
+func (d *Database) GetNextJobTime(ctx context.Context) (time.Time, bool, error) {
+	jobs, err := d.queryJobs(ctx, `SELECT `+jobColumns+` FROM scheduled_jobs WHERE active = 1 ORDER BY next_run LIMIT 1`)
+	if err != nil || len(jobs) == 0 {
+		return time.Time{}, false, err
+	}
+	return jobs[0].NextRun, true, nil
+}
+
+// ListJobs returns active jobs of the given kind; a non-zero ownerID limits them to that owner.
+func (d *Database) ListJobs(ctx context.Context, kind string, ownerID int64) ([]Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM scheduled_jobs WHERE active = 1 AND kind = ?`
+	args := []interface{}{kind}
+	if ownerID != 0 {
+		query += ` AND owner_id = ?`
+		args = append(args, ownerID)
+	}
+	return d.queryJobs(ctx, query+` ORDER BY next_run`, args...)
+}
+
+// CompleteJobRun records a run; a zero next time deactivates the job.
+func (d *Database) CompleteJobRun(ctx context.Context, id int64, ranAt, next time.Time) error {
+	var err error
+	if next.IsZero() {
+		_, err = d.db.ExecContext(ctx, `UPDATE scheduled_jobs SET last_run = ?, active = 0 WHERE id = ?`, sqliteTime(ranAt), id)
+	} else {
+		_, err = d.db.ExecContext(ctx, `UPDATE scheduled_jobs SET last_run = ?, next_run = ? WHERE id = ?`, sqliteTime(ranAt), sqliteTime(next), id)
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("job_id", id).Error("❌ Database: Failed to update job")
+	}
+	return err
+}
+
+// RetryJob records a failed run of a one-off job and moves it to next.
+func (d *Database) RetryJob(ctx context.Context, id int64, ranAt, next time.Time) error {
+	_, err := d.db.ExecContext(ctx, `UPDATE scheduled_jobs SET last_run = ?, next_run = ?, attempts = COALESCE(attempts, 0) + 1 WHERE id = ?`,
+		sqliteTime(ranAt), sqliteTime(next), id)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("job_id", id).Error("❌ Database: Failed to update job")
+	}
+	return err
+}
+
+// DeactivateJob cancels a job. A non-zero ownerID only matches that owner's
+// jobs. It reports whether a job was cancelled.
+func (d *Database) DeactivateJob(ctx context.Context, id, ownerID int64, kind string) (bool, error) {
+	query := `UPDATE scheduled_jobs SET active = 0 WHERE id = ? AND kind = ? AND active = 1`
+	args := []interface{}{id, kind}
+	if ownerID != 0 {
+		query += ` AND owner_id = ?`
+		args = append(args, ownerID)
+	}
+
+	res, err := d.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("job_id", id).Error("❌ Database: Failed to cancel job")
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (d *Database) queryJobs(ctx context.Context, query string, args ...interface{}) ([]Job, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to list jobs")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var job Job
+		if err := rows.Scan(&job.ID, &job.Kind, &job.OwnerID, &job.Target, &job.Text, &job.Cron, &job.NextRun, &job.Attempts, &job.CreatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
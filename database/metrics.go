@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"factory_bot/metrics"
+	"factory_bot/tracing"
+)
+
+var queryDuration = metrics.NewHistogram("factory_bot_db_query_duration_seconds",
+	"Duration of SQLite statements by operation.", metrics.DBDurationBuckets, "operation")
+
+// instrumentedDB records the duration of every statement and traces it as a
+// client span. For queries the time until the first result is measured, not
+// reading the rows.
+type instrumentedDB struct {
+	*sql.DB
+}
+
+func (db instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, done := startQuery(ctx, query)
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
+}
+
+func (db instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, done := startQuery(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (db instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, done := startQuery(ctx, query)
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
+
+func (db instrumentedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (instrumentedTx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	return instrumentedTx{tx}, err
+}
+
+// instrumentedTx records statements run in a transaction like instrumentedDB.
+type instrumentedTx struct {
+	*sql.Tx
+}
+
+func (tx instrumentedTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, done := startQuery(ctx, query)
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
+}
+
+func (tx instrumentedTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, done := startQuery(ctx, query)
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (tx instrumentedTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, done := startQuery(ctx, query)
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
+
+// startQuery opens a span for a statement; the returned function records its
+// duration and outcome.
+func startQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	op := queryOperation(query)
+	ctx, span := tracing.StartKind(ctx, tracing.KindClient, "sqlite "+op,
+		tracing.String("db.system", "sqlite"),
+		tracing.String("db.operation", op),
+		tracing.String("db.statement", strings.Join(strings.Fields(query), " ")),
+	)
+	start := time.Now()
+
+	return ctx, func(err error) {
+		queryDuration.Observe(time.Since(start).Seconds(), op)
+		if err != sql.ErrNoRows {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
+
+// queryOperation returns the statement's leading keyword, e.g. "select".
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "other"
+	}
+	switch op := strings.ToLower(fields[0]); op {
+	case "select", "insert", "update", "delete", "create", "alter", "pragma":
+		return op
+	}
+	return "other"
+}
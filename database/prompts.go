@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"factory_bot/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Prompt is one version of a system prompt. Versions are numbered by ID
+// across all prompt names; one version per name is active.
+type Prompt struct {
+	ID         int64
+	Name       string // e.g. "main", "image"
+	Text       string
+	AuthorID   int64 // 0 for versions published from the config file
+	AuthorName string
+	Active     bool
+	CreatedAt  time.Time
+}
+
+const promptColumns = `id, name, text, author_id, author_name, active, created_at`
+
+// SavePrompt stores a new inactive version and returns its ID.
+func (d *Database) SavePrompt(ctx context.Context, name, text string, authorID int64, authorName string) (int64, error) {
+	res, err := d.db.ExecContext(ctx, `INSERT INTO prompts (name, text, author_id, author_name) VALUES (?, ?, ?, ?)`,
+		name, text, authorID, authorName)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("name", name).Error("❌ Database: Failed to save prompt")
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"prompt_id": id,
+		"name":      name,
+		"author_id": authorID,
+	}).Info("📝 Database: Prompt version saved")
+
+	return id, nil
+}
+
+// ActivatePrompt makes the version the active one for its name.
+func (d *Database) ActivatePrompt(ctx context.Context, id int64) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var name string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM prompts WHERE id = ?`, id).Scan(&name); err != nil {
+		logging.From(ctx).WithError(err).WithField("prompt_id", id).Error("❌ Database: Failed to find prompt")
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE prompts SET active = 0 WHERE name = ? AND active = 1`, name); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE prompts SET active = 1, activated_at = ? WHERE id = ?`, sqliteTime(time.Now()), id); err != nil {
+		logging.From(ctx).WithError(err).WithField("prompt_id", id).Error("❌ Database: Failed to activate prompt")
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"prompt_id": id,
+		"name":      name,
+	}).Info("✅ Database: Prompt version activated")
+
+	return nil
+}
+
+// GetPrompt returns the version or nil if it does not exist.
+func (d *Database) GetPrompt(ctx context.Context, id int64) (*Prompt, error) {
+	return d.queryPrompt(ctx, `SELECT `+promptColumns+` FROM prompts WHERE id = ?`, id)
+}
+
+// GetActivePrompts returns the active version of every prompt name.
+func (d *Database) GetActivePrompts(ctx context.Context) ([]Prompt, error) {
+	return d.queryPrompts(ctx, `SELECT `+promptColumns+` FROM prompts WHERE active = 1 ORDER BY name`)
+}
+
+// GetPromptHistory returns the newest versions of a prompt, newest first.
+func (d *Database) GetPromptHistory(ctx context.Context, name string, limit int) ([]Prompt, error) {
+	return d.queryPrompts(ctx, `SELECT `+promptColumns+` FROM prompts WHERE name = ? ORDER BY id DESC LIMIT ?`, name, limit)
+}
+
+// GetLatestPromptByAuthor returns the newest version of a prompt by the
+// author, or nil if there is none.
+func (d *Database) GetLatestPromptByAuthor(ctx context.Context, name string, authorID int64) (*Prompt, error) {
+	return d.queryPrompt(ctx, `SELECT `+promptColumns+` FROM prompts WHERE name = ? AND author_id = ? ORDER BY id DESC LIMIT 1`, name, authorID)
+}
+
+// GetPreviousPrompt returns the version of a prompt that was active before
+// the current one, or nil if there is none.
+func (d *Database) GetPreviousPrompt(ctx context.Context, name string) (*Prompt, error) {
+	return d.queryPrompt(ctx, `SELECT `+promptColumns+` FROM prompts
+			  WHERE name = ? AND active = 0 AND activated_at IS NOT NULL
+			  ORDER BY activated_at DESC, id DESC LIMIT 1`, name)
+}
+
+func (d *Database) queryPrompt(ctx context.Context, query string, args ...interface{}) (*Prompt, error) {
+	var p Prompt
+	err := d.db.QueryRowContext(ctx, query, args...).Scan(&p.ID, &p.Name, &p.Text, &p.AuthorID, &p.AuthorName, &p.Active, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to get prompt")
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (d *Database) queryPrompts(ctx context.Context, query string, args ...interface{}) ([]Prompt, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to list prompts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prompts []Prompt
+	for rows.Next() {
+		var p Prompt
+		if err := rows.Scan(&p.ID, &p.Name, &p.Text, &p.AuthorID, &p.AuthorName, &p.Active, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, rows.Err()
+}
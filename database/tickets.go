@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"factory_bot/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Ticket statuses
+const (
+	TicketOpen       = "open"
+	TicketInProgress = "in_progress"
+	TicketOnHold     = "on_hold"
+	TicketClosed     = "closed"
+)
+
+// Ticket is a maintenance request for a piece of equipment.
+type Ticket struct {
+	ID           int64
+	Equipment    string
+	Description  string
+	Priority     string
+	Status       string
+	ReporterID   int64
+	ReporterName string
+	ChatID       int64
+	AssigneeID   int64
+	AssigneeName string
+	PhotoFileIDs []string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TicketEvent is an entry in a ticket's status history.
+type TicketEvent struct {
+	Status    string
+	Note      string
+	AuthorID  int64
+	CreatedAt time.Time
+}
+
+func (t *Ticket) Number() string {
+	return FormatTicketNumber(t.ID)
+}
+
+func FormatTicketNumber(id int64) string {
+	return fmt.Sprintf("TKT-%05d", id)
+}
+
+// ParseTicketNumber accepts "TKT-00012", "tkt-12" or "12".
+func ParseTicketNumber(value string) (int64, bool) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "TKT-")
+	id, err := strconv.ParseInt(value, 10, 64)
+	return id, err == nil && id > 0
+}
+
+const ticketColumns = `id, equipment, description, priority, status, reporter_id, reporter_name, chat_id,
+			  assignee_id, assignee_name, created_at, updated_at`
+
+func scanTicket(row interface{ Scan(...interface{}) error }) (*Ticket, error) {
+	var t Ticket
+	err := row.Scan(&t.ID, &t.Equipment, &t.Description, &t.Priority, &t.Status, &t.ReporterID, &t.ReporterName,
+		&t.ChatID, &t.AssigneeID, &t.AssigneeName, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateTicket stores a new ticket with its photos and initial history entry.
+func (d *Database) CreateTicket(ctx context.Context, t *Ticket) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO tickets (equipment, description, priority, status, reporter_id, reporter_name, chat_id)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		t.Equipment, t.Description, t.Priority, TicketOpen, t.ReporterID, t.ReporterName, t.ChatID)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("reporter_id", t.ReporterID).Error("❌ Database: Failed to create ticket")
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, fileID := range t.PhotoFileIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO ticket_photos (ticket_id, file_id) VALUES (?, ?)`, id, fileID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO ticket_history (ticket_id, status, author_id) VALUES (?, ?, ?)`, id, TicketOpen, t.ReporterID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	t.ID = id
+	t.Status = TicketOpen
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"ticket_id":   id,
+		"reporter_id": t.ReporterID,
+		"priority":    t.Priority,
+	}).Info("🛠 Database: Ticket created")
+
+	return nil
+}
+
+// GetTicket returns the ticket with its photos, or nil if it does not exist.
+func (d *Database) GetTicket(ctx context.Context, id int64) (*Ticket, error) {
+	t, err := scanTicket(d.db.QueryRowContext(ctx, `SELECT `+ticketColumns+` FROM tickets WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("ticket_id", id).Error("❌ Database: Failed to get ticket")
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `SELECT file_id FROM ticket_photos WHERE ticket_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fileID string
+		if err := rows.Scan(&fileID); err != nil {
+			return nil, err
+		}
+		t.PhotoFileIDs = append(t.PhotoFileIDs, fileID)
+	}
+
+	return t, rows.Err()
+}
+
+// UpdateTicketStatus changes the status and records it in the ticket history.
+func (d *Database) UpdateTicketStatus(ctx context.Context, id int64, status, note string, authorID int64) error {
+	return d.updateTicket(ctx, id, `UPDATE tickets SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		[]interface{}{status, id}, status, note, authorID)
+}
+
+// AssignTicket sets the assignee and records the assignment in the ticket history.
+func (d *Database) AssignTicket(ctx context.Context, id, assigneeID int64, assigneeName string, authorID int64) error {
+	return d.updateTicket(ctx, id, `UPDATE tickets SET assignee_id = ?, assignee_name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		[]interface{}{assigneeID, assigneeName, id}, "assigned", assigneeName, authorID)
+}
+
+func (d *Database) updateTicket(ctx context.Context, id int64, update string, args []interface{}, event, note string, authorID int64) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, update, args...)
+	if err != nil {
+		logging.From(ctx).WithError(err).WithField("ticket_id", id).Error("❌ Database: Failed to update ticket")
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO ticket_history (ticket_id, status, note, author_id) VALUES (?, ?, ?, ?)`, id, event, note, authorID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logging.From(ctx).WithFields(logrus.Fields{
+		"ticket_id": id,
+		"event":     event,
+		"author_id": authorID,
+	}).Info("✅ Database: Ticket updated")
+
+	return nil
+}
+
+// GetTicketHistory returns the ticket's status history, oldest first.
+func (d *Database) GetTicketHistory(ctx context.Context, id int64) ([]TicketEvent, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT status, COALESCE(note, ''), author_id, created_at
+			  FROM ticket_history WHERE ticket_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []TicketEvent
+	for rows.Next() {
+		var e TicketEvent
+		if err := rows.Scan(&e.Status, &e.Note, &e.AuthorID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// ListTickets returns tickets newest first. Closed tickets are included only
+// if includeClosed is set; a non-zero userID limits the list to tickets the
+// user reported or is assigned to.
+func (d *Database) ListTickets(ctx context.Context, includeClosed bool, userID int64, limit int) ([]Ticket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM tickets WHERE 1 = 1`
+	var args []interface{}
+	if !includeClosed {
+		query += ` AND status != ?`
+		args = append(args, TicketClosed)
+	}
+	if userID != 0 {
+		query += ` AND (reporter_id = ? OR assignee_id = ?)`
+		args = append(args, userID, userID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return d.queryTickets(ctx, query, args...)
+}
+
+// GetTicketsBetween returns tickets created or updated in [from, to), oldest first.
+func (d *Database) GetTicketsBetween(ctx context.Context, from, to time.Time) ([]Ticket, error) {
+	return d.queryTickets(ctx, `SELECT `+ticketColumns+` FROM tickets
+			  WHERE (created_at >= ? AND created_at < ?) OR (updated_at >= ? AND updated_at < ?)
+			  ORDER BY id`, sqliteTime(from), sqliteTime(to), sqliteTime(from), sqliteTime(to))
+}
+
+func (d *Database) queryTickets(ctx context.Context, query string, args ...interface{}) ([]Ticket, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logging.From(ctx).WithError(err).Error("❌ Database: Failed to list tickets")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []Ticket
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, *t)
+	}
+
+	return tickets, rows.Err()
+}
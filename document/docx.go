@@ -0,0 +1,264 @@
+// Package document renders assistant answers as Word (DOCX) documents that
+// can be printed or forwarded.
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"factory_bot/markdown"
+)
+
+// Header is printed at the top of every page and on the title block.
+type Header struct {
+	Organization string // plant name
+	Title        string
+	Subtitle     string // e.g. who the answer was prepared for
+	Date         time.Time
+	PageLabel    string // footer text before the page number, e.g. "Стр. "
+}
+
+// DOCX renders Markdown as a DOCX file: headings, lists, tables, quotes and
+// code blocks keep their structure, and every page carries the plant header
+// and a page number.
+func DOCX(header Header, src string) ([]byte, error) {
+	w := &docxWriter{}
+	w.titleBlock(header)
+	w.blocks(markdown.Parse(src))
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"docProps/core.xml", coreXML(header)},
+		{"word/document.xml", w.document()},
+		{"word/styles.xml", stylesXML},
+		{"word/header1.xml", headerXML(header)},
+		{"word/footer1.xml", footerXML(header)},
+		{"word/_rels/document.xml.rels", w.relationships()},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", f.name, err)
+		}
+		if _, err := fw.Write([]byte(f.content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type docxWriter struct {
+	body  strings.Builder
+	links []string // hyperlink targets, relationship IDs rLink1…
+}
+
+func (w *docxWriter) titleBlock(h Header) {
+	w.paragraph("Title", "", []markdown.Span{{Text: h.Title}})
+	meta := h.Organization
+	if h.Subtitle != "" {
+		meta += " · " + h.Subtitle
+	}
+	meta += " · " + h.Date.Format("02.01.2006 15:04")
+	w.paragraph("Subtitle", "", []markdown.Span{{Text: meta}})
+}
+
+func (w *docxWriter) blocks(blocks []markdown.Block) {
+	for i := 0; i < len(blocks); i++ {
+		blk := blocks[i]
+		switch blk.Kind {
+		case markdown.BlockBlank:
+			continue
+
+		case markdown.BlockParagraph:
+			// Consecutive lines form one paragraph with line breaks
+			spans := markdown.Spans(blk.Text)
+			for i+1 < len(blocks) && blocks[i+1].Kind == markdown.BlockParagraph {
+				i++
+				spans = append(spans, markdown.Span{Text: "\n"})
+				spans = append(spans, markdown.Spans(blocks[i].Text)...)
+			}
+			w.paragraph("", "", spans)
+
+		case markdown.BlockHeading:
+			w.paragraph(fmt.Sprintf("Heading%d", min(blk.Level, 3)), "", markdown.Spans(blk.Text))
+
+		case markdown.BlockListItem:
+			indent := 360 * (blk.Level + 1)
+			props := fmt.Sprintf(`<w:ind w:left="%d" w:hanging="360"/>`, indent)
+			spans := append([]markdown.Span{{Text: blk.Marker + "\t"}}, markdown.Spans(blk.Text)...)
+			w.paragraph("ListParagraph", props, spans)
+
+		case markdown.BlockQuote:
+			var spans []markdown.Span
+			for j, line := range strings.Split(blk.Text, "\n") {
+				if j > 0 {
+					spans = append(spans, markdown.Span{Text: "\n"})
+				}
+				spans = append(spans, markdown.Spans(line)...)
+			}
+			w.paragraph("Quote", "", spans)
+
+		case markdown.BlockCode:
+			w.paragraph("Code", "", []markdown.Span{{Text: blk.Text, Code: true}})
+
+		case markdown.BlockTable:
+			w.table(blk.Rows)
+
+		case markdown.BlockRule:
+			w.paragraph("", `<w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="999999"/></w:pBdr>`, nil)
+		}
+	}
+}
+
+func (w *docxWriter) paragraph(style, props string, spans []markdown.Span) {
+	w.body.WriteString("<w:p>")
+	if style != "" || props != "" {
+		w.body.WriteString("<w:pPr>")
+		if style != "" {
+			fmt.Fprintf(&w.body, `<w:pStyle w:val="%s"/>`, style)
+		}
+		w.body.WriteString(props)
+		w.body.WriteString("</w:pPr>")
+	}
+	w.runs(spans)
+	w.body.WriteString("</w:p>")
+}
+
+func (w *docxWriter) runs(spans []markdown.Span) {
+	for _, span := range spans {
+		if span.URL != "" {
+			w.links = append(w.links, span.URL)
+			fmt.Fprintf(&w.body, `<w:hyperlink r:id="rLink%d">`, len(w.links))
+			w.run(span, true)
+			w.body.WriteString("</w:hyperlink>")
+			continue
+		}
+		w.run(span, false)
+	}
+}
+
+func (w *docxWriter) run(span markdown.Span, link bool) {
+	// Run properties must follow the schema order
+	var props strings.Builder
+	if span.Code {
+		props.WriteString(`<w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/>`)
+	}
+	if span.Bold {
+		props.WriteString("<w:b/>")
+	}
+	if span.Italic {
+		props.WriteString("<w:i/>")
+	}
+	if span.Strike {
+		props.WriteString("<w:strike/>")
+	}
+	if link {
+		props.WriteString(`<w:color w:val="0563C1"/><w:u w:val="single"/>`)
+	}
+	if span.Code {
+		props.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/>`)
+	}
+
+	w.body.WriteString("<w:r>")
+	if props.Len() > 0 {
+		w.body.WriteString("<w:rPr>" + props.String() + "</w:rPr>")
+	}
+	// Line breaks and tabs are separate elements in WordprocessingML
+	for i, line := range strings.Split(span.Text, "\n") {
+		if i > 0 {
+			w.body.WriteString("<w:br/>")
+		}
+		for j, part := range strings.Split(line, "\t") {
+			if j > 0 {
+				w.body.WriteString("<w:tab/>")
+			}
+			if part != "" {
+				w.body.WriteString(`<w:t xml:space="preserve">` + escapeXML(part) + "</w:t>")
+			}
+		}
+	}
+	w.body.WriteString("</w:r>")
+}
+
+func (w *docxWriter) table(rows [][]string) {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+
+	w.body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid>`)
+	for c := 0; c < columns; c++ {
+		w.body.WriteString(`<w:gridCol/>`)
+	}
+	w.body.WriteString("</w:tblGrid>")
+
+	for r, row := range rows {
+		w.body.WriteString("<w:tr>")
+		if r == 0 {
+			// Repeat the header row on every page
+			w.body.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		for c := 0; c < columns; c++ {
+			cell := ""
+			if c < len(row) {
+				cell = row[c]
+			}
+			w.body.WriteString("<w:tc>")
+			if r == 0 {
+				w.body.WriteString(`<w:tcPr><w:shd w:val="clear" w:color="auto" w:fill="D9E2F3"/></w:tcPr>`)
+			}
+			spans := markdown.Spans(cell)
+			if r == 0 {
+				for i := range spans {
+					spans[i].Bold = true
+				}
+			}
+			w.paragraph("TableText", "", spans)
+			w.body.WriteString("</w:tc>")
+		}
+		w.body.WriteString("</w:tr>")
+	}
+	w.body.WriteString("</w:tbl>")
+	// Word requires a paragraph between adjacent tables and before the section end
+	w.paragraph("", "", nil)
+}
+
+func (w *docxWriter) document() string {
+	return xml.Header + `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>` +
+		w.body.String() +
+		// A4 with 2 cm margins
+		`<w:sectPr><w:headerReference w:type="default" r:id="rHeader"/><w:footerReference w:type="default" r:id="rFooter"/>` +
+		`<w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/></w:sectPr>` +
+		`</w:body></w:document>`
+}
+
+func (w *docxWriter) relationships() string {
+	var rels strings.Builder
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	rels.WriteString(`<Relationship Id="rStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	rels.WriteString(`<Relationship Id="rHeader" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>`)
+	rels.WriteString(`<Relationship Id="rFooter" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>`)
+	for i, url := range w.links {
+		fmt.Fprintf(&rels, `<Relationship Id="rLink%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="%s" TargetMode="External"/>`,
+			i+1, escapeXML(url))
+	}
+	rels.WriteString(`</Relationships>`)
+	return rels.String()
+}
+
+func escapeXML(s string) string {
+	var buf strings.Builder
+	// EscapeText only fails on writer errors, which strings.Builder never returns
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
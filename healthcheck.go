@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"factory_bot/config"
+)
+
+// healthcheckTimeout bounds the request made by the healthcheck subcommand.
+const healthcheckTimeout = 5 * time.Second
+
+// runHealthcheck implements "factory_bot healthcheck [live|ready]" for the
+// Docker HEALTHCHECK: it queries /readyz (or /healthz) of the running bot on
+// the configured metrics port and returns the exit code.
+func runHealthcheck(args []string) int {
+	path := "/readyz"
+	if len(args) > 0 {
+		switch args[0] {
+		case "ready":
+		case "live":
+			path = "/healthz"
+		default:
+			fmt.Fprintf(os.Stderr, "usage: %s healthcheck [live|ready]\n", os.Args[0])
+			return 2
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if cfg.MetricsPort == 0 {
+		fmt.Fprintln(os.Stderr, "health endpoints are disabled (METRICS_PORT=0)")
+		return 1
+	}
+
+	client := &http.Client{Timeout: healthcheckTimeout}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d%s", cfg.MetricsPort, path))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	os.Stdout.Write(body)
+	if resp.StatusCode != http.StatusOK {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,317 @@
+package i18n
+
+var en = map[string]string{
+	// General
+	"start": "🏭 **%s AI Assistant**\n\n" +
+		"**What I can do:**\n" +
+		"• Support production processes\n" +
+		"• Technical help and troubleshooting\n" +
+		"• Quality and safety control\n" +
+		"• GOST standards compliance\n" +
+		"• Production optimization\n\n" +
+		"Ready to help with your work! Language: /lang",
+	"busy":                 "⏳ The bot is busy, please wait for the previous answers.",
+	"error.restarting":     "⚠️ The bot is restarting and your request was interrupted. Please retry in a minute.",
+	"error.ai_unavailable": "⏳ The AI service is temporarily unavailable. Please retry in a minute.",
+	"error.request":        "❌ Error processing request",
+	"error.image":          "❌ Error processing image",
+	"error.image_analysis": "❌ Error analyzing image",
+	"error.loading":        "❌ Error loading data",
+	"error.status_update":  "❌ Failed to update status",
+	"error.request_id":     "Request ID: `%s`",
+	"not_allowed":          "⛔ Not allowed",
+	"usage":                "Usage: %s",
+	"history":              "History:",
+	"comment":              "Comment: %s",
+	"cancelled":            "❎ Cancelled.",
+	"cancel.nothing":       "Nothing to cancel.",
+	"dialog.ended":         "The dialog has ended",
+	"dialog.not_yours":     "Only the user who started this can use the button.",
+	"button.done":          "✅ Done",
+	"button.skip":          "⏭ Skip",
+	"photos.limit":         "At most %d photos can be attached.",
+	"photos.added":         "📷 Photo added (%d).",
+	"photos.send_or_done":  "Send a photo or press Done.",
+
+	// Commands
+	"command.unknown":    "Unknown command. See /help for the list of commands.",
+	"command.admin_only": "⛔ This command is for admins only.",
+	"help.title":         "📋 Available commands:",
+
+	"cmd.start":          "Start the assistant",
+	"cmd.help":           "List of commands",
+	"cmd.lang":           "Interface and answer language",
+	"cmd.mode":           "Assistant mode: maintenance, quality, safety…",
+	"cmd.model":          "Answer model: cheaper or stronger",
+	"cmd.cancel":         "Cancel the current dialog",
+	"cmd.incident":       "Report a hazard or check its status",
+	"cmd.incidentstatus": "Change a hazard report status",
+	"cmd.ticket":         "Maintenance tickets: new, list, show, assign, close",
+	"cmd.remind":         "Reminder: /remind 30m text",
+	"cmd.schedule":       "Recurring scheduled notifications",
+	"cmd.shiftreport":    "Shift or period summary",
+	"cmd.feedback":       "Answer rating report",
+	"cmd.department":     "Set a user's department",
+	"cmd.status":         "Processing queue status",
+	"cmd.reload":         "Reload the config file",
+	"cmd.prompt":         "Versioned system prompts",
+
+	"usage.lang":           "[ru|en|uz|tg|auto]",
+	"usage.mode":           "[mode|auto]",
+	"usage.model":          "[model|default]",
+	"usage.incident":       "[number]",
+	"usage.incidentstatus": "<number> <status> [comment]",
+	"usage.ticket":         "<new|list|show|assign|close> ...",
+	"usage.remind":         "<when> <text> | list | cancel <id>",
+	"usage.schedule":       "add|list|remove ...",
+	"usage.shiftreport":    "[from] [to]",
+	"usage.feedback":       "[days]",
+	"usage.department":     "<@username|id> [department]",
+	"usage.prompt":         "list | show | history | edit | diff | activate | rollback",
+
+	// Language
+	"lang.choose":   "🌐 Choose your language. Current: %s",
+	"lang.auto":     "🔄 Automatic",
+	"lang.set":      "✅ Language: %s",
+	"lang.auto_set": "✅ The language follows your Telegram settings: %s",
+	"lang.failed":   "❌ Failed to save the language",
+
+	// Assistant modes
+	"mode.general":       "🏭 General",
+	"mode.maintenance":   "🔧 Maintenance",
+	"mode.quality":       "🔍 Quality control",
+	"mode.safety":        "🦺 Safety",
+	"mode.planning":      "📅 Planning",
+	"mode.current":       "🎛 Current mode: %s\nDepartment: %s\n\nChoose a mode:",
+	"mode.no_department": "not set",
+	"mode.auto":          "🔄 By department",
+	"mode.set":           "✅ Mode: %s",
+	"mode.auto_set":      "✅ The mode follows your department: %s",
+	"mode.failed":        "❌ Failed to save the mode",
+	"mode.unknown":       "Unknown mode. Available: %s",
+	"mode.no_images":     "🖼 Photos are not analyzed in mode %s. Change the mode: /mode",
+	"department.set":     "✅ %s: department “%s”, default mode %s",
+	"department.cleared": "✅ Department of %s cleared",
+	"department.failed":  "❌ Failed to save the department",
+
+	// Model choice
+	"model.current":        "🤖 Model: %s\n\nChoose a model (💰 cost, 🖼 reads photos):",
+	"model.default":        "by mode (%s)",
+	"model.button.default": "🔄 By mode",
+	"model.per_day":        "%d/day",
+	"model.no_catalog":     "No models to choose from, answers use %s.",
+	"model.set":            "✅ Model: %s",
+	"model.set_limited":    "✅ Model: %s\nUp to %d answers a day, then the mode's model answers.",
+	"model.default_set":    "✅ The model follows the mode: %s",
+	"model.unknown":        "Unknown model. Available: %s",
+	"model.not_allowed":    "⛔ %s is only available to admins.",
+	"model.failed":         "❌ Failed to save the model",
+	"model.limit_reached":  "⏳ Today's limit for %s (%d answers) is used up, the mode's model answers until tomorrow.",
+
+	// Configuration reload
+	"reload.done":      "✅ Configuration reloaded. Changed: %s",
+	"reload.unchanged": "Configuration reloaded, nothing changed.",
+	"reload.restart":   "⚠️ Take effect after a restart: %s",
+	"reload.failed":    "❌ Configuration not applied:\n%s",
+
+	// Prompt versions
+	"prompt.usage": "Usage:\n" +
+		"/prompt list — active versions\n" +
+		"/prompt show <name|version> — prompt text as a file\n" +
+		"/prompt history <name> — recent versions\n" +
+		"/prompt edit <name> — upload a new version\n" +
+		"/prompt diff <version> [version] — compare versions\n" +
+		"/prompt activate <version> — make a version active\n" +
+		"/prompt rollback <name> — return to the previous version",
+	"prompt.unknown_name":     "Unknown prompt. Available: %s",
+	"prompt.not_found":        "Version not found.",
+	"prompt.load_failed":      "❌ Failed to load prompts",
+	"prompt.list_title":       "📝 Active prompts (version · author · created · characters):",
+	"prompt.history_title":    "📝 Versions of %s:",
+	"prompt.active_mark":      "✅ active",
+	"prompt.config_author":    "config",
+	"prompt.edit_start":       "✏️ Current text of %s is attached. Send the new text as a .txt file or a message. /cancel to abort.",
+	"prompt.edit_expect":      "Send a .txt file or a text message.",
+	"prompt.file_invalid":     "❌ Expected a UTF-8 text file up to %d KB",
+	"prompt.empty":            "The prompt cannot be empty.",
+	"prompt.template_invalid": "❌ The template has an error, nothing saved:\n%v",
+	"prompt.unchanged":        "The text matches active version v%d, nothing saved.",
+	"prompt.save_failed":      "❌ Failed to save the version",
+	"prompt.saved":            "✅ %s v%d saved, not active yet: +%d −%d lines",
+	"prompt.button.activate":  "✅ Activate",
+	"prompt.button.diff":      "🔍 Changes",
+	"prompt.activated":        "✅ %s v%d is active",
+	"prompt.activate_failed":  "❌ Failed to activate the version",
+	"prompt.already_active":   "%s v%d is already active.",
+	"prompt.no_previous":      "%s has no previous version.",
+	"prompt.diff_same":        "The versions are identical.",
+	"prompt.diff_title":       "🔍 %s: v%d → v%d, +%d −%d lines",
+
+	"status.report": "📈 Processing queue\n\n" +
+		"Workers: %d (busy: %d)\n" +
+		"Queued: %d (peak: %d, limit: %d)\n" +
+		"Active chats: %d\n" +
+		"Processed: %d\n" +
+		"Rejected: %d",
+
+	// Answer feedback
+	"rating.save_failed":    "❌ Failed to save the rating",
+	"rating.thanks_button":  "👍 Thanks!",
+	"rating.thanks":         "Thanks for the feedback!",
+	"rating.why_button":     "📝 What was wrong?",
+	"rating.noted":          "Thanks, noted.",
+	"rating.ask_comment":    "📝 Please describe what was wrong with the answer.",
+	"rating.not_yours":      "Only the user who rated the answer can comment on it.",
+	"rating.comment_failed": "❌ Failed to save feedback",
+	"rating.comment_saved":  "🙏 Thank you, your feedback was forwarded to the admins.",
+	"report.failed":         "❌ Error building the report",
+	"report.title":          "📊 Answer ratings for %d days",
+	"report.empty":          "No ratings yet.",
+	"report.model":          "• %s (prompt v%s): 👍 %d / 👎 %d",
+	"report.low":            "👎 Latest low ratings:",
+	"report.answer":         "Answer: %s",
+
+	// Answer documents
+	"document.button":    "📄 As file",
+	"document.subtitle":  "AI assistant",
+	"document.page":      "Page ",
+	"document.title":     "Assistant answer",
+	"document.summary":   "📄 The answer is long, so it was sent as a file.",
+	"document.sections":  "**Sections:**",
+	"document.not_found": "❌ Answer not found",
+	"document.failed":    "❌ Failed to create the file",
+
+	// Fields of incident and ticket cards
+	"field.category":    "Category",
+	"field.severity":    "Severity",
+	"field.location":    "Location",
+	"field.reporter":    "Reported by",
+	"field.photos":      "Photos",
+	"field.status":      "Status",
+	"field.equipment":   "Equipment",
+	"field.priority":    "Priority",
+	"field.author":      "Author",
+	"field.assignee":    "Assignee",
+	"field.description": "Description",
+
+	// Incidents
+	"incident.category.injury":     "🩹 Injury",
+	"incident.category.near_miss":  "⚠️ Near miss",
+	"incident.category.equipment":  "⚙️ Equipment hazard",
+	"incident.category.fire":       "🔥 Fire, smoke",
+	"incident.category.chemical":   "🧪 Chemical hazard",
+	"incident.category.electrical": "⚡ Electrical hazard",
+	"incident.category.other":      "📌 Other",
+	"incident.severity.low":        "🟢 Low",
+	"incident.severity.medium":     "🟡 Medium",
+	"incident.severity.high":       "🟠 High",
+	"incident.severity.critical":   "🔴 Critical",
+	"incident.status.new":          "🆕 New",
+	"incident.status.in_progress":  "🔎 In progress",
+	"incident.status.resolved":     "✅ Resolved",
+	"incident.status.rejected":     "❌ Rejected",
+
+	"incident.start":            "🚨 **Hazard report**\n\nStep 1/5. Choose a category. /cancel to abort.",
+	"incident.choose_category":  "Please choose a category with the buttons above.",
+	"incident.category_chosen":  "Category: %s\n\nStep 2/5. Where did it happen? Give the workshop, area or equipment.",
+	"incident.type_location":    "Please type the location.",
+	"incident.step_description": "Step 3/5. Describe what happened or what the hazard is.",
+	"incident.describe":         "Please describe the situation in text.",
+	"incident.step_photos":      "Step 4/5. Send photos (several allowed), then press Done, or skip.",
+	"incident.step_severity":    "Step 5/5. How severe is it?",
+	"incident.choose_severity":  "Please choose the severity with the buttons above.",
+	"incident.save_failed":      "❌ Failed to save the report. Please inform your supervisor directly!",
+	"incident.registered":       "✅ Report registered: **%[1]s**\nSafety officers have been notified. Status: /incident %[1]s",
+	"incident.status_changed":   "📣 Status of your report %s: %s",
+	"incident.private_only":     "Please report hazards in a private chat with the bot.",
+	"incident.not_found":        "Report not found.",
+	"incident.statuses":         "Statuses: %s",
+
+	// Tickets
+	"ticket.priority.low":       "🟢 Low",
+	"ticket.priority.normal":    "🟡 Normal",
+	"ticket.priority.high":      "🟠 High",
+	"ticket.priority.urgent":    "🔴 Urgent",
+	"ticket.status.open":        "🆕 Open",
+	"ticket.status.in_progress": "🔧 In progress",
+	"ticket.status.on_hold":     "⏸ On hold",
+	"ticket.status.closed":      "✅ Closed",
+	"ticket.status.assigned":    "👤 Assignee set",
+
+	"ticket.create_button":      "🛠 Create ticket",
+	"ticket.draft":              "🛠 New ticket\n\n%s\n\nCreate the ticket?",
+	"ticket.button.create":      "✅ Create",
+	"ticket.button.photos":      "📷 Add photos",
+	"ticket.button.start_over":  "✏️ Start over",
+	"ticket.start":              "🛠 **New ticket**\n\nStep 1/4. Which equipment? (name, asset number, area). /cancel to abort.",
+	"ticket.use_buttons":        "Please use the buttons above.",
+	"ticket.type_equipment":     "Please type the equipment name.",
+	"ticket.step_description":   "Step 2/4. Describe the fault.",
+	"ticket.describe":           "Please describe the fault in text.",
+	"ticket.step_photos":        "Step 3/4. Send photos of the fault (several allowed), then press Done, or skip.",
+	"ticket.step_priority":      "Step 4/4. Priority?",
+	"ticket.choose_priority":    "Please choose the priority with the buttons above.",
+	"ticket.create_failed":      "❌ Failed to create the ticket",
+	"ticket.created":            "✅ Ticket %s created.\n\n%s",
+	"ticket.suggestion_expired": "The suggestion has expired",
+	"ticket.status_changed":     "🛠 Ticket %s (%s): %s",
+	"ticket.usage": "Usage:\n" +
+		"/ticket new — new ticket\n" +
+		"/ticket list [all|mine] — list tickets\n" +
+		"/ticket show <number> — details\n" +
+		"/ticket assign <number> <@username|id> — set the assignee\n" +
+		"/ticket close <number> [comment] — close",
+	"ticket.close_not_allowed":  "⛔ Only the author, the assignee or an admin can close the ticket.",
+	"ticket.update_failed":      "❌ Failed to update the ticket",
+	"ticket.not_found":          "Ticket not found.",
+	"ticket.closed":             "✅ Ticket %s closed.",
+	"ticket.list_failed":        "❌ Error loading tickets",
+	"ticket.none":               "No tickets.",
+	"ticket.list_title":         "🛠 Tickets:",
+	"ticket.invalid_number":     "Invalid ticket number.",
+	"ticket.load_failed":        "❌ Error loading the ticket",
+	"ticket.assign_admin_only":  "⛔ Only admins can assign tickets.",
+	"ticket.user_lookup_failed": "❌ Error looking up the user",
+	"ticket.user_not_found":     "User not found: they must message the bot first.",
+	"ticket.assign_failed":      "❌ Failed to assign (check the ticket number).",
+	"ticket.assigned":           "👤 Ticket %s assigned to %s\n\n%s",
+
+	// Reminders and scheduled jobs
+	"reminder.header": "⏰ Reminder:\n\n%s",
+	"remind.usage": "Usage:\n" +
+		"/remind 30m Check the pressure\n" +
+		"/remind 14:30 Call quality control\n" +
+		"/remind 25.12 08:00 Hand in the report\n" +
+		"/remind cron \"0 8 * * 1-5\" Area walkthrough\n" +
+		"/remind list — my reminders\n" +
+		"/remind cancel <id> — cancel",
+	"remind.failed":  "❌ Failed to create the reminder: %s",
+	"remind.created": "✅ Reminder #%d: %s\n%s",
+	"schedule.usage": "Usage:\n" +
+		"/schedule add \"<cron>\" <target> <text>\n" +
+		"   target: chat ID, admins, safety, management (comma-separated)\n" +
+		"   example: /schedule add \"0 9 * * 1\" -100123456 Weekly lubrication check\n" +
+		"/schedule list\n" +
+		"/schedule remove <id>",
+	"schedule.failed":     "❌ Failed to create the job: %s",
+	"schedule.created":    "✅ Job #%d → %s\nNext run: %s",
+	"jobs.list_failed":    "❌ Error loading the list",
+	"jobs.empty":          "Nothing scheduled.",
+	"jobs.title":          "⏰ Scheduled:",
+	"jobs.invalid_number": "Invalid number.",
+	"jobs.cancel_failed":  "❌ Error cancelling",
+	"jobs.not_found":      "Not found.",
+	"jobs.cancelled":      "❎ #%d cancelled.",
+	"jobs.repeats":        "Repeats: %q",
+
+	// Shift reports
+	"shiftreport.title":          "📋 Shift %d report (%s), %s",
+	"shiftreport.current_title":  "📋 Current shift %d report (%s), as of %s",
+	"shiftreport.period_title":   "📋 Report for %s — %s",
+	"shiftreport.collect_failed": "❌ Error collecting report data",
+	"shiftreport.failed":         "❌ Error generating the report",
+	"shiftreport.usage": "Usage:\n" +
+		"/shiftreport — current shift\n" +
+		"/shiftreport 12h — last 12 hours\n" +
+		"/shiftreport \"02.01.2006 08:00\" [\"02.01.2006 20:00\"] — custom period",
+}
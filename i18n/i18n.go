@@ -0,0 +1,115 @@
+// Package i18n holds the message catalogs for user-facing bot texts.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale is a supported interface language, identified by its ISO 639-1 code.
+type Locale string
+
+const (
+	Russian Locale = "ru"
+	English Locale = "en"
+	Uzbek   Locale = "uz"
+	Tajik   Locale = "tg"
+)
+
+// Locales lists the supported languages in the order they are offered to users.
+var Locales = []Locale{Russian, English, Uzbek, Tajik}
+
+// catalogs maps each locale to its messages. Russian is the reference
+// catalog: keys missing elsewhere fall back to it.
+var catalogs = map[Locale]map[string]string{
+	Russian: ru,
+	English: en,
+	Uzbek:   uz,
+	Tajik:   tg,
+}
+
+// russianSpeaking are languages whose speakers at the plant are expected to
+// read Russian better than English.
+var russianSpeaking = map[string]bool{
+	"be": true, "kk": true, "ky": true, "uk": true, "tk": true, "az": true, "hy": true,
+}
+
+// Parse reads a language code such as "uz" or "en-US". It reports false for
+// unsupported languages.
+func Parse(code string) (Locale, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	for _, l := range Locales {
+		if string(l) == code {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// Detect picks a locale from a Telegram language_code. Unsupported languages
+// of the former USSR map to Russian, others to English; an empty code gives fallback.
+func Detect(languageCode string, fallback Locale) Locale {
+	if l, ok := Parse(languageCode); ok {
+		return l
+	}
+	code := strings.ToLower(languageCode)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	switch {
+	case code == "":
+		return fallback
+	case russianSpeaking[code]:
+		return Russian
+	default:
+		return English
+	}
+}
+
+// Name returns the language name in the language itself, for the /lang menu.
+func (l Locale) Name() string {
+	switch l {
+	case Russian:
+		return "🇷🇺 Русский"
+	case English:
+		return "🇬🇧 English"
+	case Uzbek:
+		return "🇺🇿 O‘zbekcha"
+	case Tajik:
+		return "🇹🇯 Тоҷикӣ"
+	}
+	return string(l)
+}
+
+// PromptName returns the language name used to instruct the model.
+func (l Locale) PromptName() string {
+	switch l {
+	case Russian:
+		return "Russian"
+	case English:
+		return "English"
+	case Uzbek:
+		return "Uzbek (Latin script)"
+	case Tajik:
+		return "Tajik (Cyrillic script)"
+	}
+	return "Russian"
+}
+
+// T returns the message for key in locale l, formatted with args. Unknown
+// keys are returned as is, so a missing translation is visible but harmless.
+func T(l Locale, key string, args ...interface{}) string {
+	msg, ok := catalogs[l][key]
+	if !ok {
+		if msg, ok = ru[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
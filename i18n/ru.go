@@ -0,0 +1,317 @@
+package i18n
+
+var ru = map[string]string{
+	// General
+	"start": "🏭 **AI-ассистент %s**\n\n" +
+		"**Основные функции:**\n" +
+		"• Поддержка производственных процессов\n" +
+		"• Техническая помощь и диагностика\n" +
+		"• Контроль качества и безопасности\n" +
+		"• Соблюдение стандартов ГОСТ\n" +
+		"• Оптимизация производства\n\n" +
+		"Готов помочь с производственными задачами! Язык: /lang",
+	"busy":                 "⏳ Бот занят, пожалуйста, подождите ответа на предыдущие сообщения.",
+	"error.restarting":     "⚠️ Бот перезапускается, запрос прерван. Повторите его через минуту.",
+	"error.ai_unavailable": "⏳ Сервис ИИ временно недоступен. Повторите запрос через минуту.",
+	"error.request":        "❌ Ошибка обработки запроса",
+	"error.image":          "❌ Ошибка обработки изображения",
+	"error.image_analysis": "❌ Ошибка анализа изображения",
+	"error.loading":        "❌ Ошибка получения данных",
+	"error.status_update":  "❌ Ошибка обновления статуса",
+	"error.request_id":     "Код запроса: `%s`",
+	"not_allowed":          "⛔ Нет прав",
+	"usage":                "Использование: %s",
+	"history":              "История:",
+	"comment":              "Комментарий: %s",
+	"cancelled":            "❎ Отменено.",
+	"cancel.nothing":       "Нечего отменять.",
+	"dialog.ended":         "Диалог уже завершён",
+	"dialog.not_yours":     "Эта кнопка доступна только тому, кто начал этот диалог.",
+	"button.done":          "✅ Готово",
+	"button.skip":          "⏭ Пропустить",
+	"photos.limit":         "Можно приложить не более %d фото.",
+	"photos.added":         "📷 Фото добавлено (%d).",
+	"photos.send_or_done":  "Пришлите фото или нажмите «Готово».",
+
+	// Commands
+	"command.unknown":    "Неизвестная команда. /help — список команд.",
+	"command.admin_only": "⛔ Команда доступна только администраторам.",
+	"help.title":         "📋 Доступные команды:",
+
+	"cmd.start":          "Запуск ассистента",
+	"cmd.help":           "Список команд",
+	"cmd.lang":           "Язык интерфейса и ответов",
+	"cmd.mode":           "Режим ассистента: техобслуживание, качество, охрана труда…",
+	"cmd.model":          "Модель ответов: дешевле или сильнее",
+	"cmd.cancel":         "Отменить текущий диалог",
+	"cmd.incident":       "Сообщить об опасности или узнать статус",
+	"cmd.incidentstatus": "Изменить статус сообщения об опасности",
+	"cmd.ticket":         "Заявки на ремонт: new, list, show, assign, close",
+	"cmd.remind":         "Напоминание: /remind 30m текст",
+	"cmd.schedule":       "Регулярные уведомления по расписанию",
+	"cmd.shiftreport":    "Сводка за смену или период",
+	"cmd.feedback":       "Отчёт по оценкам ответов",
+	"cmd.department":     "Назначить отдел пользователя",
+	"cmd.status":         "Состояние очереди обработки",
+	"cmd.reload":         "Перечитать файл конфигурации",
+	"cmd.prompt":         "Версии системных промптов",
+
+	"usage.lang":           "[ru|en|uz|tg|auto]",
+	"usage.mode":           "[режим|auto]",
+	"usage.model":          "[модель|default]",
+	"usage.incident":       "[номер]",
+	"usage.incidentstatus": "<номер> <статус> [комментарий]",
+	"usage.ticket":         "<new|list|show|assign|close> ...",
+	"usage.remind":         "<когда> <текст> | list | cancel <id>",
+	"usage.schedule":       "add|list|remove ...",
+	"usage.shiftreport":    "[от] [до]",
+	"usage.feedback":       "[дни]",
+	"usage.department":     "<@username|id> [отдел]",
+	"usage.prompt":         "list | show | history | edit | diff | activate | rollback",
+
+	// Language
+	"lang.choose":   "🌐 Выберите язык. Сейчас: %s",
+	"lang.auto":     "🔄 Автоматически",
+	"lang.set":      "✅ Язык: %s",
+	"lang.auto_set": "✅ Язык определяется по настройкам Telegram: %s",
+	"lang.failed":   "❌ Не удалось сохранить язык",
+
+	// Assistant modes
+	"mode.general":       "🏭 Общий",
+	"mode.maintenance":   "🔧 Техобслуживание",
+	"mode.quality":       "🔍 Контроль качества",
+	"mode.safety":        "🦺 Охрана труда",
+	"mode.planning":      "📅 Планирование",
+	"mode.current":       "🎛 Текущий режим: %s\nОтдел: %s\n\nВыберите режим:",
+	"mode.no_department": "не указан",
+	"mode.auto":          "🔄 По отделу",
+	"mode.set":           "✅ Режим: %s",
+	"mode.auto_set":      "✅ Режим определяется отделом: %s",
+	"mode.failed":        "❌ Не удалось сохранить режим",
+	"mode.unknown":       "Неизвестный режим. Доступны: %s",
+	"mode.no_images":     "🖼 В режиме %s фотографии не анализируются. Сменить режим: /mode",
+	"department.set":     "✅ %s: отдел «%s», режим по умолчанию %s",
+	"department.cleared": "✅ Отдел пользователя %s сброшен",
+	"department.failed":  "❌ Не удалось сохранить отдел",
+
+	// Выбор модели
+	"model.current":        "🤖 Модель: %s\n\nВыберите модель (💰 стоимость, 🖼 понимает фото):",
+	"model.default":        "по режиму (%s)",
+	"model.button.default": "🔄 По режиму",
+	"model.per_day":        "%d/день",
+	"model.no_catalog":     "Выбор моделей не настроен, ответы даёт %s.",
+	"model.set":            "✅ Модель: %s",
+	"model.set_limited":    "✅ Модель: %s\nДо %d ответов в день, затем отвечает модель режима.",
+	"model.default_set":    "✅ Модель выбирается по режиму: %s",
+	"model.unknown":        "Неизвестная модель. Доступны: %s",
+	"model.not_allowed":    "⛔ %s доступна только администраторам.",
+	"model.failed":         "❌ Не удалось сохранить модель",
+	"model.limit_reached":  "⏳ Дневной лимит %s (%d ответов) исчерпан, до завтра отвечает модель режима.",
+
+	// Configuration reload
+	"reload.done":      "✅ Конфигурация перечитана. Изменено: %s",
+	"reload.unchanged": "Конфигурация перечитана, изменений нет.",
+	"reload.restart":   "⚠️ Вступят в силу после перезапуска: %s",
+	"reload.failed":    "❌ Конфигурация не применена:\n%s",
+
+	// Версии промптов
+	"prompt.usage": "Использование:\n" +
+		"/prompt list — активные версии\n" +
+		"/prompt show <имя|версия> — текст промпта файлом\n" +
+		"/prompt history <имя> — последние версии\n" +
+		"/prompt edit <имя> — загрузить новую версию\n" +
+		"/prompt diff <версия> [версия] — сравнить версии\n" +
+		"/prompt activate <версия> — сделать версию активной\n" +
+		"/prompt rollback <имя> — вернуть предыдущую версию",
+	"prompt.unknown_name":     "Неизвестный промпт. Доступны: %s",
+	"prompt.not_found":        "Версия не найдена.",
+	"prompt.load_failed":      "❌ Не удалось загрузить промпты",
+	"prompt.list_title":       "📝 Активные промпты (версия · автор · создан · символов):",
+	"prompt.history_title":    "📝 Версии %s:",
+	"prompt.active_mark":      "✅ активна",
+	"prompt.config_author":    "конфигурация",
+	"prompt.edit_start":       "✏️ Текущий текст %s во вложении. Пришлите новый текст файлом .txt или сообщением. /cancel — отмена.",
+	"prompt.edit_expect":      "Пришлите файл .txt или текстовое сообщение.",
+	"prompt.file_invalid":     "❌ Нужен текстовый файл в UTF-8 до %d КБ",
+	"prompt.empty":            "Промпт не может быть пустым.",
+	"prompt.template_invalid": "❌ Ошибка в шаблоне, ничего не сохранено:\n%v",
+	"prompt.unchanged":        "Текст совпадает с активной версией v%d, ничего не сохранено.",
+	"prompt.save_failed":      "❌ Не удалось сохранить версию",
+	"prompt.saved":            "✅ %s v%d сохранён, пока не активен: +%d −%d строк",
+	"prompt.button.activate":  "✅ Активировать",
+	"prompt.button.diff":      "🔍 Изменения",
+	"prompt.activated":        "✅ %s v%d активен",
+	"prompt.activate_failed":  "❌ Не удалось активировать версию",
+	"prompt.already_active":   "%s v%d уже активен.",
+	"prompt.no_previous":      "У %s нет предыдущей версии.",
+	"prompt.diff_same":        "Версии совпадают.",
+	"prompt.diff_title":       "🔍 %s: v%d → v%d, +%d −%d строк",
+
+	"status.report": "📈 Очередь обработки\n\n" +
+		"Обработчики: %d (заняты: %d)\n" +
+		"В очереди: %d (макс.: %d, лимит: %d)\n" +
+		"Чатов в работе: %d\n" +
+		"Обработано: %d\n" +
+		"Отклонено: %d",
+
+	// Answer feedback
+	"rating.save_failed":    "❌ Не удалось сохранить оценку",
+	"rating.thanks_button":  "👍 Спасибо!",
+	"rating.thanks":         "Спасибо за оценку!",
+	"rating.why_button":     "📝 Что было не так?",
+	"rating.noted":          "Спасибо, учтём.",
+	"rating.ask_comment":    "📝 Опишите, что было не так в ответе.",
+	"rating.not_yours":      "Оставить комментарий может только тот, кто поставил оценку.",
+	"rating.comment_failed": "❌ Не удалось сохранить отзыв",
+	"rating.comment_saved":  "🙏 Спасибо, отзыв передан администраторам.",
+	"report.failed":         "❌ Ошибка получения отчёта",
+	"report.title":          "📊 Оценки ответов за %d дн.",
+	"report.empty":          "Оценок пока нет.",
+	"report.model":          "• %s (промпт v%s): 👍 %d / 👎 %d",
+	"report.low":            "👎 Последние низкие оценки:",
+	"report.answer":         "Ответ: %s",
+
+	// Answer documents
+	"document.button":    "📄 Файлом",
+	"document.subtitle":  "AI-ассистент",
+	"document.page":      "Стр. ",
+	"document.title":     "Ответ ассистента",
+	"document.summary":   "📄 Ответ длинный, поэтому отправлен файлом.",
+	"document.sections":  "**Разделы:**",
+	"document.not_found": "❌ Ответ не найден",
+	"document.failed":    "❌ Не удалось создать файл",
+
+	// Fields of incident and ticket cards
+	"field.category":    "Категория",
+	"field.severity":    "Серьёзность",
+	"field.location":    "Место",
+	"field.reporter":    "Сообщил",
+	"field.photos":      "Фото",
+	"field.status":      "Статус",
+	"field.equipment":   "Оборудование",
+	"field.priority":    "Приоритет",
+	"field.author":      "Автор",
+	"field.assignee":    "Исполнитель",
+	"field.description": "Описание",
+
+	// Incidents
+	"incident.category.injury":     "🩹 Травма",
+	"incident.category.near_miss":  "⚠️ Опасная ситуация",
+	"incident.category.equipment":  "⚙️ Неисправность оборудования",
+	"incident.category.fire":       "🔥 Пожар, задымление",
+	"incident.category.chemical":   "🧪 Химическая опасность",
+	"incident.category.electrical": "⚡ Электрическая опасность",
+	"incident.category.other":      "📌 Другое",
+	"incident.severity.low":        "🟢 Низкая",
+	"incident.severity.medium":     "🟡 Средняя",
+	"incident.severity.high":       "🟠 Высокая",
+	"incident.severity.critical":   "🔴 Критическая",
+	"incident.status.new":          "🆕 Новое",
+	"incident.status.in_progress":  "🔎 В работе",
+	"incident.status.resolved":     "✅ Устранено",
+	"incident.status.rejected":     "❌ Отклонено",
+
+	"incident.start":            "🚨 **Сообщение об опасности**\n\nШаг 1/5. Выберите категорию. /cancel — отмена.",
+	"incident.choose_category":  "Выберите категорию кнопкой выше.",
+	"incident.category_chosen":  "Категория: %s\n\nШаг 2/5. Где это произошло? Укажите цех, участок, оборудование.",
+	"incident.type_location":    "Напишите место текстом.",
+	"incident.step_description": "Шаг 3/5. Опишите, что произошло или в чём опасность.",
+	"incident.describe":         "Опишите ситуацию текстом.",
+	"incident.step_photos":      "Шаг 4/5. Пришлите фото (можно несколько) и нажмите «Готово», или пропустите.",
+	"incident.step_severity":    "Шаг 5/5. Насколько это серьёзно?",
+	"incident.choose_severity":  "Выберите серьёзность кнопкой выше.",
+	"incident.save_failed":      "❌ Не удалось сохранить сообщение. Сообщите мастеру лично!",
+	"incident.registered":       "✅ Сообщение зарегистрировано: **%[1]s**\nОтветственные по охране труда уведомлены. Статус: /incident %[1]s",
+	"incident.status_changed":   "📣 Статус вашего сообщения %s: %s",
+	"incident.private_only":     "Сообщить об опасности можно в личном чате с ботом.",
+	"incident.not_found":        "Сообщение не найдено.",
+	"incident.statuses":         "Статусы: %s",
+
+	// Tickets
+	"ticket.priority.low":       "🟢 Низкий",
+	"ticket.priority.normal":    "🟡 Обычный",
+	"ticket.priority.high":      "🟠 Высокий",
+	"ticket.priority.urgent":    "🔴 Срочный",
+	"ticket.status.open":        "🆕 Открыта",
+	"ticket.status.in_progress": "🔧 В работе",
+	"ticket.status.on_hold":     "⏸ Ожидание",
+	"ticket.status.closed":      "✅ Закрыта",
+	"ticket.status.assigned":    "👤 Назначен исполнитель",
+
+	"ticket.create_button":      "🛠 Создать заявку",
+	"ticket.draft":              "🛠 Новая заявка\n\n%s\n\nСоздать заявку?",
+	"ticket.button.create":      "✅ Создать",
+	"ticket.button.photos":      "📷 Добавить фото",
+	"ticket.button.start_over":  "✏️ Заполнить заново",
+	"ticket.start":              "🛠 **Новая заявка**\n\nШаг 1/4. Какое оборудование? (название, инв. номер, участок). /cancel — отмена.",
+	"ticket.use_buttons":        "Выберите действие кнопкой выше.",
+	"ticket.type_equipment":     "Напишите название оборудования.",
+	"ticket.step_description":   "Шаг 2/4. Опишите неисправность.",
+	"ticket.describe":           "Опишите неисправность текстом.",
+	"ticket.step_photos":        "Шаг 3/4. Пришлите фото неисправности (можно несколько) и нажмите «Готово», или пропустите.",
+	"ticket.step_priority":      "Шаг 4/4. Приоритет?",
+	"ticket.choose_priority":    "Выберите приоритет кнопкой выше.",
+	"ticket.create_failed":      "❌ Не удалось создать заявку",
+	"ticket.created":            "✅ Заявка %s создана.\n\n%s",
+	"ticket.suggestion_expired": "Предложение устарело",
+	"ticket.status_changed":     "🛠 Заявка %s (%s): %s",
+	"ticket.usage": "Использование:\n" +
+		"/ticket new — новая заявка\n" +
+		"/ticket list [all|mine] — список заявок\n" +
+		"/ticket show <номер> — подробности\n" +
+		"/ticket assign <номер> <@username|id> — назначить исполнителя\n" +
+		"/ticket close <номер> [комментарий] — закрыть",
+	"ticket.close_not_allowed":  "⛔ Закрыть заявку может автор, исполнитель или администратор.",
+	"ticket.update_failed":      "❌ Ошибка обновления заявки",
+	"ticket.not_found":          "Заявка не найдена.",
+	"ticket.closed":             "✅ Заявка %s закрыта.",
+	"ticket.list_failed":        "❌ Ошибка получения заявок",
+	"ticket.none":               "Заявок нет.",
+	"ticket.list_title":         "🛠 Заявки:",
+	"ticket.invalid_number":     "Неверный номер заявки.",
+	"ticket.load_failed":        "❌ Ошибка получения заявки",
+	"ticket.assign_admin_only":  "⛔ Назначать исполнителей могут только администраторы.",
+	"ticket.user_lookup_failed": "❌ Ошибка поиска пользователя",
+	"ticket.user_not_found":     "Пользователь не найден: он должен хотя бы раз написать боту.",
+	"ticket.assign_failed":      "❌ Не удалось назначить исполнителя (проверьте номер заявки).",
+	"ticket.assigned":           "👤 Заявка %s назначена: %s\n\n%s",
+
+	// Reminders and scheduled jobs
+	"reminder.header": "⏰ Напоминание:\n\n%s",
+	"remind.usage": "Использование:\n" +
+		"/remind 30m Проверить давление\n" +
+		"/remind 14:30 Позвонить в ОТК\n" +
+		"/remind 25.12 08:00 Сдать отчёт\n" +
+		"/remind cron \"0 8 * * 1-5\" Обход участка\n" +
+		"/remind list — мои напоминания\n" +
+		"/remind cancel <id> — отменить",
+	"remind.failed":  "❌ Не удалось создать напоминание: %s",
+	"remind.created": "✅ Напоминание #%d: %s\n%s",
+	"schedule.usage": "Использование:\n" +
+		"/schedule add \"<cron>\" <цель> <текст>\n" +
+		"   цель: ID чата, admins, safety, management (через запятую)\n" +
+		"   пример: /schedule add \"0 9 * * 1\" -100123456 Еженедельная проверка смазки\n" +
+		"/schedule list\n" +
+		"/schedule remove <id>",
+	"schedule.failed":     "❌ Не удалось создать задание: %s",
+	"schedule.created":    "✅ Задание #%d → %s\nСледующий запуск: %s",
+	"jobs.list_failed":    "❌ Ошибка получения списка",
+	"jobs.empty":          "Список пуст.",
+	"jobs.title":          "⏰ Запланировано:",
+	"jobs.invalid_number": "Неверный номер.",
+	"jobs.cancel_failed":  "❌ Ошибка отмены",
+	"jobs.not_found":      "Не найдено.",
+	"jobs.cancelled":      "❎ #%d отменено.",
+	"jobs.repeats":        "Повтор по расписанию: %q",
+
+	// Shift reports
+	"shiftreport.title":          "📋 Отчёт за смену %d (%s), %s",
+	"shiftreport.current_title":  "📋 Отчёт за текущую смену %d (%s), на %s",
+	"shiftreport.period_title":   "📋 Отчёт за период %s — %s",
+	"shiftreport.collect_failed": "❌ Ошибка сбора данных для отчёта",
+	"shiftreport.failed":         "❌ Ошибка генерации отчёта",
+	"shiftreport.usage": "Использование:\n" +
+		"/shiftreport — текущая смена\n" +
+		"/shiftreport 12h — последние 12 часов\n" +
+		"/shiftreport \"02.01.2006 08:00\" [\"02.01.2006 20:00\"] — произвольный период",
+}
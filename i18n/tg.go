@@ -0,0 +1,317 @@
+package i18n
+
+var tg = map[string]string{
+	// General
+	"start": "🏭 **Ёрдамчии AI-и %s**\n\n" +
+		"**Имкониятҳои асосӣ:**\n" +
+		"• Дастгирии равандҳои истеҳсолӣ\n" +
+		"• Кӯмаки техникӣ ва ташхиси носозиҳо\n" +
+		"• Назорати сифат ва бехатарӣ\n" +
+		"• Риояи стандартҳои ГОСТ\n" +
+		"• Беҳтар кардани истеҳсолот\n\n" +
+		"Омодаам дар корҳои истеҳсолӣ кӯмак расонам! Забон: /lang",
+	"busy":                 "⏳ Бот банд аст, лутфан ҷавоби паёмҳои қаблиро интизор шавед.",
+	"error.restarting":     "⚠️ Бот аз нав оғоз мешавад, дархости шумо қатъ шуд. Баъд аз як дақиқа такрор кунед.",
+	"error.ai_unavailable": "⏳ Хидмати AI муваққатан дастрас нест. Баъд аз як дақиқа такрор кунед.",
+	"error.request":        "❌ Хатогӣ ҳангоми коркарди дархост",
+	"error.image":          "❌ Хатогӣ ҳангоми коркарди тасвир",
+	"error.image_analysis": "❌ Хатогӣ ҳангоми таҳлили тасвир",
+	"error.loading":        "❌ Хатогӣ ҳангоми гирифтани маълумот",
+	"error.status_update":  "❌ Ҳолатро нав кардан муяссар нашуд",
+	"error.request_id":     "Рамзи дархост: `%s`",
+	"not_allowed":          "⛔ Иҷозат нест",
+	"usage":                "Истифода: %s",
+	"history":              "Таърих:",
+	"comment":              "Шарҳ: %s",
+	"cancelled":            "❎ Бекор карда шуд.",
+	"cancel.nothing":       "Чизе барои бекор кардан нест.",
+	"dialog.ended":         "Муколама аллакай ба охир расидааст",
+	"dialog.not_yours":     "Ин тугмаро танҳо корбаре, ки онро оғоз кардааст, истифода бурда метавонад.",
+	"button.done":          "✅ Тайёр",
+	"button.skip":          "⏭ Гузаштан",
+	"photos.limit":         "На зиёда аз %d акс замима кардан мумкин аст.",
+	"photos.added":         "📷 Акс илова шуд (%d).",
+	"photos.send_or_done":  "Акс фиристед ё тугмаи «Тайёр»-ро пахш кунед.",
+
+	// Commands
+	"command.unknown":    "Фармони номаълум. Рӯйхати фармонҳо: /help",
+	"command.admin_only": "⛔ Ин фармон танҳо барои маъмурон аст.",
+	"help.title":         "📋 Фармонҳои дастрас:",
+
+	"cmd.start":          "Оғози ёрдамчӣ",
+	"cmd.help":           "Рӯйхати фармонҳо",
+	"cmd.lang":           "Забони интерфейс ва ҷавобҳо",
+	"cmd.mode":           "Реҷаи ёрдамчӣ: хизматрасонии техникӣ, сифат, ҳифзи меҳнат…",
+	"cmd.model":          "Модели ҷавоб: арзонтар ё пурқувваттар",
+	"cmd.cancel":         "Бекор кардани муколамаи ҷорӣ",
+	"cmd.incident":       "Хабар додан дар бораи хатар ё санҷидани ҳолат",
+	"cmd.incidentstatus": "Тағйир додани ҳолати хабар дар бораи хатар",
+	"cmd.ticket":         "Дархостҳои таъмир: new, list, show, assign, close",
+	"cmd.remind":         "Ёдрасонӣ: /remind 30m матн",
+	"cmd.schedule":       "Огоҳиномаҳои мунтазам аз рӯи ҷадвал",
+	"cmd.shiftreport":    "Ҳисобот барои баст ё давра",
+	"cmd.feedback":       "Ҳисобот оид ба баҳои ҷавобҳо",
+	"cmd.department":     "Таъин кардани шӯъбаи корбар",
+	"cmd.status":         "Ҳолати навбати коркард",
+	"cmd.reload":         "Файли танзимотро аз нав хондан",
+	"cmd.prompt":         "Версияҳои промптҳои системавӣ",
+
+	"usage.lang":           "[ru|en|uz|tg|auto]",
+	"usage.mode":           "[реҷа|auto]",
+	"usage.model":          "[модел|default]",
+	"usage.incident":       "[рақам]",
+	"usage.incidentstatus": "<рақам> <ҳолат> [шарҳ]",
+	"usage.ticket":         "<new|list|show|assign|close> ...",
+	"usage.remind":         "<кай> <матн> | list | cancel <id>",
+	"usage.schedule":       "add|list|remove ...",
+	"usage.shiftreport":    "[аз] [то]",
+	"usage.feedback":       "[рӯзҳо]",
+	"usage.department":     "<@username|id> [шӯъба]",
+	"usage.prompt":         "list | show | history | edit | diff | activate | rollback",
+
+	// Language
+	"lang.choose":   "🌐 Забонро интихоб кунед. Ҳозира: %s",
+	"lang.auto":     "🔄 Худкор",
+	"lang.set":      "✅ Забон: %s",
+	"lang.auto_set": "✅ Забон аз рӯи танзимоти Telegram муайян мешавад: %s",
+	"lang.failed":   "❌ Забонро нигоҳ доштан муяссар нашуд",
+
+	// Assistant modes
+	"mode.general":       "🏭 Умумӣ",
+	"mode.maintenance":   "🔧 Хизматрасонии техникӣ",
+	"mode.quality":       "🔍 Назорати сифат",
+	"mode.safety":        "🦺 Ҳифзи меҳнат",
+	"mode.planning":      "📅 Банақшагирӣ",
+	"mode.current":       "🎛 Реҷаи ҷорӣ: %s\nШӯъба: %s\n\nРеҷаро интихоб кунед:",
+	"mode.no_department": "нишон дода нашудааст",
+	"mode.auto":          "🔄 Аз рӯи шӯъба",
+	"mode.set":           "✅ Реҷа: %s",
+	"mode.auto_set":      "✅ Реҷа аз рӯи шӯъбаи шумо муайян мешавад: %s",
+	"mode.failed":        "❌ Реҷаро нигоҳ доштан муяссар нашуд",
+	"mode.unknown":       "Реҷаи номаълум. Дастрас: %s",
+	"mode.no_images":     "🖼 Дар реҷаи %s суратҳо таҳлил намешаванд. Иваз кардани реҷа: /mode",
+	"department.set":     "✅ %s: шӯъба «%s», реҷаи пешфарз %s",
+	"department.cleared": "✅ Шӯъбаи %s тоза карда шуд",
+	"department.failed":  "❌ Шӯъбаро нигоҳ доштан муяссар нашуд",
+
+	// Интихоби модел
+	"model.current":        "🤖 Модел: %s\n\nМоделро интихоб кунед (💰 нарх, 🖼 суратро мефаҳмад):",
+	"model.default":        "аз рӯи реҷа (%s)",
+	"model.button.default": "🔄 Аз рӯи реҷа",
+	"model.per_day":        "%d/рӯз",
+	"model.no_catalog":     "Интихоби модел танзим нашудааст, ҷавобҳоро %s медиҳад.",
+	"model.set":            "✅ Модел: %s",
+	"model.set_limited":    "✅ Модел: %s\nТо %d ҷавоб дар як рӯз, сипас модели реҷа ҷавоб медиҳад.",
+	"model.default_set":    "✅ Модел аз рӯи реҷа интихоб мешавад: %s",
+	"model.unknown":        "Модели номаълум. Дастрас: %s",
+	"model.not_allowed":    "⛔ %s танҳо барои маъмурон дастрас аст.",
+	"model.failed":         "❌ Моделро нигоҳ доштан муяссар нашуд",
+	"model.limit_reached":  "⏳ Лимити рӯзонаи %s (%d ҷавоб) тамом шуд, то фардо модели реҷа ҷавоб медиҳад.",
+
+	// Configuration reload
+	"reload.done":      "✅ Танзимот аз нав хонда шуд. Тағйир ёфт: %s",
+	"reload.unchanged": "Танзимот аз нав хонда шуд, тағйирот нест.",
+	"reload.restart":   "⚠️ Пас аз бозоғозӣ эътибор пайдо мекунад: %s",
+	"reload.failed":    "❌ Танзимот татбиқ нашуд:\n%s",
+
+	// Версияҳои промпт
+	"prompt.usage": "Истифода:\n" +
+		"/prompt list — версияҳои фаъол\n" +
+		"/prompt show <ном|версия> — матни промпт ҳамчун файл\n" +
+		"/prompt history <ном> — версияҳои охирин\n" +
+		"/prompt edit <ном> — бор кардани версияи нав\n" +
+		"/prompt diff <версия> [версия] — муқоисаи версияҳо\n" +
+		"/prompt activate <версия> — фаъол кардани версия\n" +
+		"/prompt rollback <ном> — баргаштан ба версияи пештара",
+	"prompt.unknown_name":     "Промпти номаълум. Дастрас: %s",
+	"prompt.not_found":        "Версия ёфт нашуд.",
+	"prompt.load_failed":      "❌ Промптҳоро бор кардан муяссар нашуд",
+	"prompt.list_title":       "📝 Промптҳои фаъол (версия · муаллиф · сохта шуд · аломатҳо):",
+	"prompt.history_title":    "📝 Версияҳои %s:",
+	"prompt.active_mark":      "✅ фаъол",
+	"prompt.config_author":    "танзимот",
+	"prompt.edit_start":       "✏️ Матни ҷории %s замима шудааст. Матни навро ҳамчун файли .txt ё паём фиристед. /cancel — бекор кардан.",
+	"prompt.edit_expect":      "Файли .txt ё паёми матнӣ фиристед.",
+	"prompt.file_invalid":     "❌ Файли матнии UTF-8 то %d КБ лозим аст",
+	"prompt.empty":            "Промпт холӣ буда наметавонад.",
+	"prompt.template_invalid": "❌ Дар шаблон хато ҳаст, чизе нигоҳ дошта нашуд:\n%v",
+	"prompt.unchanged":        "Матн бо версияи фаъоли v%d якхела аст, чизе нигоҳ дошта нашуд.",
+	"prompt.save_failed":      "❌ Версияро нигоҳ доштан муяссар нашуд",
+	"prompt.saved":            "✅ %s v%d нигоҳ дошта шуд, ҳоло фаъол нест: +%d −%d сатр",
+	"prompt.button.activate":  "✅ Фаъол кардан",
+	"prompt.button.diff":      "🔍 Тағйирот",
+	"prompt.activated":        "✅ %s v%d фаъол аст",
+	"prompt.activate_failed":  "❌ Версияро фаъол кардан муяссар нашуд",
+	"prompt.already_active":   "%s v%d аллакай фаъол аст.",
+	"prompt.no_previous":      "%s версияи пештара надорад.",
+	"prompt.diff_same":        "Версияҳо якхелаанд.",
+	"prompt.diff_title":       "🔍 %s: v%d → v%d, +%d −%d сатр",
+
+	"status.report": "📈 Навбати коркард\n\n" +
+		"Коркардкунандагон: %d (банд: %d)\n" +
+		"Дар навбат: %d (ҳадди аксар: %d, маҳдудият: %d)\n" +
+		"Чатҳои фаъол: %d\n" +
+		"Коркардшуда: %d\n" +
+		"Радшуда: %d",
+
+	// Answer feedback
+	"rating.save_failed":    "❌ Баҳоро нигоҳ доштан муяссар нашуд",
+	"rating.thanks_button":  "👍 Ташаккур!",
+	"rating.thanks":         "Ташаккур барои баҳо!",
+	"rating.why_button":     "📝 Чӣ нодуруст буд?",
+	"rating.noted":          "Ташаккур, ба инобат мегирем.",
+	"rating.ask_comment":    "📝 Нависед, ки дар ҷавоб чӣ нодуруст буд.",
+	"rating.not_yours":      "Шарҳро танҳо корбаре, ки баҳо гузоштааст, навишта метавонад.",
+	"rating.comment_failed": "❌ Фикрро нигоҳ доштан муяссар нашуд",
+	"rating.comment_saved":  "🙏 Ташаккур, фикри шумо ба маъмурон фиристода шуд.",
+	"report.failed":         "❌ Хатогӣ ҳангоми тайёр кардани ҳисобот",
+	"report.title":          "📊 Баҳои ҷавобҳо барои %d рӯз",
+	"report.empty":          "Ҳоло баҳо нест.",
+	"report.model":          "• %s (prompt v%s): 👍 %d / 👎 %d",
+	"report.low":            "👎 Баҳоҳои пасти охирин:",
+	"report.answer":         "Ҷавоб: %s",
+
+	// Answer documents
+	"document.button":    "📄 Ҳамчун файл",
+	"document.subtitle":  "Ёрдамчии AI",
+	"document.page":      "Саҳ. ",
+	"document.title":     "Ҷавоби ёрдамчӣ",
+	"document.summary":   "📄 Ҷавоб дароз аст, бинобар ин ҳамчун файл фиристода шуд.",
+	"document.sections":  "**Бахшҳо:**",
+	"document.not_found": "❌ Ҷавоб ёфт нашуд",
+	"document.failed":    "❌ Файлро сохтан муяссар нашуд",
+
+	// Fields of incident and ticket cards
+	"field.category":    "Категория",
+	"field.severity":    "Ҷиддият",
+	"field.location":    "Ҷой",
+	"field.reporter":    "Хабардиҳанда",
+	"field.photos":      "Аксҳо",
+	"field.status":      "Ҳолат",
+	"field.equipment":   "Таҷҳизот",
+	"field.priority":    "Афзалият",
+	"field.author":      "Муаллиф",
+	"field.assignee":    "Иҷрокунанда",
+	"field.description": "Тавсиф",
+
+	// Incidents
+	"incident.category.injury":     "🩹 Ҷароҳат",
+	"incident.category.near_miss":  "⚠️ Вазъияти хатарнок",
+	"incident.category.equipment":  "⚙️ Носозии таҷҳизот",
+	"incident.category.fire":       "🔥 Сӯхтор, дуд",
+	"incident.category.chemical":   "🧪 Хатари кимиёвӣ",
+	"incident.category.electrical": "⚡ Хатари барқӣ",
+	"incident.category.other":      "📌 Дигар",
+	"incident.severity.low":        "🟢 Паст",
+	"incident.severity.medium":     "🟡 Миёна",
+	"incident.severity.high":       "🟠 Баланд",
+	"incident.severity.critical":   "🔴 Бӯҳронӣ",
+	"incident.status.new":          "🆕 Нав",
+	"incident.status.in_progress":  "🔎 Дар кор",
+	"incident.status.resolved":     "✅ Бартараф шуд",
+	"incident.status.rejected":     "❌ Рад шуд",
+
+	"incident.start":            "🚨 **Хабар дар бораи хатар**\n\nҚадами 1/5. Категорияро интихоб кунед. /cancel — бекор кардан.",
+	"incident.choose_category":  "Категорияро бо тугмаҳои боло интихоб кунед.",
+	"incident.category_chosen":  "Категория: %s\n\nҚадами 2/5. Ин дар куҷо рух дод? Сех, қитъа ё таҷҳизотро нишон диҳед.",
+	"incident.type_location":    "Ҷойро бо матн нависед.",
+	"incident.step_description": "Қадами 3/5. Нависед, ки чӣ рух дод ё хатар дар чист.",
+	"incident.describe":         "Вазъиятро бо матн тавсиф кунед.",
+	"incident.step_photos":      "Қадами 4/5. Акс фиристед (якчанд мумкин аст) ва «Тайёр»-ро пахш кунед ё гузаред.",
+	"incident.step_severity":    "Қадами 5/5. Ин то чӣ андоза ҷиддӣ аст?",
+	"incident.choose_severity":  "Ҷиддиятро бо тугмаҳои боло интихоб кунед.",
+	"incident.save_failed":      "❌ Хабарро нигоҳ доштан муяссар нашуд. Ба устои худ шахсан хабар диҳед!",
+	"incident.registered":       "✅ Хабар сабт шуд: **%[1]s**\nМасъулони ҳифзи меҳнат огоҳ карда шуданд. Ҳолат: /incident %[1]s",
+	"incident.status_changed":   "📣 Ҳолати хабари шумо %s: %s",
+	"incident.private_only":     "Дар бораи хатар дар чати шахсӣ бо бот хабар диҳед.",
+	"incident.not_found":        "Хабар ёфт нашуд.",
+	"incident.statuses":         "Ҳолатҳо: %s",
+
+	// Tickets
+	"ticket.priority.low":       "🟢 Паст",
+	"ticket.priority.normal":    "🟡 Муқаррарӣ",
+	"ticket.priority.high":      "🟠 Баланд",
+	"ticket.priority.urgent":    "🔴 Фаврӣ",
+	"ticket.status.open":        "🆕 Кушода",
+	"ticket.status.in_progress": "🔧 Дар кор",
+	"ticket.status.on_hold":     "⏸ Дар интизорӣ",
+	"ticket.status.closed":      "✅ Баста",
+	"ticket.status.assigned":    "👤 Иҷрокунанда таъин шуд",
+
+	"ticket.create_button":      "🛠 Сохтани дархост",
+	"ticket.draft":              "🛠 Дархости нав\n\n%s\n\nДархост сохта шавад?",
+	"ticket.button.create":      "✅ Сохтан",
+	"ticket.button.photos":      "📷 Илова кардани акс",
+	"ticket.button.start_over":  "✏️ Аз нав пур кардан",
+	"ticket.start":              "🛠 **Дархости нав**\n\nҚадами 1/4. Кадом таҷҳизот? (ном, рақами инвентарӣ, қитъа). /cancel — бекор кардан.",
+	"ticket.use_buttons":        "Амалро бо тугмаҳои боло интихоб кунед.",
+	"ticket.type_equipment":     "Номи таҷҳизотро нависед.",
+	"ticket.step_description":   "Қадами 2/4. Носозиро тавсиф кунед.",
+	"ticket.describe":           "Носозиро бо матн тавсиф кунед.",
+	"ticket.step_photos":        "Қадами 3/4. Аксҳои носозиро фиристед (якчанд мумкин аст) ва «Тайёр»-ро пахш кунед ё гузаред.",
+	"ticket.step_priority":      "Қадами 4/4. Афзалият?",
+	"ticket.choose_priority":    "Афзалиятро бо тугмаҳои боло интихоб кунед.",
+	"ticket.create_failed":      "❌ Дархостро сохтан муяссар нашуд",
+	"ticket.created":            "✅ Дархости %s сохта шуд.\n\n%s",
+	"ticket.suggestion_expired": "Пешниҳод кӯҳна шудааст",
+	"ticket.status_changed":     "🛠 Дархости %s (%s): %s",
+	"ticket.usage": "Истифода:\n" +
+		"/ticket new — дархости нав\n" +
+		"/ticket list [all|mine] — рӯйхати дархостҳо\n" +
+		"/ticket show <рақам> — тафсилот\n" +
+		"/ticket assign <рақам> <@username|id> — таъин кардани иҷрокунанда\n" +
+		"/ticket close <рақам> [шарҳ] — бастан",
+	"ticket.close_not_allowed":  "⛔ Дархостро муаллиф, иҷрокунанда ё маъмур баста метавонад.",
+	"ticket.update_failed":      "❌ Дархостро нав кардан муяссар нашуд",
+	"ticket.not_found":          "Дархост ёфт нашуд.",
+	"ticket.closed":             "✅ Дархости %s баста шуд.",
+	"ticket.list_failed":        "❌ Хатогӣ ҳангоми гирифтани дархостҳо",
+	"ticket.none":               "Дархост нест.",
+	"ticket.list_title":         "🛠 Дархостҳо:",
+	"ticket.invalid_number":     "Рақами дархост нодуруст аст.",
+	"ticket.load_failed":        "❌ Хатогӣ ҳангоми гирифтани дархост",
+	"ticket.assign_admin_only":  "⛔ Иҷрокунандаро танҳо маъмурон таъин карда метавонанд.",
+	"ticket.user_lookup_failed": "❌ Хатогӣ ҳангоми ҷустуҷӯи корбар",
+	"ticket.user_not_found":     "Корбар ёфт нашуд: ӯ бояд ақаллан як бор ба бот навишта бошад.",
+	"ticket.assign_failed":      "❌ Иҷрокунандаро таъин кардан муяссар нашуд (рақами дархостро санҷед).",
+	"ticket.assigned":           "👤 Дархости %s таъин шуд: %s\n\n%s",
+
+	// Reminders and scheduled jobs
+	"reminder.header": "⏰ Ёдрасонӣ:\n\n%s",
+	"remind.usage": "Истифода:\n" +
+		"/remind 30m Санҷидани фишор\n" +
+		"/remind 14:30 Занг задан ба назорати сифат\n" +
+		"/remind 25.12 08:00 Супоридани ҳисобот\n" +
+		"/remind cron \"0 8 * * 1-5\" Гашти қитъа\n" +
+		"/remind list — ёдрасониҳои ман\n" +
+		"/remind cancel <id> — бекор кардан",
+	"remind.failed":  "❌ Ёдрасониро сохтан муяссар нашуд: %s",
+	"remind.created": "✅ Ёдрасонӣ #%d: %s\n%s",
+	"schedule.usage": "Истифода:\n" +
+		"/schedule add \"<cron>\" <суроға> <матн>\n" +
+		"   суроға: ID-и чат, admins, safety, management (бо вергул)\n" +
+		"   мисол: /schedule add \"0 9 * * 1\" -100123456 Санҷиши ҳарҳафтаинаи равғанкорӣ\n" +
+		"/schedule list\n" +
+		"/schedule remove <id>",
+	"schedule.failed":     "❌ Вазифаро сохтан муяссар нашуд: %s",
+	"schedule.created":    "✅ Вазифа #%d → %s\nОғози навбатӣ: %s",
+	"jobs.list_failed":    "❌ Хатогӣ ҳангоми гирифтани рӯйхат",
+	"jobs.empty":          "Рӯйхат холӣ аст.",
+	"jobs.title":          "⏰ Ба нақша гирифташуда:",
+	"jobs.invalid_number": "Рақам нодуруст аст.",
+	"jobs.cancel_failed":  "❌ Хатогӣ ҳангоми бекор кардан",
+	"jobs.not_found":      "Ёфт нашуд.",
+	"jobs.cancelled":      "❎ #%d бекор карда шуд.",
+	"jobs.repeats":        "Аз рӯи ҷадвал такрор мешавад: %q",
+
+	// Shift reports
+	"shiftreport.title":          "📋 Ҳисоботи басти %d (%s), %s",
+	"shiftreport.current_title":  "📋 Ҳисоботи басти ҷории %d (%s), то %s",
+	"shiftreport.period_title":   "📋 Ҳисобот барои давраи %s — %s",
+	"shiftreport.collect_failed": "❌ Хатогӣ ҳангоми ҷамъоварии маълумот барои ҳисобот",
+	"shiftreport.failed":         "❌ Хатогӣ ҳангоми тайёр кардани ҳисобот",
+	"shiftreport.usage": "Истифода:\n" +
+		"/shiftreport — басти ҷорӣ\n" +
+		"/shiftreport 12h — 12 соати охир\n" +
+		"/shiftreport \"02.01.2006 08:00\" [\"02.01.2006 20:00\"] — давраи дилхоҳ",
+}
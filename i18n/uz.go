@@ -0,0 +1,317 @@
+package i18n
+
+var uz = map[string]string{
+	// General
+	"start": "🏭 **%s AI-yordamchisi**\n\n" +
+		"**Asosiy imkoniyatlar:**\n" +
+		"• Ishlab chiqarish jarayonlarini qo‘llab-quvvatlash\n" +
+		"• Texnik yordam va nosozliklarni aniqlash\n" +
+		"• Sifat va xavfsizlik nazorati\n" +
+		"• GOST standartlariga rioya qilish\n" +
+		"• Ishlab chiqarishni optimallashtirish\n\n" +
+		"Ishingizda yordam berishga tayyorman! Til: /lang",
+	"busy":                 "⏳ Bot band, iltimos, oldingi javoblarni kuting.",
+	"error.restarting":     "⚠️ Bot qayta ishga tushmoqda, so‘rovingiz to‘xtatildi. Bir daqiqadan so‘ng qayta urinib ko‘ring.",
+	"error.ai_unavailable": "⏳ AI xizmati vaqtincha ishlamayapti. Bir daqiqadan so‘ng qayta urinib ko‘ring.",
+	"error.request":        "❌ So‘rovni qayta ishlashda xatolik",
+	"error.image":          "❌ Rasmni qayta ishlashda xatolik",
+	"error.image_analysis": "❌ Rasmni tahlil qilishda xatolik",
+	"error.loading":        "❌ Ma’lumotlarni olishda xatolik",
+	"error.status_update":  "❌ Holatni yangilab bo‘lmadi",
+	"error.request_id":     "So‘rov kodi: `%s`",
+	"not_allowed":          "⛔ Ruxsat yo‘q",
+	"usage":                "Foydalanish: %s",
+	"history":              "Tarix:",
+	"comment":              "Izoh: %s",
+	"cancelled":            "❎ Bekor qilindi.",
+	"cancel.nothing":       "Bekor qiladigan narsa yo‘q.",
+	"dialog.ended":         "Muloqot allaqachon tugagan",
+	"dialog.not_yours":     "Bu tugmadan faqat uni boshlagan foydalanuvchi foydalana oladi.",
+	"button.done":          "✅ Tayyor",
+	"button.skip":          "⏭ O‘tkazib yuborish",
+	"photos.limit":         "Ko‘pi bilan %d ta rasm biriktirish mumkin.",
+	"photos.added":         "📷 Rasm qo‘shildi (%d).",
+	"photos.send_or_done":  "Rasm yuboring yoki «Tayyor» tugmasini bosing.",
+
+	// Commands
+	"command.unknown":    "Noma’lum buyruq. Buyruqlar ro‘yxati: /help",
+	"command.admin_only": "⛔ Bu buyruq faqat administratorlar uchun.",
+	"help.title":         "📋 Mavjud buyruqlar:",
+
+	"cmd.start":          "Yordamchini ishga tushirish",
+	"cmd.help":           "Buyruqlar ro‘yxati",
+	"cmd.lang":           "Interfeys va javoblar tili",
+	"cmd.mode":           "Yordamchi rejimi: texnik xizmat, sifat, mehnat xavfsizligi…",
+	"cmd.model":          "Javob modeli: arzonroq yoki kuchliroq",
+	"cmd.cancel":         "Joriy muloqotni bekor qilish",
+	"cmd.incident":       "Xavf haqida xabar berish yoki holatini bilish",
+	"cmd.incidentstatus": "Xavf haqidagi xabar holatini o‘zgartirish",
+	"cmd.ticket":         "Ta’mirlash arizalari: new, list, show, assign, close",
+	"cmd.remind":         "Eslatma: /remind 30m matn",
+	"cmd.schedule":       "Jadval bo‘yicha muntazam bildirishnomalar",
+	"cmd.shiftreport":    "Smena yoki davr bo‘yicha hisobot",
+	"cmd.feedback":       "Javoblar bahosi bo‘yicha hisobot",
+	"cmd.department":     "Foydalanuvchi bo‘limini belgilash",
+	"cmd.status":         "Qayta ishlash navbati holati",
+	"cmd.reload":         "Konfiguratsiya faylini qayta o‘qish",
+	"cmd.prompt":         "Tizim promptlari versiyalari",
+
+	"usage.lang":           "[ru|en|uz|tg|auto]",
+	"usage.mode":           "[rejim|auto]",
+	"usage.model":          "[model|default]",
+	"usage.incident":       "[raqam]",
+	"usage.incidentstatus": "<raqam> <holat> [izoh]",
+	"usage.ticket":         "<new|list|show|assign|close> ...",
+	"usage.remind":         "<qachon> <matn> | list | cancel <id>",
+	"usage.schedule":       "add|list|remove ...",
+	"usage.shiftreport":    "[dan] [gacha]",
+	"usage.feedback":       "[kunlar]",
+	"usage.department":     "<@username|id> [bo‘lim]",
+	"usage.prompt":         "list | show | history | edit | diff | activate | rollback",
+
+	// Language
+	"lang.choose":   "🌐 Tilni tanlang. Hozirgi: %s",
+	"lang.auto":     "🔄 Avtomatik",
+	"lang.set":      "✅ Til: %s",
+	"lang.auto_set": "✅ Til Telegram sozlamalari bo‘yicha aniqlanadi: %s",
+	"lang.failed":   "❌ Tilni saqlab bo‘lmadi",
+
+	// Assistant modes
+	"mode.general":       "🏭 Umumiy",
+	"mode.maintenance":   "🔧 Texnik xizmat",
+	"mode.quality":       "🔍 Sifat nazorati",
+	"mode.safety":        "🦺 Mehnat xavfsizligi",
+	"mode.planning":      "📅 Rejalashtirish",
+	"mode.current":       "🎛 Joriy rejim: %s\nBo‘lim: %s\n\nRejimni tanlang:",
+	"mode.no_department": "ko‘rsatilmagan",
+	"mode.auto":          "🔄 Bo‘lim bo‘yicha",
+	"mode.set":           "✅ Rejim: %s",
+	"mode.auto_set":      "✅ Rejim bo‘limingiz bo‘yicha belgilanadi: %s",
+	"mode.failed":        "❌ Rejimni saqlab bo‘lmadi",
+	"mode.unknown":       "Noma’lum rejim. Mavjud: %s",
+	"mode.no_images":     "🖼 %s rejimida fotosuratlar tahlil qilinmaydi. Rejimni almashtirish: /mode",
+	"department.set":     "✅ %s: bo‘lim «%s», standart rejim %s",
+	"department.cleared": "✅ %s bo‘limi tozalandi",
+	"department.failed":  "❌ Bo‘limni saqlab bo‘lmadi",
+
+	// Model tanlash
+	"model.current":        "🤖 Model: %s\n\nModelni tanlang (💰 narx, 🖼 rasmlarni tushunadi):",
+	"model.default":        "rejim bo‘yicha (%s)",
+	"model.button.default": "🔄 Rejim bo‘yicha",
+	"model.per_day":        "%d/kun",
+	"model.no_catalog":     "Model tanlash sozlanmagan, javoblarni %s beradi.",
+	"model.set":            "✅ Model: %s",
+	"model.set_limited":    "✅ Model: %s\nKuniga %d tagacha javob, so‘ng rejim modeli javob beradi.",
+	"model.default_set":    "✅ Model rejim bo‘yicha tanlanadi: %s",
+	"model.unknown":        "Noma’lum model. Mavjud: %s",
+	"model.not_allowed":    "⛔ %s faqat administratorlar uchun.",
+	"model.failed":         "❌ Modelni saqlab bo‘lmadi",
+	"model.limit_reached":  "⏳ %s uchun kunlik limit (%d javob) tugadi, ertagacha rejim modeli javob beradi.",
+
+	// Configuration reload
+	"reload.done":      "✅ Konfiguratsiya qayta o‘qildi. O‘zgardi: %s",
+	"reload.unchanged": "Konfiguratsiya qayta o‘qildi, o‘zgarish yo‘q.",
+	"reload.restart":   "⚠️ Qayta ishga tushirilgandan keyin kuchga kiradi: %s",
+	"reload.failed":    "❌ Konfiguratsiya qo‘llanmadi:\n%s",
+
+	// Prompt versiyalari
+	"prompt.usage": "Foydalanish:\n" +
+		"/prompt list — faol versiyalar\n" +
+		"/prompt show <nom|versiya> — prompt matni fayl sifatida\n" +
+		"/prompt history <nom> — so‘nggi versiyalar\n" +
+		"/prompt edit <nom> — yangi versiyani yuklash\n" +
+		"/prompt diff <versiya> [versiya] — versiyalarni solishtirish\n" +
+		"/prompt activate <versiya> — versiyani faollashtirish\n" +
+		"/prompt rollback <nom> — oldingi versiyaga qaytish",
+	"prompt.unknown_name":     "Noma’lum prompt. Mavjud: %s",
+	"prompt.not_found":        "Versiya topilmadi.",
+	"prompt.load_failed":      "❌ Promptlarni yuklab bo‘lmadi",
+	"prompt.list_title":       "📝 Faol promptlar (versiya · muallif · yaratilgan · belgilar):",
+	"prompt.history_title":    "📝 %s versiyalari:",
+	"prompt.active_mark":      "✅ faol",
+	"prompt.config_author":    "konfiguratsiya",
+	"prompt.edit_start":       "✏️ %s ning joriy matni ilova qilingan. Yangi matnni .txt fayl yoki xabar sifatida yuboring. /cancel — bekor qilish.",
+	"prompt.edit_expect":      ".txt fayl yoki matnli xabar yuboring.",
+	"prompt.file_invalid":     "❌ %d KB gacha bo‘lgan UTF-8 matn fayli kerak",
+	"prompt.empty":            "Prompt bo‘sh bo‘lishi mumkin emas.",
+	"prompt.template_invalid": "❌ Shablonda xato bor, hech narsa saqlanmadi:\n%v",
+	"prompt.unchanged":        "Matn faol v%d versiya bilan bir xil, hech narsa saqlanmadi.",
+	"prompt.save_failed":      "❌ Versiyani saqlab bo‘lmadi",
+	"prompt.saved":            "✅ %s v%d saqlandi, hali faol emas: +%d −%d qator",
+	"prompt.button.activate":  "✅ Faollashtirish",
+	"prompt.button.diff":      "🔍 O‘zgarishlar",
+	"prompt.activated":        "✅ %s v%d faol",
+	"prompt.activate_failed":  "❌ Versiyani faollashtirib bo‘lmadi",
+	"prompt.already_active":   "%s v%d allaqachon faol.",
+	"prompt.no_previous":      "%s ning oldingi versiyasi yo‘q.",
+	"prompt.diff_same":        "Versiyalar bir xil.",
+	"prompt.diff_title":       "🔍 %s: v%d → v%d, +%d −%d qator",
+
+	"status.report": "📈 Qayta ishlash navbati\n\n" +
+		"Ishlovchilar: %d (band: %d)\n" +
+		"Navbatda: %d (eng ko‘pi: %d, chegara: %d)\n" +
+		"Faol chatlar: %d\n" +
+		"Qayta ishlangan: %d\n" +
+		"Rad etilgan: %d",
+
+	// Answer feedback
+	"rating.save_failed":    "❌ Bahoni saqlab bo‘lmadi",
+	"rating.thanks_button":  "👍 Rahmat!",
+	"rating.thanks":         "Baho uchun rahmat!",
+	"rating.why_button":     "📝 Nima noto‘g‘ri edi?",
+	"rating.noted":          "Rahmat, inobatga olamiz.",
+	"rating.ask_comment":    "📝 Javobda nima noto‘g‘ri bo‘lganini yozing.",
+	"rating.not_yours":      "Izohni faqat baho qo‘ygan foydalanuvchi qoldira oladi.",
+	"rating.comment_failed": "❌ Fikrni saqlab bo‘lmadi",
+	"rating.comment_saved":  "🙏 Rahmat, fikringiz administratorlarga yuborildi.",
+	"report.failed":         "❌ Hisobotni tayyorlashda xatolik",
+	"report.title":          "📊 %d kunlik javob baholari",
+	"report.empty":          "Hozircha baholar yo‘q.",
+	"report.model":          "• %s (prompt v%s): 👍 %d / 👎 %d",
+	"report.low":            "👎 Oxirgi past baholar:",
+	"report.answer":         "Javob: %s",
+
+	// Answer documents
+	"document.button":    "📄 Fayl sifatida",
+	"document.subtitle":  "AI-yordamchi",
+	"document.page":      "Bet ",
+	"document.title":     "Yordamchi javobi",
+	"document.summary":   "📄 Javob uzun, shuning uchun fayl sifatida yuborildi.",
+	"document.sections":  "**Bo‘limlar:**",
+	"document.not_found": "❌ Javob topilmadi",
+	"document.failed":    "❌ Faylni yaratib bo‘lmadi",
+
+	// Fields of incident and ticket cards
+	"field.category":    "Toifa",
+	"field.severity":    "Jiddiylik",
+	"field.location":    "Joy",
+	"field.reporter":    "Xabar bergan",
+	"field.photos":      "Rasmlar",
+	"field.status":      "Holat",
+	"field.equipment":   "Uskuna",
+	"field.priority":    "Muhimlik",
+	"field.author":      "Muallif",
+	"field.assignee":    "Ijrochi",
+	"field.description": "Tavsif",
+
+	// Incidents
+	"incident.category.injury":     "🩹 Jarohat",
+	"incident.category.near_miss":  "⚠️ Xavfli vaziyat",
+	"incident.category.equipment":  "⚙️ Uskuna nosozligi",
+	"incident.category.fire":       "🔥 Yong‘in, tutun",
+	"incident.category.chemical":   "🧪 Kimyoviy xavf",
+	"incident.category.electrical": "⚡ Elektr xavfi",
+	"incident.category.other":      "📌 Boshqa",
+	"incident.severity.low":        "🟢 Past",
+	"incident.severity.medium":     "🟡 O‘rta",
+	"incident.severity.high":       "🟠 Yuqori",
+	"incident.severity.critical":   "🔴 Juda xavfli",
+	"incident.status.new":          "🆕 Yangi",
+	"incident.status.in_progress":  "🔎 Ko‘rib chiqilmoqda",
+	"incident.status.resolved":     "✅ Bartaraf etildi",
+	"incident.status.rejected":     "❌ Rad etildi",
+
+	"incident.start":            "🚨 **Xavf haqida xabar**\n\n1/5-qadam. Toifani tanlang. /cancel — bekor qilish.",
+	"incident.choose_category":  "Yuqoridagi tugmalar orqali toifani tanlang.",
+	"incident.category_chosen":  "Toifa: %s\n\n2/5-qadam. Bu qayerda sodir bo‘ldi? Sex, uchastka yoki uskunani ko‘rsating.",
+	"incident.type_location":    "Joyni matn bilan yozing.",
+	"incident.step_description": "3/5-qadam. Nima sodir bo‘lganini yoki xavf nimada ekanini yozing.",
+	"incident.describe":         "Vaziyatni matn bilan tasvirlang.",
+	"incident.step_photos":      "4/5-qadam. Rasm yuboring (bir nechta mumkin) va «Tayyor» tugmasini bosing yoki o‘tkazib yuboring.",
+	"incident.step_severity":    "5/5-qadam. Bu qanchalik jiddiy?",
+	"incident.choose_severity":  "Yuqoridagi tugmalar orqali jiddiylikni tanlang.",
+	"incident.save_failed":      "❌ Xabarni saqlab bo‘lmadi. Ustaga shaxsan xabar bering!",
+	"incident.registered":       "✅ Xabar ro‘yxatga olindi: **%[1]s**\nMehnat muhofazasi mas’ullari xabardor qilindi. Holat: /incident %[1]s",
+	"incident.status_changed":   "📣 %s raqamli xabaringiz holati: %s",
+	"incident.private_only":     "Xavf haqida bot bilan shaxsiy chatda xabar bering.",
+	"incident.not_found":        "Xabar topilmadi.",
+	"incident.statuses":         "Holatlar: %s",
+
+	// Tickets
+	"ticket.priority.low":       "🟢 Past",
+	"ticket.priority.normal":    "🟡 Oddiy",
+	"ticket.priority.high":      "🟠 Yuqori",
+	"ticket.priority.urgent":    "🔴 Shoshilinch",
+	"ticket.status.open":        "🆕 Ochiq",
+	"ticket.status.in_progress": "🔧 Bajarilmoqda",
+	"ticket.status.on_hold":     "⏸ Kutilmoqda",
+	"ticket.status.closed":      "✅ Yopilgan",
+	"ticket.status.assigned":    "👤 Ijrochi tayinlandi",
+
+	"ticket.create_button":      "🛠 Ariza yaratish",
+	"ticket.draft":              "🛠 Yangi ariza\n\n%s\n\nAriza yaratilsinmi?",
+	"ticket.button.create":      "✅ Yaratish",
+	"ticket.button.photos":      "📷 Rasm qo‘shish",
+	"ticket.button.start_over":  "✏️ Qaytadan to‘ldirish",
+	"ticket.start":              "🛠 **Yangi ariza**\n\n1/4-qadam. Qaysi uskuna? (nomi, inventar raqami, uchastka). /cancel — bekor qilish.",
+	"ticket.use_buttons":        "Yuqoridagi tugmalar orqali amalni tanlang.",
+	"ticket.type_equipment":     "Uskuna nomini yozing.",
+	"ticket.step_description":   "2/4-qadam. Nosozlikni tasvirlang.",
+	"ticket.describe":           "Nosozlikni matn bilan tasvirlang.",
+	"ticket.step_photos":        "3/4-qadam. Nosozlik rasmlarini yuboring (bir nechta mumkin) va «Tayyor» tugmasini bosing yoki o‘tkazib yuboring.",
+	"ticket.step_priority":      "4/4-qadam. Muhimlik darajasi?",
+	"ticket.choose_priority":    "Yuqoridagi tugmalar orqali muhimlikni tanlang.",
+	"ticket.create_failed":      "❌ Arizani yaratib bo‘lmadi",
+	"ticket.created":            "✅ %s raqamli ariza yaratildi.\n\n%s",
+	"ticket.suggestion_expired": "Taklif eskirgan",
+	"ticket.status_changed":     "🛠 %s ariza (%s): %s",
+	"ticket.usage": "Foydalanish:\n" +
+		"/ticket new — yangi ariza\n" +
+		"/ticket list [all|mine] — arizalar ro‘yxati\n" +
+		"/ticket show <raqam> — batafsil\n" +
+		"/ticket assign <raqam> <@username|id> — ijrochi tayinlash\n" +
+		"/ticket close <raqam> [izoh] — yopish",
+	"ticket.close_not_allowed":  "⛔ Arizani muallif, ijrochi yoki administrator yopishi mumkin.",
+	"ticket.update_failed":      "❌ Arizani yangilab bo‘lmadi",
+	"ticket.not_found":          "Ariza topilmadi.",
+	"ticket.closed":             "✅ %s ariza yopildi.",
+	"ticket.list_failed":        "❌ Arizalarni olishda xatolik",
+	"ticket.none":               "Arizalar yo‘q.",
+	"ticket.list_title":         "🛠 Arizalar:",
+	"ticket.invalid_number":     "Ariza raqami noto‘g‘ri.",
+	"ticket.load_failed":        "❌ Arizani olishda xatolik",
+	"ticket.assign_admin_only":  "⛔ Ijrochini faqat administratorlar tayinlashi mumkin.",
+	"ticket.user_lookup_failed": "❌ Foydalanuvchini qidirishda xatolik",
+	"ticket.user_not_found":     "Foydalanuvchi topilmadi: u botga kamida bir marta yozgan bo‘lishi kerak.",
+	"ticket.assign_failed":      "❌ Ijrochini tayinlab bo‘lmadi (ariza raqamini tekshiring).",
+	"ticket.assigned":           "👤 %s ariza tayinlandi: %s\n\n%s",
+
+	// Reminders and scheduled jobs
+	"reminder.header": "⏰ Eslatma:\n\n%s",
+	"remind.usage": "Foydalanish:\n" +
+		"/remind 30m Bosimni tekshirish\n" +
+		"/remind 14:30 Sifat nazoratiga qo‘ng‘iroq qilish\n" +
+		"/remind 25.12 08:00 Hisobot topshirish\n" +
+		"/remind cron \"0 8 * * 1-5\" Uchastkani aylanib chiqish\n" +
+		"/remind list — mening eslatmalarim\n" +
+		"/remind cancel <id> — bekor qilish",
+	"remind.failed":  "❌ Eslatma yaratib bo‘lmadi: %s",
+	"remind.created": "✅ Eslatma #%d: %s\n%s",
+	"schedule.usage": "Foydalanish:\n" +
+		"/schedule add \"<cron>\" <manzil> <matn>\n" +
+		"   manzil: chat ID, admins, safety, management (vergul bilan)\n" +
+		"   misol: /schedule add \"0 9 * * 1\" -100123456 Haftalik moylash tekshiruvi\n" +
+		"/schedule list\n" +
+		"/schedule remove <id>",
+	"schedule.failed":     "❌ Vazifani yaratib bo‘lmadi: %s",
+	"schedule.created":    "✅ Vazifa #%d → %s\nKeyingi ishga tushish: %s",
+	"jobs.list_failed":    "❌ Ro‘yxatni olishda xatolik",
+	"jobs.empty":          "Ro‘yxat bo‘sh.",
+	"jobs.title":          "⏰ Rejalashtirilgan:",
+	"jobs.invalid_number": "Raqam noto‘g‘ri.",
+	"jobs.cancel_failed":  "❌ Bekor qilishda xatolik",
+	"jobs.not_found":      "Topilmadi.",
+	"jobs.cancelled":      "❎ #%d bekor qilindi.",
+	"jobs.repeats":        "Jadval bo‘yicha takrorlanadi: %q",
+
+	// Shift reports
+	"shiftreport.title":          "📋 %d-smena hisoboti (%s), %s",
+	"shiftreport.current_title":  "📋 Joriy %d-smena hisoboti (%s), %s holatiga",
+	"shiftreport.period_title":   "📋 %s — %s davr uchun hisobot",
+	"shiftreport.collect_failed": "❌ Hisobot uchun ma’lumot yig‘ishda xatolik",
+	"shiftreport.failed":         "❌ Hisobotni yaratishda xatolik",
+	"shiftreport.usage": "Foydalanish:\n" +
+		"/shiftreport — joriy smena\n" +
+		"/shiftreport 12h — oxirgi 12 soat\n" +
+		"/shiftreport \"02.01.2006 08:00\" [\"02.01.2006 20:00\"] — ixtiyoriy davr",
+}
@@ -0,0 +1,72 @@
+package instructions
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Context holds the variables available to prompt templates, e.g.
+// {{.PlantName}} or {{if .Shift}}shift {{.Shift}}{{end}}.
+type Context struct {
+	PlantName     string
+	PlantLocation string
+	Now           time.Time // plant local time
+	Date          string    // "02.01.2006"
+	Time          string    // "15:04"
+	Weekday       string    // English name, e.g. "Monday"
+	Shift         int       // 1-based shift number, 0 outside shift hours
+	ShiftTime     string    // e.g. "08:00–20:00"
+	UserName      string    // empty in group chats and shift reports
+	Department    string
+	Role          string   // "admin" or "user"
+	Equipment     []string // equipment in service
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Render executes a prompt template.
+func Render(text string, ctx Context) (string, error) {
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, ctx); err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// Validate reports template syntax errors and unknown variables.
+func Validate(text string) error {
+	now := time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC)
+	_, err := Render(text, Context{
+		PlantName:     "Plant",
+		PlantLocation: "City",
+		Now:           now,
+		Date:          now.Format("02.01.2006"),
+		Time:          now.Format("15:04"),
+		Weekday:       now.Weekday().String(),
+		Shift:         1,
+		ShiftTime:     "08:00–20:00",
+		UserName:      "User",
+		Department:    "department",
+		Role:          "user",
+		Equipment:     []string{"equipment"},
+	})
+	return err
+}
+
+func parse(text string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return tmpl, nil
+}
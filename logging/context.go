@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"factory_bot/tracing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Request identifies the work a context belongs to: an update being handled
+// or a background job run. Its fields are added to every entry logged
+// through From.
+type Request struct {
+	ID       string // random, quoted by users to support from error replies
+	UpdateID int    // Telegram update, 0 for background jobs
+	ChatID   int64
+	UserID   int64
+	Job      string // background job name, empty for updates
+}
+
+type requestKey struct{}
+
+// NewRequestID returns a short random ID.
+func NewRequestID() string {
+	var b [6]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// WithRequest returns a context carrying req.
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// RequestFrom returns the request ctx belongs to.
+func RequestFrom(ctx context.Context) (Request, bool) {
+	req, ok := ctx.Value(requestKey{}).(Request)
+	return req, ok
+}
+
+// RequestID returns the ID of the request ctx belongs to, or "".
+func RequestID(ctx context.Context) string {
+	req, _ := RequestFrom(ctx)
+	return req.ID
+}
+
+// From returns a log entry carrying the fields of the request ctx belongs to
+// and the ID of the trace it is part of.
+func From(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		fields["trace_id"] = traceID
+	}
+	req, ok := RequestFrom(ctx)
+	if !ok {
+		return logrus.WithFields(fields)
+	}
+
+	fields["request_id"] = req.ID
+	if req.UpdateID != 0 {
+		fields["update_id"] = req.UpdateID
+	}
+	if req.ChatID != 0 {
+		fields["chat_id"] = req.ChatID
+	}
+	if req.UserID != 0 {
+		fields["user_id"] = req.UserID
+	}
+	if req.Job != "" {
+		fields["job"] = req.Job
+	}
+	return logrus.WithFields(fields)
+}
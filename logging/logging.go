@@ -0,0 +1,41 @@
+// Package logging configures logrus for the bot: level, format, secret
+// redaction and request-scoped fields.
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Configure sets the level and format of the standard logger.
+func Configure(level, format string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case FormatText:
+		logrus.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp: true,
+		})
+	case FormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+		})
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	logrus.SetLevel(lvl)
+	return nil
+}
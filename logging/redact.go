@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces secrets in log output.
+const Redacted = "[REDACTED]"
+
+// Token shapes masked even when the value is not known up front: Telegram bot
+// tokens (also inside api.telegram.org/bot<token>/ URLs) and OpenRouter keys.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\d{6,}:[A-Za-z0-9_-]{30,}`),
+	regexp.MustCompile(`sk-or-[A-Za-z0-9_-]{20,}`),
+}
+
+// RedactHook masks secrets in the message and fields of every log entry.
+type RedactHook struct {
+	secrets []string
+}
+
+// NewRedactHook returns a hook masking the given secrets and anything shaped
+// like a bot token or API key. Empty secrets are ignored.
+func NewRedactHook(secrets ...string) *RedactHook {
+	h := &RedactHook{}
+	for _, s := range secrets {
+		if s != "" {
+			h.secrets = append(h.secrets, s)
+		}
+	}
+	return h
+}
+
+// Levels reports that the hook applies to all levels.
+func (h *RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire rewrites the entry in place before it is formatted.
+func (h *RedactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.Redact(entry.Message)
+	for key, value := range entry.Data {
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		case error:
+			text = v.Error()
+		case fmt.Stringer:
+			text = v.String()
+		default:
+			continue
+		}
+		if redacted := h.Redact(text); redacted != text {
+			entry.Data[key] = redacted
+		}
+	}
+	return nil
+}
+
+// Redact returns s with all secrets masked.
+func (h *RedactHook) Redact(s string) string {
+	for _, secret := range h.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, Redacted)
+	}
+	return s
+}
+
+// Install adds a RedactHook for the given secrets to the standard logger and
+// returns it for masking output that bypasses logrus.
+func Install(secrets ...string) *RedactHook {
+	h := NewRedactHook(secrets...)
+	logrus.AddHook(h)
+	return h
+}
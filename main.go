@@ -1,60 +1,114 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"factory_bot/bot"
 	"factory_bot/config"
+	"factory_bot/logging"
+	"factory_bot/tracing"
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 )
 
+// tracingShutdownTimeout bounds exporting the last spans on exit.
+const tracingShutdownTimeout = 5 * time.Second
+
 func main() {
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
+	healthcheck := len(os.Args) > 1 && os.Args[1] == "healthcheck"
+	if err := godotenv.Load(); err != nil && !healthcheck {
 		logrus.Warn("No .env file found")
 	}
 
-	// Load configuration
-	cfg := config.Load()
-
-	// Validate required configuration
-	if cfg.BotToken == "" {
-		log.Fatal("BOT_TOKEN is required")
+	// Query the running bot instead of starting one
+	if healthcheck {
+		os.Exit(runHealthcheck(os.Args[2:]))
 	}
-	if cfg.OpenRouterKey == "" {
-		log.Fatal("OPENROUTER_KEY is required")
+
+	// Load and validate configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	// Configure logging
-	logrus.SetLevel(logrus.InfoLevel)
-	logrus.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
+	if err := logging.Configure(cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatal(err)
+	}
+	redactor := logging.Install(cfg.BotToken, cfg.OpenRouterKey, cfg.WebhookSecret)
+
+	// Export spans
+	err = tracing.Setup(tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		ServiceName: cfg.TracingServiceName,
+		Redact:      redactor.Redact,
 	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Initialize bot
 	botInstance, err := bot.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize bot: %v", err)
+		logrus.Fatalf("Failed to initialize bot: %v", err) // logrus masks the token in API errors
 	}
 
-	// Start bot in goroutine
+	// Receiving stops on interrupt or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// SIGHUP re-reads the config file
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
 	go func() {
-		if err := botInstance.Start(); err != nil {
-			log.Fatalf("Failed to start bot: %v", err)
+		for range hangup {
+			botInstance.Reload() // logs the outcome
 		}
 	}()
 
+	// Start bot in goroutine
+	done := make(chan error, 1)
+	go func() {
+		done <- botInstance.Start(ctx)
+	}()
+
+	if cfg.File != "" {
+		logrus.WithField("file", cfg.File).Info("📝 Configuration file loaded")
+	}
 	logrus.Info("Sector Prom Factory Bot is running. Press CTRL+C to exit.")
 
-	// Wait for interrupt signal
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
+	// Wait for a signal or for the bot to stop on its own
+	select {
+	case <-ctx.Done():
+		<-done
+	case err := <-done:
+		if err != nil {
+			logrus.WithError(err).Error("Bot stopped with error")
+		}
+		stop()
+	}
 
 	logrus.Info("Shutting down bot...")
+
+	// Let in-flight requests finish before closing the database
+	botInstance.Shutdown(cfg.ShutdownGracePeriod)
+	if err := botInstance.Close(); err != nil {
+		logrus.WithError(err).Error("Failed to close database")
+	}
+
+	// Export the spans still queued
+	traceCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	tracing.Shutdown(traceCtx)
+	cancel()
+
+	logrus.Info("Bot stopped")
 }
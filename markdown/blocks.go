@@ -0,0 +1,203 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	fenceRe    = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+#.-]*)")
+	headingRe  = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleRe     = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	listRe     = regexp.MustCompile(`^(\s*)([-*+•]|\d{1,9}[.)])\s+(.*)$`)
+	taskRe     = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	quoteRe    = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	tableSepRe = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	spanTagRe  = regexp.MustCompile(`<(/?)(b|i|s|code|a)(?: href="([^"]*)")?>`)
+)
+
+// BlockKind is the type of a Markdown block.
+type BlockKind int
+
+const (
+	BlockParagraph BlockKind = iota // one line of a paragraph
+	BlockBlank                      // empty line between paragraphs
+	BlockHeading
+	BlockListItem
+	BlockQuote
+	BlockCode
+	BlockTable
+	BlockRule
+)
+
+// Block is a line-level element of a Markdown document. Text is inline
+// Markdown except for code blocks, where it is the raw code.
+type Block struct {
+	Kind   BlockKind
+	Level  int    // heading level 1–6, or list nesting level from 0
+	Marker string // list bullet, number ("1.") or checkbox
+	Text   string // lines of a quote are separated by "\n"
+	Lang   string // code block language
+	Rows   [][]string
+
+	aligns []align
+}
+
+// Parse splits Markdown into blocks. Paragraphs are kept line by line, as
+// chat messages use single line breaks for layout.
+func Parse(src string) []Block {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var blocks []Block
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		// Fenced code block; an unterminated fence runs to the end of the text
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			fence := m[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, Block{Kind: BlockCode, Lang: m[2], Text: strings.Join(code, "\n")})
+			continue
+		}
+
+		// Table: a row followed by a separator line
+		if strings.Contains(line, "|") && i+1 < len(lines) && tableSepRe.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-") {
+			rows := [][]string{splitRow(line)}
+			aligns := parseAligns(lines[i+1])
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+				rows = append(rows, splitRow(lines[i]))
+			}
+			i--
+			blocks = append(blocks, Block{Kind: BlockTable, Rows: rows, aligns: aligns})
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, Block{Kind: BlockHeading, Level: len(m[1]), Text: m[2]})
+			continue
+		}
+
+		if ruleRe.MatchString(line) {
+			blocks = append(blocks, Block{Kind: BlockRule})
+			continue
+		}
+
+		if quoteRe.MatchString(line) {
+			var quoted []string
+			for ; i < len(lines); i++ {
+				m := quoteRe.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				quoted = append(quoted, m[1])
+			}
+			i--
+			blocks = append(blocks, Block{Kind: BlockQuote, Text: strings.Join(quoted, "\n")})
+			continue
+		}
+
+		if m := listRe.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, listItem(m[1], m[2], m[3]))
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			blocks = append(blocks, Block{Kind: BlockBlank})
+			continue
+		}
+		blocks = append(blocks, Block{Kind: BlockParagraph, Text: line})
+	}
+
+	return blocks
+}
+
+func listItem(indent, marker, text string) Block {
+	width := 0
+	for _, r := range indent {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	level := width / 2
+
+	switch marker {
+	case "-", "*", "+", "•":
+		bullets := []string{"•", "◦", "▪"}
+		marker = bullets[min(level, len(bullets)-1)]
+	}
+
+	if m := taskRe.FindStringSubmatch(text); m != nil {
+		marker = "☐"
+		if m[1] != " " {
+			marker = "☑"
+		}
+		text = text[len(m[0]):]
+	}
+
+	return Block{Kind: BlockListItem, Level: level, Marker: marker, Text: text}
+}
+
+// Span is a run of text with uniform formatting.
+type Span struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Strike bool
+	Code   bool
+	URL    string // link target, if the span is part of a link
+}
+
+// Spans splits inline Markdown into formatted runs.
+func Spans(text string) []Span {
+	rendered := renderInline(text, true)
+
+	var spans []Span
+	var cur Span
+	emit := func(s string) {
+		if s != "" {
+			span := cur
+			span.Text = html.UnescapeString(s)
+			spans = append(spans, span)
+		}
+	}
+
+	pos := 0
+	for _, m := range spanTagRe.FindAllStringSubmatchIndex(rendered, -1) {
+		emit(rendered[pos:m[0]])
+		pos = m[1]
+
+		closing := rendered[m[2]:m[3]] == "/"
+		switch rendered[m[4]:m[5]] {
+		case "b":
+			cur.Bold = !closing
+		case "i":
+			cur.Italic = !closing
+		case "s":
+			cur.Strike = !closing
+		case "code":
+			cur.Code = !closing
+		case "a":
+			cur.URL = ""
+			if !closing && m[6] >= 0 {
+				cur.URL = html.UnescapeString(rendered[m[6]:m[7]])
+			}
+		}
+	}
+	emit(rendered[pos:])
+
+	return spans
+}
+
+// PlainInline returns inline Markdown as plain text.
+func PlainInline(text string) string {
+	return stripTags(renderInline(text, false))
+}
@@ -0,0 +1,508 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// UTF16Len returns the length of s in UTF-16 code units, the unit Telegram
+// uses for message limits and entity offsets.
+func UTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if size := utf16.RuneLen(r); size > 0 {
+			n += size
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// Split cuts Markdown into parts whose rendered text fits in limit UTF-16
+// code units. It prefers paragraph, then line, sentence and word boundaries.
+// A code block cut in two is closed and reopened with its fence, and bold,
+// italic, strikethrough and inline code spans are closed at the end of a part
+// and reopened in the next, so every part renders on its own.
+func Split(src string, limit int) []string {
+	s := &splitter{
+		limit:   limit,
+		measure: func(part string) int { return UTF16Len(ToPlainText(part)) },
+		balance: true,
+	}
+	return s.split(src)
+}
+
+// SplitPlain cuts plain text into parts of at most limit UTF-16 code units
+// at the same boundaries as Split.
+func SplitPlain(text string, limit int) []string {
+	s := &splitter{limit: limit, measure: UTF16Len}
+	return s.split(text)
+}
+
+// splitter builds parts from units (blocks, lines, sentences, words) measured
+// one at a time, adding up their sizes instead of measuring every candidate
+// part, which would take quadratic time. Units rendered apart may differ from
+// the part rendered as a whole, so each finished part is measured once more
+// and the rare one that is too long is cut again, measuring every candidate.
+type splitter struct {
+	limit   int
+	measure func(string) int
+	balance bool // keep Markdown delimiters balanced across parts
+	exact   bool // measure candidate parts instead of adding up unit sizes
+}
+
+// block is a paragraph or a fenced code block with the separator that
+// preceded it in the source.
+type block struct {
+	text  string
+	sep   string
+	fence string // closing fence for code blocks
+}
+
+func (s *splitter) fits(text string) bool {
+	return s.measure(text) <= s.limit
+}
+
+func (s *splitter) split(src string) []string {
+	src = strings.TrimSpace(strings.ReplaceAll(src, "\r\n", "\n"))
+	if s.fits(src) {
+		return []string{src}
+	}
+
+	var parts []string
+	cur, curSize := "", 0
+	curCode := false // cur ends with a code block and cannot be split as text
+	for _, blk := range splitBlocks(src) {
+		size := s.measure(blk.text)
+		if cur != "" && curSize+UTF16Len(blk.sep)+size <= s.limit {
+			cur += blk.sep + blk.text
+			curSize += UTF16Len(blk.sep) + size
+			curCode = blk.fence != ""
+			continue
+		}
+
+		var pieces []string
+		switch {
+		case size <= s.limit:
+			pieces = []string{blk.text}
+		case blk.fence != "":
+			pieces = s.splitCode(blk)
+		case cur != "" && !curCode:
+			// Fill the current part with the start of the paragraph, so a
+			// heading is not sent on its own
+			pieces = s.splitText(cur+blk.sep+blk.text, 0)
+			cur = ""
+		default:
+			pieces = s.splitText(blk.text, 0)
+		}
+		if s.balance && blk.fence == "" {
+			pieces = balanceDelimiters(pieces)
+		}
+
+		if cur != "" {
+			parts = append(parts, cur)
+		}
+		parts = append(parts, pieces[:len(pieces)-1]...)
+		cur = pieces[len(pieces)-1]
+		curSize = s.measure(cur)
+		curCode = blk.fence != ""
+	}
+	if cur != "" {
+		parts = append(parts, cur)
+	}
+
+	var result []string
+	for _, part := range parts {
+		part = strings.TrimRightFunc(part, unicode.IsSpace)
+		switch {
+		case part == "":
+		case s.exact || s.fits(part):
+			result = append(result, part)
+		default:
+			exact := *s
+			exact.exact = true
+			result = append(result, exact.split(part)...)
+		}
+	}
+	return result
+}
+
+// splitBlocks breaks src into paragraphs and fenced code blocks.
+func splitBlocks(src string) []block {
+	var blocks []block
+	var cur []string
+	sep := ""
+	blanks := 0
+
+	flush := func() {
+		if len(cur) > 0 {
+			blocks = append(blocks, block{text: strings.Join(cur, "\n"), sep: sep})
+			cur = nil
+		}
+	}
+	separator := func() string {
+		if len(blocks) == 0 && len(cur) == 0 {
+			return ""
+		}
+		return strings.Repeat("\n", blanks+1)
+	}
+
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			flush()
+			code := []string{line}
+			closed := false
+			for i++; i < len(lines); i++ {
+				code = append(code, lines[i])
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]) {
+					closed = true
+					break
+				}
+			}
+			if !closed {
+				code = append(code, m[1])
+			}
+			blocks = append(blocks, block{text: strings.Join(code, "\n"), sep: separator(), fence: m[1]})
+			blanks = 0
+			sep = ""
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			blanks++
+			continue
+		}
+
+		if len(cur) == 0 {
+			sep = separator()
+			blanks = 0
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return blocks
+}
+
+// splitCode cuts a fenced code block between lines, repeating the opening
+// and closing fence in every piece.
+func (s *splitter) splitCode(blk block) []string {
+	lines := strings.Split(blk.text, "\n")
+	open := lines[0]
+	body := lines[1 : len(lines)-1]
+	wrap := func(chunk []string) string {
+		return open + "\n" + strings.Join(chunk, "\n") + "\n" + blk.fence
+	}
+
+	// Code is rendered verbatim, so a piece measures the fences plus its lines
+	overhead := s.measure(wrap([]string{""}))
+	var pieces []string
+	var chunk []string
+	size := overhead
+	for _, line := range body {
+		lineSize := UTF16Len(line)
+		if len(chunk) > 0 && size+1+lineSize > s.limit {
+			pieces = append(pieces, wrap(chunk))
+			chunk, size = nil, overhead
+		}
+		if len(chunk) == 0 && overhead+lineSize > s.limit {
+			// A single line longer than a message
+			for _, piece := range hardSplit(line, s.limit-overhead) {
+				pieces = append(pieces, wrap([]string{piece}))
+			}
+			continue
+		}
+		if len(chunk) > 0 {
+			size++
+		}
+		size += lineSize
+		chunk = append(chunk, line)
+	}
+	if len(chunk) > 0 {
+		pieces = append(pieces, wrap(chunk))
+	}
+	return pieces
+}
+
+// fitsAfter reports whether unit may be added to the part cur.
+func (s *splitter) fitsAfter(cur string, curSize int, sep string, sepSize int, unit string, size int) bool {
+	if s.exact {
+		return s.fits(cur + sep + unit)
+	}
+	return curSize+sepSize+size <= s.limit
+}
+
+// splitText cuts text at line (level 0), sentence (1) and word (2)
+// boundaries, falling back to cutting between characters.
+func (s *splitter) splitText(text string, level int) []string {
+	if s.fits(text) {
+		return []string{text}
+	}
+
+	var units []string
+	sep := ""
+	switch level {
+	case 0:
+		units, sep = strings.Split(text, "\n"), "\n"
+	case 1:
+		units = splitSentences(text)
+	case 2:
+		units = splitWords(text)
+	default:
+		return hardSplit(text, s.limit)
+	}
+
+	var parts []string
+	cur, curSize, has := "", 0, false
+	sepSize := UTF16Len(sep)
+	for _, unit := range units {
+		size := s.measure(unit)
+		if has && s.fitsAfter(cur, curSize, sep, sepSize, unit, size) {
+			cur += sep + unit
+			curSize += sepSize + size
+			continue
+		}
+		if size <= s.limit {
+			if has {
+				parts = append(parts, cur)
+			}
+			cur, curSize, has = unit, size, true
+			continue
+		}
+		// The unit needs splitting anyway; let its first piece fill the current part
+		if has {
+			unit = cur + sep + unit
+		}
+		sub := s.splitText(unit, level+1)
+		parts = append(parts, sub[:len(sub)-1]...)
+		cur, has = sub[len(sub)-1], true
+		curSize = s.measure(cur)
+	}
+	if has {
+		parts = append(parts, cur)
+	}
+	return parts
+}
+
+// splitSentences cuts text after sentence-ending punctuation followed by
+// whitespace, keeping the punctuation and whitespace with the sentence.
+// Decimals such as "0.5 мм" are not sentence ends.
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !strings.ContainsRune(".!?…", runes[i]) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && strings.ContainsRune(".!?…\"'»)]*_", runes[j]) {
+			j++
+		}
+		if j < len(runes) && !unicode.IsSpace(runes[j]) {
+			continue
+		}
+		for j < len(runes) && unicode.IsSpace(runes[j]) {
+			j++
+		}
+		sentences = append(sentences, string(runes[start:j]))
+		start = j
+		i = j - 1
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// splitWords cuts text after each run of whitespace.
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	inSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if inSpace && !space {
+			words = append(words, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	return append(words, text[start:])
+}
+
+// hardSplit cuts text between characters into pieces of at most limit UTF-16 code units.
+func hardSplit(text string, limit int) []string {
+	limit = max(limit, 2)
+	var pieces []string
+	start, n := 0, 0
+	for i, r := range text {
+		size := utf16.RuneLen(r)
+		if size < 0 {
+			size = 1
+		}
+		if n+size > limit {
+			pieces = append(pieces, text[start:i])
+			start, n = i, 0
+		}
+		n += size
+	}
+	return append(pieces, text[start:])
+}
+
+// delimiter is an emphasis or code span delimiter left open at the end of a
+// piece. pos is its offset in the joined pieces and identifies it across them.
+type delimiter struct {
+	text string
+	pos  int
+}
+
+// balanceDelimiters closes inline code and emphasis left open at the end of a
+// piece and reopens it at the start of the next one. Delimiters that are never
+// closed in the text stay literal, as they would in a single message.
+func balanceDelimiters(pieces []string) []string {
+	joined := strings.Join(pieces, "\n")
+	opened := make([][]delimiter, len(pieces)+1)
+	unclosed := make(map[int]bool)
+	start := 0
+	for i, piece := range pieces {
+		opened[i+1] = openDelimiters(joined, start, start+len(piece), opened[i], unclosed)
+		start += len(piece) + 1
+	}
+	for _, d := range opened[len(pieces)] {
+		unclosed[d.pos] = true
+	}
+
+	for i, piece := range pieces {
+		var openers, closers strings.Builder
+		for _, d := range opened[i] {
+			if !unclosed[d.pos] {
+				openers.WriteString(d.text)
+			}
+		}
+		open := opened[i+1]
+		for j := len(open) - 1; j >= 0; j-- {
+			if !unclosed[open[j].pos] {
+				closers.WriteString(open[j].text)
+			}
+		}
+		pieces[i] = openers.String() + strings.TrimRightFunc(piece, unicode.IsSpace) + closers.String()
+	}
+	return pieces
+}
+
+// openDelimiters scans the piece src[start:end], which continues with the
+// delimiters in open still open, and returns the delimiters open at its end
+// in opening order. It follows the rules of the inline renderer, so only
+// delimiters that render as formatting once closed are counted. Delimiters
+// skipped by a closer further out are added to unclosed.
+func openDelimiters(src string, start, end int, open []delimiter, unclosed map[int]bool) []delimiter {
+	stack := append([]delimiter(nil), open...)
+
+	i := start
+	if n := len(stack); n > 0 && stack[n-1].text[0] == '`' {
+		// The piece starts inside a code span
+		next := closingBackticks(src, start, len(stack[n-1].text))
+		if next < 0 || next > end {
+			return stack
+		}
+		stack = stack[:n-1]
+		i = next
+	}
+
+	for i < end {
+		c := src[i]
+		switch {
+		case c == '\\':
+			i += 2
+		case c == '`':
+			n := runLength(src, i, '`')
+			next := closingBackticks(src, i+n, n)
+			switch {
+			case next < 0:
+				// Never closed, so the backticks are literal
+				i += n
+			case next > end:
+				return append(stack, delimiter{src[i : i+n], i})
+			default:
+				i = next
+			}
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(src, i, c)
+			stack = emphasisRun(src[:end], i, n, stack, i == start, unclosed)
+			i += n
+		default:
+			i++
+		}
+	}
+	return stack
+}
+
+// closingBackticks returns the index after the first run of exactly n
+// backticks at or after from, or -1.
+func closingBackticks(text string, from, n int) int {
+	for j := from; j < len(text); {
+		k := strings.IndexByte(text[j:], '`')
+		if k < 0 {
+			break
+		}
+		j += k
+		m := runLength(text, j, '`')
+		if m == n {
+			return j + m
+		}
+		j += m
+	}
+	return -1
+}
+
+// emphasisRun applies the run of n delimiter characters at text[i] to stack:
+// it closes the innermost delimiter it matches, or opens new ones. first
+// reports whether the run starts a piece, right after the reopened delimiters.
+func emphasisRun(text string, i, n int, stack []delimiter, first bool, unclosed map[int]bool) []delimiter {
+	c := text[i]
+	if n > 3 || (c == '~' && n != 2) {
+		return stack
+	}
+	prev, next := byte(' '), byte(' ')
+	if first && len(stack) > 0 {
+		prev = c
+	} else if i > 0 {
+		prev = text[i-1]
+	}
+	if i+n < len(text) {
+		next = text[i+n]
+	}
+
+	if !isSpace(prev) && (c != '_' || !isWordByte(next)) {
+		top := len(stack) - 1
+		if n == 3 && top >= 1 && stack[top].text[0] == c && stack[top-1].text[0] == c &&
+			len(stack[top].text)+len(stack[top-1].text) == 3 {
+			return stack[:top-1]
+		}
+		for j := top; j >= 0; j-- {
+			if stack[j].text == text[i:i+n] {
+				// Delimiters opened inside and not closed there stay literal
+				for _, d := range stack[j+1:] {
+					unclosed[d.pos] = true
+				}
+				return stack[:j]
+			}
+		}
+	}
+
+	if isSpace(next) || ((c == '_' || n == 1) && i > 0 && isWordByte(prev)) {
+		return stack
+	}
+	if n == 3 {
+		// Bold and italic opened together; reopening them as *** is read the
+		// same way by the renderer
+		return append(stack, delimiter{text[i : i+2], i}, delimiter{text[i+2 : i+3], i + 2})
+	}
+	return append(stack, delimiter{text[i : i+n], i})
+}
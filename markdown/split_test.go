@@ -0,0 +1,173 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestUTF16Len(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"Привет", 6},
+		{"😀", 2},
+		{"a😀б", 4},
+		{"\xff", 1},
+	}
+
+	for _, tt := range tests {
+		if got := UTF16Len(tt.s); got != tt.want {
+			t.Errorf("UTF16Len(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		limit int
+		want  []string
+	}{
+		{"fits", "**short**", 10, []string{"**short**"}},
+		{"paragraphs", "first one\n\nsecond one", 12, []string{"first one", "second one"}},
+		{"paragraphs share a part", "one\n\ntwo\n\nthree four", 10, []string{"one\n\ntwo", "three four"}},
+		// Rows summed one by one are shorter than the padded table; the part is cut again
+		{"table longer than its rows", "|abc|d|\n|-|-|\n|x|y|", 20, []string{"|abc|d|\n|-|-|", "|x|y|"}},
+		{"decimals are not sentence ends", "Зазор 0.5 мм. Проверить щуп.", 16, []string{"Зазор 0.5 мм.", "Проверить щуп."}},
+		{"bold", "**one two three four**", 12, []string{"**one two**", "**three four**"}},
+		{"italic", "*one two three four*", 11, []string{"*one two*", "*three four*"}},
+		{"underscore italic", "_one two three four_", 11, []string{"_one two_", "_three four_"}},
+		{"strikethrough", "~~one two three four~~", 12, []string{"~~one two~~", "~~three four~~"}},
+		{"bold italic", "***one two three***", 11, []string{"***one two***", "***three***"}},
+		{"italic inside bold", "**bold text *italic words* and `code span` end of it**", 20,
+			[]string{"**bold text *italic***", "***words* and `code`**", "**`span` end of it**"}},
+		{"code inside bold", "**bold with `code one two three four` tail**", 15,
+			[]string{"**bold with**", "**`code one two`**", "**`three four`**", "**tail**"}},
+		{"unclosed bold", "**open bold never closed", 10, []string{"**open", "bold", "never", "closed"}},
+		{"unclosed italic", "*open italic never closed", 10, []string{"*open", "italic", "never", "closed"}},
+		{"unclosed code", "`open code never closed", 11, []string{"`open code", "never", "closed"}},
+		{"unclosed inside closed", "**bold *open and more words**", 12, []string{"**bold**", "***open and**", "**more words**"}},
+		{"snake case", "snake_case words after snake_case", 12, []string{"snake_case", "words after", "snake_case"}},
+		{"escaped delimiters", `\*not italic one two three\*`, 10, []string{`\*not`, "italic", "one two", `three\*`}},
+		{"entities are text", "a &lt; b &amp; c &gt; d", 8, []string{"a &lt;", "b &amp;", "c &gt; d"}},
+		{"code block", "```go\nline one\nline two\n```", 10,
+			[]string{"```go\nline one\n```", "```go\nline two\n```"}},
+		{"surrogate pairs", "😀😀😀😀😀", 4, []string{"😀😀", "😀😀", "😀"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.src, tt.limit)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q, %d)\n got %q\nwant %q", tt.src, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSplitRendersParts checks that every part fits and renders with its
+// formatting, whatever the split point.
+func TestSplitRendersParts(t *testing.T) {
+	src := "**Bold with *italic* and `code` inside** then *italic with **bold** inside* and ~~struck text~~."
+	for limit := 12; limit <= UTF16Len(ToPlainText(src)); limit++ {
+		for _, part := range Split(src, limit) {
+			if n := UTF16Len(ToPlainText(part)); n > limit {
+				t.Errorf("limit %d: part %q is %d long", limit, part, n)
+			}
+			if plain := ToPlainText(part); strings.ContainsAny(plain, "*~`") {
+				t.Errorf("limit %d: part %q renders with literal markers: %q", limit, part, plain)
+			}
+		}
+	}
+}
+
+func TestSplitTelegramLimit(t *testing.T) {
+	const limit = 4096
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"emoji across the limit", strings.Repeat("я", limit-1) + "😀b"},
+		{"emoji only", strings.Repeat("😀", limit)},
+		{"bold emoji", "**" + strings.Repeat("😀", limit) + "**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := Split(tt.src, limit)
+			plain := SplitPlain(tt.src, limit)
+			if len(parts) < 2 || len(plain) < 2 {
+				t.Fatalf("got %d and %d parts, want at least 2", len(parts), len(plain))
+			}
+
+			var rendered strings.Builder
+			for _, part := range parts {
+				if n := UTF16Len(ToPlainText(part)); n > limit {
+					t.Errorf("Split: part is %d UTF-16 units long", n)
+				}
+				rendered.WriteString(ToPlainText(part))
+			}
+			if rendered.String() != ToPlainText(tt.src) {
+				t.Error("Split: parts do not add up to the text")
+			}
+
+			for _, part := range plain {
+				if !utf8.ValidString(part) {
+					t.Errorf("SplitPlain: part ends in a broken character: %q", part[len(part)-4:])
+				}
+				if n := UTF16Len(part); n > limit {
+					t.Errorf("SplitPlain: part is %d UTF-16 units long", n)
+				}
+			}
+			if strings.Join(plain, "") != tt.src {
+				t.Error("SplitPlain: parts do not add up to the text")
+			}
+		})
+	}
+}
+
+func TestSplitPlain(t *testing.T) {
+	tests := []struct {
+		src   string
+		limit int
+		want  []string
+	}{
+		{"**not markdown** one", 10, []string{"**not mark", "down** one"}},
+		{"Первая строка.\nВторая строка.", 15, []string{"Первая строка.", "Вторая строка."}},
+		{"abcdef", 4, []string{"abcd", "ef"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitPlain(tt.src, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitPlain(%q, %d) = %q, want %q", tt.src, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkSplit(b *testing.B) {
+	paragraph := "Проверьте **давление масла** в гидросистеме пресса: норма 0.5–0.7 МПа, см. `ГОСТ 17216`. " +
+		"При отклонении *остановите* станок и сообщите мастеру смены.\n\n"
+	benchmarks := []struct {
+		name string
+		src  string
+	}{
+		{"answer", strings.Repeat(paragraph, 100)},
+		{"long line", strings.Repeat(strings.TrimSpace(paragraph)+" ", 150)},
+		{"unmatched delimiters", strings.Repeat("*a ", 2000)},
+		{"code block", "```\n" + strings.Repeat("x := a*b + c_d // `comment`\n", 800) + "```"},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Split(bm.src, 4080)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignRight
+	alignCenter
+)
+
+// splitRow splits a table row into trimmed cells, honouring escaped pipes.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func parseAligns(separator string) []align {
+	var aligns []align
+	for _, cell := range splitRow(separator) {
+		left := strings.HasPrefix(cell, ":")
+		right := strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			aligns = append(aligns, alignCenter)
+		case right:
+			aligns = append(aligns, alignRight)
+		default:
+			aligns = append(aligns, alignLeft)
+		}
+	}
+	return aligns
+}
+
+// renderTable lays a table out as an aligned monospace block. Inline
+// formatting inside cells is dropped, since <pre> cannot contain other tags.
+func renderTable(rows [][]string, aligns []align) string {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+
+	plain := make([][]string, len(rows))
+	widths := make([]int, columns)
+	for r, row := range rows {
+		plain[r] = make([]string, columns)
+		for c := 0; c < columns; c++ {
+			if c < len(row) {
+				plain[r][c] = stripTags(renderInline(row[c], false))
+			}
+			widths[c] = max(widths[c], utf8.RuneCountInString(plain[r][c]))
+		}
+	}
+
+	separator := make([]string, columns)
+	for c, w := range widths {
+		separator[c] = strings.Repeat("-", w)
+	}
+
+	var lines []string
+	for r, row := range plain {
+		if r == 1 {
+			lines = append(lines, strings.Join(separator, "-+-"))
+		}
+		cells := make([]string, columns)
+		for c, cell := range row {
+			a := alignLeft
+			if c < len(aligns) {
+				a = aligns[c]
+			}
+			cells[c] = pad(cell, widths[c], a, c == columns-1)
+		}
+		lines = append(lines, strings.Join(cells, " | "))
+	}
+	if len(plain) == 1 {
+		lines = append(lines, strings.Join(separator, "-+-"))
+	}
+
+	return "<pre>" + escape(strings.Join(lines, "\n")) + "</pre>"
+}
+
+// pad aligns cell within width; trailing spaces of the last column are omitted.
+func pad(cell string, width int, a align, last bool) string {
+	gap := width - utf8.RuneCountInString(cell)
+	switch a {
+	case alignRight:
+		return strings.Repeat(" ", gap) + cell
+	case alignCenter:
+		left := gap / 2
+		cell = strings.Repeat(" ", left) + cell
+		gap -= left
+	}
+	if last {
+		return cell
+	}
+	return cell + strings.Repeat(" ", gap)
+}
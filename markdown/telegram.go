@@ -0,0 +1,352 @@
+// Package markdown converts the Markdown produced by language models into the
+// subset of HTML accepted by Telegram (parse_mode=HTML).
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlTagRe   = regexp.MustCompile(`<[^>]*>`)
+	headingBold = strings.NewReplacer("**", "", "__", "")
+	linkSchemes = []string{"http://", "https://", "tg://", "mailto:"}
+)
+
+// ToTelegramHTML renders Markdown as Telegram HTML. Every tag it emits is
+// supported by Telegram and properly nested, and all other text is escaped,
+// so the result always parses. Headings become bold lines, tables become
+// monospace blocks and lists use bullet characters.
+func ToTelegramHTML(src string) string {
+	var out []string
+	for _, blk := range Parse(src) {
+		switch blk.Kind {
+		case BlockBlank:
+			out = append(out, "")
+		case BlockCode:
+			out = append(out, codeBlock(blk.Lang, blk.Text))
+		case BlockTable:
+			out = append(out, renderTable(blk.Rows, blk.aligns))
+		case BlockHeading:
+			// Bold inside the heading would nest <b> tags
+			out = append(out, "<b>"+renderInline(headingBold.Replace(blk.Text), true)+"</b>")
+		case BlockRule:
+			out = append(out, "──────────")
+		case BlockQuote:
+			var quoted []string
+			for _, line := range strings.Split(blk.Text, "\n") {
+				quoted = append(quoted, renderInline(line, true))
+			}
+			out = append(out, "<blockquote>"+strings.Join(quoted, "\n")+"</blockquote>")
+		case BlockListItem:
+			out = append(out, strings.Repeat("  ", blk.Level)+blk.Marker+" "+renderInline(blk.Text, true))
+		default:
+			out = append(out, renderInline(blk.Text, true))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// ToPlainText renders Markdown as plain text without markup, for use when a
+// formatted message cannot be sent.
+func ToPlainText(src string) string {
+	return stripTags(ToTelegramHTML(src))
+}
+
+func stripTags(s string) string {
+	return html.UnescapeString(htmlTagRe.ReplaceAllString(s, ""))
+}
+
+func codeBlock(lang, code string) string {
+	if lang != "" {
+		return `<pre><code class="language-` + html.EscapeString(lang) + `">` + escape(code) + "</code></pre>"
+	}
+	return "<pre>" + escape(code) + "</pre>"
+}
+
+// escape escapes text for Telegram HTML, which only recognises &lt; &gt; &amp; and &quot;.
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	return strings.ReplaceAll(s, ">", "&gt;")
+}
+
+func escapeAttr(s string) string {
+	return strings.ReplaceAll(escape(s), `"`, "&quot;")
+}
+
+func safeURL(url string) bool {
+	lower := strings.ToLower(url)
+	for _, scheme := range linkSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderInline converts emphasis, code spans and links in a single line.
+// Nested formatting is rendered recursively, so tags always nest properly.
+// Links are not rendered inside link text, which Telegram does not allow.
+func renderInline(s string, links bool) string {
+	var out strings.Builder
+	misses := make(closerMisses)
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			out.WriteString(escape(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if code, next, ok := codeSpan(s, i); ok {
+				out.WriteString("<code>" + escape(code) + "</code>")
+				i = next
+				continue
+			}
+			n := runLength(s, i, '`')
+			out.WriteString(s[i : i+n])
+			i += n
+			continue
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if text, url, next, ok := parseLink(s, i+1); ok {
+				out.WriteString(link(text, url, links))
+				i = next
+				continue
+			}
+
+		case c == '[':
+			if text, url, next, ok := parseLink(s, i); ok {
+				out.WriteString(link(text, url, links))
+				i = next
+				continue
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				url := s[i+1 : i+end]
+				if safeURL(url) && !strings.ContainsAny(url, " <") {
+					out.WriteString(link(url, url, links))
+					i += end + 1
+					continue
+				}
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if rendered, next, ok := emphasis(s, i, links, misses); ok {
+				out.WriteString(rendered)
+				i = next
+				continue
+			}
+			n := runLength(s, i, c)
+			out.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+
+		out.WriteString(escape(s[i : i+1]))
+		i++
+	}
+
+	return out.String()
+}
+
+func link(text, url string, links bool) string {
+	if !links || !safeURL(url) {
+		return renderInline(text, links)
+	}
+	return `<a href="` + escapeAttr(url) + `">` + renderInline(text, false) + "</a>"
+}
+
+// codeSpan matches a backtick code span starting at i and returns its content
+// and the index after it.
+func codeSpan(s string, i int) (string, int, bool) {
+	n := runLength(s, i, '`')
+	for j := i + n; j < len(s); {
+		k := strings.IndexByte(s[j:], '`')
+		if k < 0 {
+			break
+		}
+		j += k
+		m := runLength(s, j, '`')
+		if m == n {
+			code := s[i+n : j]
+			if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+				code = code[1 : len(code)-1]
+			}
+			return code, j + m, true
+		}
+		j += m
+	}
+	return "", 0, false
+}
+
+// parseLink matches [text](url "title") starting at the opening bracket.
+func parseLink(s string, i int) (text, url string, next int, ok bool) {
+	closeText := matchBracket(s, i, '[', ']')
+	if closeText < 0 || closeText+1 >= len(s) || s[closeText+1] != '(' {
+		return "", "", 0, false
+	}
+	closeURL := matchBracket(s, closeText+1, '(', ')')
+	if closeURL < 0 {
+		return "", "", 0, false
+	}
+
+	target := strings.TrimSpace(s[closeText+2 : closeURL])
+	if sp := strings.IndexAny(target, " \t"); sp >= 0 {
+		// Drop an optional title
+		target = target[:sp]
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+	return s[i+1 : closeText], target, closeURL + 1, true
+}
+
+// matchBracket returns the index of the bracket closing the one at i, or -1.
+func matchBracket(s string, i int, open, close byte) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+var emphasisTags = map[string][2]string{
+	"***": {"<b><i>", "</i></b>"},
+	"___": {"<b><i>", "</i></b>"},
+	"**":  {"<b>", "</b>"},
+	"__":  {"<b>", "</b>"},
+	"~~":  {"<s>", "</s>"},
+	"*":   {"<i>", "</i>"},
+	"_":   {"<i>", "</i>"},
+}
+
+// emphasis renders a delimited span (**bold**, *italic*, ~~strike~~ …)
+// starting at i. It fails when the delimiter cannot open or has no closer,
+// in which case the delimiter is literal text.
+func emphasis(s string, i int, links bool, misses closerMisses) (string, int, bool) {
+	c := s[i]
+	n := min(runLength(s, i, c), 3)
+	if c == '~' {
+		if n < 2 {
+			return "", 0, false
+		}
+		n = 2
+	}
+	delim := strings.Repeat(string(c), n)
+
+	start := i + n
+	if start >= len(s) || isSpace(s[start]) {
+		return "", 0, false
+	}
+	// Underscores inside words (snake_case) and single asterisks after a word
+	// character (2*3*4) are not emphasis
+	if (c == '_' || delim == "*") && i > 0 && isWordByte(s[i-1]) {
+		return "", 0, false
+	}
+
+	end := misses.find(s, start, delim)
+	if end < 0 && n == 3 {
+		// ***text** more* or ***text* more**: bold and italic opened together
+		// but closed apart. The delimiter that closes last is the outer one.
+		bold, italic := misses.find(s, i+2, delim[:2]), misses.find(s, i+1, delim[:1])
+		switch {
+		case bold >= 0 && bold > italic:
+			n, delim, end = 2, delim[:2], bold
+		case italic >= 0:
+			n, delim, end = 1, delim[:1], italic
+		}
+		start = i + n
+	}
+	if end < 0 {
+		return "", 0, false
+	}
+
+	tags := emphasisTags[delim]
+	return tags[0] + renderInline(s[start:end], links) + tags[1], end + n, true
+}
+
+// closerMisses remembers for each delimiter the earliest position from which
+// findCloser found no closer, so a line full of unmatched delimiters is not
+// searched to its end again for every one of them.
+type closerMisses map[string]int
+
+func (m closerMisses) find(s string, from int, delim string) int {
+	if miss, ok := m[delim]; ok && from >= miss {
+		return -1
+	}
+	end := findCloser(s, from, delim)
+	if end < 0 {
+		m[delim] = from
+	}
+	return end
+}
+
+// findCloser finds a closing delimiter run of exactly delim, skipping code
+// spans and escaped characters. Without one, a *** run that ends a word closes
+// delim with its last characters, so **bold *italic*** works.
+func findCloser(s string, from int, delim string) int {
+	c := delim[0]
+	fallback := -1
+	for j := from; j < len(s); {
+		switch {
+		case s[j] == '\\':
+			j += 2
+			continue
+		case s[j] == '`':
+			if _, next, ok := codeSpan(s, j); ok {
+				j = next
+				continue
+			}
+		case s[j] == c:
+			n := runLength(s, j, c)
+			closes := !isSpace(s[j-1])
+			endsWord := j+n >= len(s) || !isWordByte(s[j+n])
+			if closes && n == len(delim) && (c != '_' || endsWord) {
+				return j
+			}
+			if closes && endsWord && n == 3 && c != '~' && fallback < 0 {
+				fallback = j + n - len(delim)
+			}
+			j += n
+			continue
+		}
+		j++
+	}
+	return fallback
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+// isWordByte reports whether c is part of a word; bytes of multi-byte UTF-8
+// characters count, so Cyrillic words behave like Latin ones.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
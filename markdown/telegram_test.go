@@ -0,0 +1,73 @@
+package markdown
+
+import "testing"
+
+func TestToTelegramHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"bold and italic", "**bold** and *italic*", "<b>bold</b> and <i>italic</i>"},
+		{"bold italic", "***both***", "<b><i>both</i></b>"},
+		{"nested italic and code in bold", "**bold *it* `c<d`**", "<b>bold <i>it</i> <code>c&lt;d</code></b>"},
+		{"italic closed with bold", "**bold *italic***", "<b>bold <i>italic</i></b>"},
+		{"bold closed with italic", "*a **b***", "<i>a <b>b</b></i>"},
+		{"bold italic closed apart", "***a* b** ***c** d*", "<b><i>a</i> b</b> <i><b>c</b> d</i>"},
+		{"nested bold italic", "*a ***b*** c*", "<i>a <b><i>b</i></b> c</i>"},
+		{"strikethrough", "~~s~~ ~x~", "<s>s</s> ~x~"},
+		{"escaped markers", `\*lit\* \<b\>`, "*lit* &lt;b&gt;"},
+		{"snake case", "snake_case_name and __snake_case__", "snake_case_name and <b>snake_case</b>"},
+		{"asterisks between digits", "2*3*4", "2*3*4"},
+		{"cyrillic and emoji", "😀 *эмодзи*", "😀 <i>эмодзи</i>"},
+
+		{"entities are escaped again", "a &lt; b & c", "a &amp;lt; b &amp; c"},
+		{"raw tags are escaped", "<b>x</b> <script>", "&lt;b&gt;x&lt;/b&gt; &lt;script&gt;"},
+		{"code span escapes", "`a && b > c`", "<code>a &amp;&amp; b &gt; c</code>"},
+
+		{"unclosed bold", "**open", "**open"},
+		{"unclosed italic", "*open", "*open"},
+		{"unclosed code", "`open", "`open"},
+		{"unclosed strikethrough", "~~open", "~~open"},
+		{"unclosed inner italic", "**unclosed *mix**", "<b>unclosed *mix</b>"},
+		{"marker before space", "** not bold**", "** not bold**"},
+
+		{"link", "[a **b**](https://e.com?a=1&b=\"2\")", `<a href="https://e.com?a=1&amp;b=&quot;2&quot;">a <b>b</b></a>`},
+		{"autolink", "<https://e.com>", `<a href="https://e.com">https://e.com</a>`},
+		{"unsafe link scheme", "[x](javascript:alert(1))", "x"},
+		{"no links in link text", "[see [x](https://a.com)](https://b.com)", `<a href="https://b.com">see x</a>`},
+
+		{"heading", "# Head **b**", "<b>Head b</b>"},
+		{"rule", "---", "──────────"},
+		{"quote", "> q *i*\n> <x>", "<blockquote>q <i>i</i>\n&lt;x&gt;</blockquote>"},
+		{"nested list", "- item **b**\n  - sub", "• item <b>b</b>\n  ◦ sub"},
+		{"code block", "```go\na<b\n```", `<pre><code class="language-go">a&lt;b</code></pre>`},
+		{"unterminated code block", "```\n**x**", "<pre>**x**</pre>"},
+		{"table", "|a|b|\n|-|-|\n|1|2|", "<pre>a | b\n--+--\n1 | 2</pre>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToTelegramHTML(tt.src); got != tt.want {
+				t.Errorf("ToTelegramHTML(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToPlainText(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"**bold** `a<b` &amp;", "bold a<b &amp;"},
+		{"# Title\n- [x](https://e.com)", "Title\n• x"},
+		{"**open *mix", "**open *mix"},
+	}
+
+	for _, tt := range tests {
+		if got := ToPlainText(tt.src); got != tt.want {
+			t.Errorf("ToPlainText(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
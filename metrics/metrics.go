@@ -0,0 +1,261 @@
+// Package metrics keeps counters, histograms and gauges and serves them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metrics are registered in one process-wide registry and rendered in
+// registration order.
+var (
+	registryMu sync.Mutex
+	registry   []metric
+	byName     = make(map[string]int)
+)
+
+type metric interface {
+	name() string
+	write(b *strings.Builder)
+}
+
+// register adds m, replacing a metric registered earlier under the same name.
+func register(m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if i, ok := byName[m.name()]; ok {
+		registry[i] = m
+		return
+	}
+	byName[m.name()] = len(registry)
+	registry = append(registry, m)
+}
+
+// desc holds what all metric types share.
+type desc struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (d *desc) name() string { return d.metricName }
+
+func (d *desc) header(b *strings.Builder, kind string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, kind)
+}
+
+// key joins label values into a map key; \xff never occurs in UTF-8 text.
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.metricName, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs renders `{a="x",b="y"}` for a key made by key, with extra
+// appended (used for histogram buckets).
+func (d *desc) labelPairs(key string, extra ...string) string {
+	var pairs []string
+	if len(d.labels) > 0 {
+		for i, v := range strings.Split(key, "\xff") {
+			pairs = append(pairs, d.labels[i]+`="`+escapeLabel(v)+`"`)
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter is a monotonically increasing value per label set.
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter registers a counter with the given label names.
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name, help, labels}, values: make(map[string]float64)}
+	register(c)
+	return c
+}
+
+// Inc adds one for the label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, for the label values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(b *strings.Builder) {
+	c.header(b, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(b, "%s%s %s\n", c.metricName, c.labelPairs(key), formatValue(c.values[key]))
+	}
+}
+
+// Histogram counts observations in cumulative buckets per label set.
+type Histogram struct {
+	desc
+	buckets []float64 // upper bounds, ascending
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Buckets for latencies in seconds.
+var (
+	LongDurationBuckets = []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120}                                  // AI requests
+	DBDurationBuckets   = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1} // SQLite statements
+)
+
+// NewHistogram registers a histogram with the given bucket upper bounds and
+// label names.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	h := &Histogram{desc: desc{name, help, labels}, buckets: sorted, series: make(map[string]*histogramSeries)}
+	register(h)
+	return h
+}
+
+// Observe records v for the label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *Histogram) write(b *strings.Builder) {
+	h.header(b, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.metricName, h.labelPairs(key, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.metricName, h.labelPairs(key, "le", "+Inf"), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", h.metricName, h.labelPairs(key), formatValue(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", h.metricName, h.labelPairs(key), s.count)
+	}
+}
+
+// ValueFunc reports a value read when metrics are scraped.
+type ValueFunc struct {
+	desc
+	kind string // "gauge" or "counter"
+	fn   func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is returned by fn.
+func NewGaugeFunc(name, help string, fn func() float64) *ValueFunc {
+	v := &ValueFunc{desc: desc{metricName: name, help: help}, kind: "gauge", fn: fn}
+	register(v)
+	return v
+}
+
+// NewCounterFunc registers a counter whose value is returned by fn, for
+// totals kept elsewhere.
+func NewCounterFunc(name, help string, fn func() float64) *ValueFunc {
+	v := &ValueFunc{desc: desc{metricName: name, help: help}, kind: "counter", fn: fn}
+	register(v)
+	return v
+}
+
+func (v *ValueFunc) write(b *strings.Builder) {
+	v.header(b, v.kind)
+	fmt.Fprintf(b, "%s %s\n", v.metricName, formatValue(v.fn()))
+}
+
+func init() {
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+}
+
+// Handler serves all registered metrics.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registryMu.Lock()
+		metrics := append([]metric(nil), registry...)
+		registryMu.Unlock()
+
+		var b strings.Builder
+		for _, m := range metrics {
+			m.write(&b)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(b.String()))
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "expected 5 fields, got 0"},
+		{"0 8 * *", "expected 5 fields, got 4"},
+		{"0 8 * * 1 2", "expected 5 fields, got 6"},
+		{"@yearly", "expected 5 fields, got 1"},
+		{"60 * * * *", `minute: "60" out of range 0-59`},
+		{"* 24 * * *", `hour: "24" out of range 0-23`},
+		{"* * 0 * *", `day of month: "0" out of range 1-31`},
+		{"* * * 13 *", `month: "13" out of range 1-12`},
+		{"* * * * 8", `day of week: "8" out of range 0-7`},
+		{"5-1 * * * *", `minute: "5-1" out of range 0-59`},
+		{"*/0 * * * *", `minute: invalid step in "*/0"`},
+		{"*/x * * * *", `minute: invalid step in "*/x"`},
+		{"a * * * *", `minute: invalid value "a"`},
+		{"1-x * * * *", `minute: invalid range "1-x"`},
+		{"1,,2 * * * *", `minute: invalid value ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if err == nil {
+				t.Fatalf("ParseCron(%q) succeeded, want error", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseCron(%q) error %q, want %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+	}{
+		{"*", 0, 6, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"5", 0, 59, []int{5}},
+		{"1,3,5", 0, 6, []int{1, 3, 5}},
+		{"1-4", 0, 6, []int{1, 2, 3, 4}},
+		{"*/15", 0, 59, []int{0, 15, 30, 45}},
+		{"8-18/4", 0, 23, []int{8, 12, 16}},
+		{"50/5", 0, 59, []int{50, 55}},
+		{"1-2,20/2", 1, 23, []int{1, 2, 20, 22}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			bits, err := parseField(tt.field, tt.min, tt.max)
+			if err != nil {
+				t.Fatalf("parseField(%q): %v", tt.field, err)
+			}
+			var got []int
+			for v := tt.min; v <= tt.max; v++ {
+				if has(bits, v) {
+					got = append(got, v)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseField(%q) = %v, want %v", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	tashkent := time.FixedZone("UTC+5", 5*60*60)
+	at := func(s string) time.Time {
+		t, err := time.ParseInLocation("2006-01-02 15:04", s, tashkent)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string // empty when the schedule never fires
+	}{
+		{"every minute", "* * * * *", "2025-03-10 08:00", "2025-03-10 08:01"},
+		{"strictly after", "0 8 * * *", "2025-03-10 08:00", "2025-03-11 08:00"},
+		{"seconds are dropped", "30 8 * * *", "2025-03-10 08:29", "2025-03-10 08:30"},
+		{"step", "*/15 * * * *", "2025-03-10 08:16", "2025-03-10 08:30"},
+		{"value with step", "40/10 * * * *", "2025-03-10 08:55", "2025-03-10 09:40"},
+		{"hour range with step", "0 8-18/4 * * *", "2025-03-10 12:01", "2025-03-10 16:00"},
+		{"next day", "0 7 * * *", "2025-03-10 08:00", "2025-03-11 07:00"},
+		{"across month end", "0 0 * * *", "2025-04-30 23:59", "2025-05-01 00:00"},
+		{"across year end", "0 9 * * *", "2025-12-31 10:00", "2026-01-01 09:00"},
+		{"first of month across year", "@monthly", "2025-12-15 00:00", "2026-01-01 00:00"},
+		{"day of month skips short months", "0 6 31 * *", "2025-04-01 00:00", "2025-05-31 06:00"},
+		{"leap day", "0 0 29 2 *", "2025-03-01 00:00", "2028-02-29 00:00"},
+		{"month list", "0 12 1 3,9 *", "2025-03-02 00:00", "2025-09-01 12:00"},
+		{"weekday", "0 8 * * 1", "2025-03-10 08:00", "2025-03-17 08:00"}, // Monday
+		{"weekdays range", "0 8 * * 1-5", "2025-03-14 09:00", "2025-03-17 08:00"},
+		{"sunday as 0", "0 10 * * 0", "2025-03-10 00:00", "2025-03-16 10:00"},
+		{"sunday as 7", "0 10 * * 7", "2025-03-10 00:00", "2025-03-16 10:00"},
+		{"range ending at 7", "0 10 * * 6-7", "2025-03-10 00:00", "2025-03-15 10:00"},
+		{"weekly descriptor", "@weekly", "2025-03-10 00:00", "2025-03-17 00:00"},
+		// Both day fields restricted: either one matches (the 13th or a Friday)
+		{"day of month or day of week", "0 0 13 * 5", "2025-03-10 00:00", "2025-03-13 00:00"},
+		{"day of week or day of month", "0 0 13 * 5", "2025-03-13 00:00", "2025-03-14 00:00"},
+		{"day of month and any weekday", "0 0 13 * *", "2025-03-14 00:00", "2025-04-13 00:00"},
+		{"never fires", "0 0 31 2 *", "2025-01-01 00:00", ""},
+		{"never fires in short months", "0 0 31 4,6,9,11 *", "2025-01-01 00:00", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			got := s.Next(at(tt.from).Add(20 * time.Second))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next = %v, want zero time", got)
+				}
+				return
+			}
+			if want := at(tt.want); !got.Equal(want) || got.Location() != tashkent {
+				t.Errorf("Next(%s) = %v, want %v", tt.from, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"factory_bot/database"
+	"factory_bot/logging"
+	"factory_bot/tracing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxSleep bounds the wait between database checks so jobs added by other
+// processes (or edited by hand) are picked up.
+const maxSleep = time.Minute
+
+const (
+	maxJobAttempts = 5                // runs of a failing one-off job before it is dropped
+	retryDelay     = time.Minute      // before the second run of a failed one-off job; doubles after each failure
+	recordDelay    = 30 * time.Second // between attempts to record a run after the database failed
+)
+
+// completion is the outcome of a run to be stored with the job.
+type completion struct {
+	ranAt time.Time
+	next  time.Time // zero deactivates the job
+	retry bool      // a failed one-off run, counted towards maxJobAttempts
+}
+
+// Runner delivers a due job. ctx carries the job's request ID for logging.
+type Runner func(ctx context.Context, job database.Job) error
+
+// Scheduler runs persisted one-off and cron jobs in the plant's time zone.
+// Jobs live in SQLite, so they survive restarts; overdue jobs run on startup.
+type Scheduler struct {
+	db       *database.Database
+	location *time.Location
+	run      Runner
+	wake     chan struct{}
+
+	// unrecorded holds runs whose completion could not be stored. Their
+	// jobs still look due in the database but must not run again. Only the
+	// Run goroutine uses it.
+	unrecorded map[int64]completion
+}
+
+func New(db *database.Database, location *time.Location, run Runner) *Scheduler {
+	return &Scheduler{
+		db:       db,
+		location: location,
+		run:      run,
+		wake:     make(chan struct{}, 1),
+
+		unrecorded: make(map[int64]completion),
+	}
+}
+
+// Add validates and stores a job. For cron jobs NextRun is computed from the
+// expression; one-off jobs must set NextRun.
+func (s *Scheduler) Add(ctx context.Context, job *database.Job) error {
+	if job.Cron != "" {
+		schedule, err := ParseCron(job.Cron)
+		if err != nil {
+			return err
+		}
+		job.NextRun = schedule.Next(time.Now().In(s.location))
+		if job.NextRun.IsZero() {
+			return fmt.Errorf("cron expression %q never fires", job.Cron)
+		}
+	} else if job.NextRun.IsZero() {
+		return fmt.Errorf("one-off job needs a run time")
+	}
+
+	if err := s.db.CreateJob(ctx, job); err != nil {
+		return err
+	}
+
+	// Re-evaluate the sleep in case the new job is due earlier
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run executes due jobs until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	logrus.WithField("timezone", s.location.String()).Info("⏰ Scheduler started")
+
+	for {
+		stalled := s.runDue(ctx)
+
+		sleep := maxSleep
+		if next, ok, err := s.db.GetNextJobTime(ctx); err == nil && ok {
+			if d := time.Until(next); d < sleep {
+				sleep = d
+			}
+		}
+		if sleep < time.Second {
+			sleep = time.Second
+		}
+		if stalled && sleep < recordDelay {
+			// An unrecorded job keeps its past next_run; do not poll the database every second
+			sleep = recordDelay
+		}
+
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logrus.Info("⏰ Scheduler stopped")
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// runDue runs due jobs and reports whether some runs could not be recorded.
+func (s *Scheduler) runDue(ctx context.Context) bool {
+	for id, c := range s.unrecorded {
+		if s.record(ctx, id, c) == nil {
+			delete(s.unrecorded, id)
+		}
+	}
+
+	now := time.Now()
+	jobs, err := s.db.GetDueJobs(ctx, now)
+	if err != nil {
+		return len(s.unrecorded) > 0
+	}
+
+	for _, job := range jobs {
+		if _, ok := s.unrecorded[job.ID]; ok {
+			continue
+		}
+
+		// A started job is delivered and recorded even if the scheduler stops meanwhile
+		ctx := logging.WithRequest(context.WithoutCancel(ctx), logging.Request{ID: logging.NewRequestID(), Job: job.Kind})
+		ctx, span := tracing.Start(ctx, "job "+job.Kind, tracing.Int64("job.id", job.ID))
+		err := s.run(ctx, job)
+		span.RecordError(err)
+		if err != nil {
+			logging.From(ctx).WithError(err).WithField("job_id", job.ID).Error("❌ Scheduled job failed")
+		} else {
+			logging.From(ctx).WithFields(logrus.Fields{
+				"job_id": job.ID,
+				"kind":   job.Kind,
+			}).Info("⏰ Scheduled job delivered")
+		}
+
+		c := completion{ranAt: now}
+		switch {
+		case job.Cron != "":
+			// Missed cron runs (e.g. while the bot was down) are not replayed;
+			// the job continues from its next activation after now
+			if schedule, err := ParseCron(job.Cron); err == nil {
+				c.next = schedule.Next(now.In(s.location))
+			}
+		case err != nil && job.Attempts+1 < maxJobAttempts:
+			c.next, c.retry = now.Add(retryDelay<<job.Attempts), true
+			logging.From(ctx).WithFields(logrus.Fields{
+				"job_id":  job.ID,
+				"attempt": job.Attempts + 1,
+				"retry":   c.next.In(s.location).Format(time.RFC3339),
+			}).Warn("⚠️ One-off job will be retried")
+		case err != nil:
+			logging.From(ctx).WithField("job_id", job.ID).Error("❌ One-off job dropped after repeated failures")
+		}
+
+		if err := s.record(ctx, job.ID, c); err != nil {
+			s.unrecorded[job.ID] = c
+		}
+		span.End()
+	}
+
+	return len(s.unrecorded) > 0
+}
+
+func (s *Scheduler) record(ctx context.Context, id int64, c completion) error {
+	if c.retry {
+		return s.db.RetryJob(ctx, id, c.ranAt, c.next)
+	}
+	return s.db.CompleteJobRun(ctx, id, c.ranAt, c.next)
+}
@@ -0,0 +1,346 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Exporters selectable in Config.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const (
+	queueSize     = 4096             // spans waiting for export; more are dropped
+	batchSize     = 512              // spans per export request
+	flushInterval = 5 * time.Second  // longest a span waits in the queue
+	exportTimeout = 10 * time.Second // per OTLP request
+)
+
+// Config selects where spans go.
+type Config struct {
+	Exporter    string // ExporterNone, ExporterOTLP or ExporterStdout
+	Endpoint    string // OTLP/HTTP base URL; /v1/traces is appended
+	ServiceName string // service.name resource attribute
+
+	// Redact masks secrets in error messages and string attributes before
+	// export, e.g. a bot token in a failed request URL; may be nil
+	Redact func(string) string
+}
+
+// exporter sends a batch of finished spans.
+type exporter func(ctx context.Context, spans []*Span) error
+
+// processor batches finished spans and exports them in the background.
+type processor struct {
+	queue   chan *Span
+	done    chan struct{}
+	export  exporter
+	redact  func(string) string
+	dropped atomic.Int64
+
+	mu     sync.RWMutex // guards closing queue against late spans
+	closed bool
+}
+
+// Setup starts exporting spans as configured. With ExporterNone spans are
+// not recorded at all.
+func Setup(cfg Config) error {
+	var export exporter
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return nil
+	case ExporterOTLP:
+		export = otlpExporter(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces", cfg.ServiceName)
+	case ExporterStdout:
+		export = stdoutExporter(os.Stdout)
+	default:
+		return fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	p := &processor{
+		queue:  make(chan *Span, queueSize),
+		done:   make(chan struct{}),
+		export: export,
+		redact: cfg.Redact,
+	}
+	go p.run()
+	active.Store(p)
+
+	logrus.WithFields(logrus.Fields{
+		"exporter": cfg.Exporter,
+		"endpoint": cfg.Endpoint,
+	}).Info("🔭 Tracing enabled")
+	return nil
+}
+
+// Shutdown stops recording spans and exports the queued ones, waiting until
+// ctx is done at most.
+func Shutdown(ctx context.Context) {
+	p := active.Swap(nil)
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		logrus.Warn("⚠️ Tracing: spans not exported before shutdown")
+	}
+}
+
+// enqueue queues a finished span, dropping it when the queue is full or
+// Shutdown has started.
+func (p *processor) enqueue(s *Span) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- s:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+func (p *processor) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	send := func() {
+		if n := p.dropped.Swap(0); n > 0 {
+			logrus.WithField("spans", n).Warn("⚠️ Tracing: queue full, spans dropped")
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if p.redact != nil {
+			for _, s := range batch {
+				s.redact(p.redact)
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		err := p.export(ctx, batch)
+		cancel()
+		if err != nil {
+			logrus.WithError(err).WithField("spans", len(batch)).Warn("⚠️ Tracing: export failed")
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case s, ok := <-p.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+// redact applies fn to the span's error message and string attributes.
+func (s *Span) redact(fn func(string) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMsg = fn(s.errMsg)
+	for i, a := range s.attrs {
+		if v, ok := a.Value.(string); ok {
+			s.attrs[i].Value = fn(v)
+		}
+	}
+}
+
+// OTLP/JSON encoding of ExportTraceServiceRequest. IDs are hex and 64-bit
+// integers strings, as the OTLP JSON mapping requires.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              Kind           `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            *otlpStatus    `json:"status,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 2 = error
+		Message string `json:"message,omitempty"`
+	}
+)
+
+func otlpAttr(a Attr) otlpKeyValue {
+	var value map[string]interface{}
+	switch v := a.Value.(type) {
+	case string:
+		value = map[string]interface{}{"stringValue": v}
+	case bool:
+		value = map[string]interface{}{"boolValue": v}
+	case int64:
+		value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		value = map[string]interface{}{"doubleValue": v}
+	default:
+		value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+	return otlpKeyValue{Key: a.Key, Value: value}
+}
+
+func toOTLP(s *Span) otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+	}
+	if s.parentID != (spanID{}) {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	for _, a := range s.attrs {
+		span.Attributes = append(span.Attributes, otlpAttr(a))
+	}
+	if s.errMsg != "" {
+		span.Status = &otlpStatus{Code: 2, Message: s.errMsg}
+	}
+	return span
+}
+
+// otlpExporter posts batches to an OTLP/HTTP collector in the JSON encoding.
+func otlpExporter(url, serviceName string) exporter {
+	client := &http.Client{}
+	resource := otlpResource{Attributes: []otlpKeyValue{otlpAttr(String("service.name", serviceName))}}
+
+	return func(ctx context.Context, spans []*Span) error {
+		scope := otlpScopeSpans{Scope: otlpScope{Name: "factory_bot"}}
+		for _, s := range spans {
+			scope.Spans = append(scope.Spans, toOTLP(s))
+		}
+		body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+			Resource:   resource,
+			ScopeSpans: []otlpScopeSpans{scope},
+		}}})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("collector returned %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+// stdoutSpan is a compact one-line form of a span for reading in a terminal.
+type stdoutSpan struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Name       string                 `json:"name"`
+	Start      string                 `json:"start"`
+	DurationMS float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// stdoutExporter writes one JSON line per span to w.
+func stdoutExporter(w io.Writer) exporter {
+	return func(ctx context.Context, spans []*Span) error {
+		enc := json.NewEncoder(w)
+		for _, s := range spans {
+			s.mu.Lock()
+			line := stdoutSpan{
+				TraceID:    hex.EncodeToString(s.traceID[:]),
+				SpanID:     hex.EncodeToString(s.spanID[:]),
+				Name:       s.name,
+				Start:      s.start.Format(time.RFC3339Nano),
+				DurationMS: float64(s.end.Sub(s.start).Microseconds()) / 1000,
+				Error:      s.errMsg,
+			}
+			if s.parentID != (spanID{}) {
+				line.ParentID = hex.EncodeToString(s.parentID[:])
+			}
+			if len(s.attrs) > 0 {
+				line.Attributes = make(map[string]interface{}, len(s.attrs))
+				for _, a := range s.attrs {
+					line.Attributes[a.Key] = a.Value
+				}
+			}
+			s.mu.Unlock()
+
+			if err := enc.Encode(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
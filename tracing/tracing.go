@@ -0,0 +1,146 @@
+// Package tracing records spans of request handling and exports them as
+// OpenTelemetry traces over OTLP/HTTP, or to stdout for local debugging.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind tells trace viewers how a span relates to other services.
+type Kind int
+
+// Values follow the OTLP SpanKind enum.
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// Attr is a span attribute. Value is a string, bool, int64 or float64.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// Attribute constructors.
+func String(key, value string) Attr        { return Attr{key, value} }
+func Bool(key string, value bool) Attr     { return Attr{key, value} }
+func Int(key string, value int) Attr       { return Attr{key, int64(value)} }
+func Int64(key string, value int64) Attr   { return Attr{key, value} }
+func Float(key string, value float64) Attr { return Attr{key, value} }
+
+type (
+	traceID [16]byte
+	spanID  [8]byte
+)
+
+// Span is one timed operation. All methods are safe on a nil Span, which is
+// what Start returns while tracing is disabled.
+type Span struct {
+	p        *processor
+	name     string
+	kind     Kind
+	traceID  traceID
+	spanID   spanID
+	parentID spanID
+	start    time.Time
+
+	mu     sync.Mutex
+	end    time.Time
+	attrs  []Attr
+	errMsg string
+	ended  bool
+}
+
+type spanKey struct{}
+
+// active is the processor spans are sent to; nil while tracing is disabled.
+var active atomic.Pointer[processor]
+
+// Start begins an internal span as a child of the span in ctx and returns a
+// context carrying the new span.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return StartKind(ctx, KindInternal, name, attrs...)
+}
+
+// StartKind begins a span of the given kind, e.g. KindClient for calls to
+// other services.
+func StartKind(ctx context.Context, kind Kind, name string, attrs ...Attr) (context.Context, *Span) {
+	p := active.Load()
+	if p == nil {
+		return ctx, nil
+	}
+
+	s := &Span{
+		p:     p,
+		name:  name,
+		kind:  kind,
+		start: time.Now(),
+		attrs: attrs,
+	}
+	if parent := FromContext(ctx); parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext returns the span ctx carries, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// TraceID returns the hex trace ID of the span in ctx, or "".
+func TraceID(ctx context.Context) string {
+	s := FromContext(ctx)
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed with err. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	s.p.enqueue(s)
+}